@@ -0,0 +1,38 @@
+// restore 将管理端导出的备份恢复到当前配置的数据库与存储, 用于搭建预发环境与灾难恢复:
+//
+//	go run ./cmd/restore -file backups/backup-20250101-030000.tar.gz [-truncate]
+//
+// 目标库须已执行 scripts/init.sql 与全部迁移; 备份涉及的表不为空时拒绝恢复, 除非指定 -truncate 先清空这些表.
+// 恢复完成后重启服务, 以重建搜索索引并丢弃旧缓存
+package main
+
+import (
+	"flag"
+	"log"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/storage"
+	"vibe-music-server/internal/repo"
+	"vibe-music-server/internal/service"
+)
+
+func main() {
+	file := flag.String("file", "", "备份文件路径")
+	truncate := flag.Bool("truncate", false, "先清空备份涉及的表")
+	flag.Parse()
+	if *file == "" {
+		flag.Usage()
+		log.Fatal("restore: -file is required")
+	}
+
+	db.Init()
+	store, err := storage.New()
+	if err != nil {
+		log.Fatalf("restore: init storage: %v", err)
+	}
+	storageService := service.NewStorageService(store, repo.NewStorageObjectRepo())
+	backupService := service.NewBackupService(repo.NewBackupRepo(), storageService)
+	if err := backupService.Restore(*file, *truncate); err != nil {
+		log.Fatalf("restore: %v", err)
+	}
+	log.Println("restore: done")
+}
@@ -0,0 +1,51 @@
+// scan 扫描本地目录(如挂载的 NAS 曲库)中的音频文件, 按标签新建或更新歌手、专辑与歌曲并上传到当前配置的存储:
+//
+//	go run ./cmd/scan -dir /mnt/music [-dry-run]
+//
+// 已扫描的文件记录在 tb_scan_file 中, 再次扫描只处理新增、内容变化或移动的文件.
+// 搜索索引由服务端定时重建, 新歌曲最多约 10 分钟后可被搜索到
+package main
+
+import (
+	"flag"
+	"log"
+	"vibe-music-server/internal/pkg/cache"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/storage"
+	"vibe-music-server/internal/repo"
+	"vibe-music-server/internal/service"
+)
+
+func main() {
+	dir := flag.String("dir", "", "曲库根目录")
+	dryRun := flag.Bool("dry-run", false, "只报告将要执行的操作, 不写入")
+	flag.Parse()
+	if *dir == "" {
+		flag.Usage()
+		log.Fatal("scan: -dir is required")
+	}
+
+	db.Init()
+	cache.Init()
+	store, err := storage.New()
+	if err != nil {
+		log.Fatalf("scan: init storage: %v", err)
+	}
+	songRepo := repo.NewSongRepo()
+	artistRepo := repo.NewArtistRepo()
+	albumRepo := repo.NewAlbumRepo()
+	styleRepo := repo.NewStyleRepo()
+	storageService := service.NewStorageService(store, repo.NewStorageObjectRepo())
+	searchService := service.NewSearchService(repo.NewSearchRepo(), storageService)
+	deletionService := service.NewDeletionService(repo.NewDeletionRepo(), storageService, searchService)
+	songService := service.NewSongService(songRepo, albumRepo, repo.NewSongArtistRepo(), repo.NewFavoriteRepo(), styleRepo, repo.NewGenreRepo(),
+		repo.NewSongAudioMetaRepo(), repo.NewSongRenditionRepo(), storageService, searchService, deletionService)
+	scanService := service.NewScanService(repo.NewScanFileRepo(), songRepo, artistRepo, albumRepo, styleRepo, storageService, songService)
+
+	report, err := scanService.Scan(*dir, *dryRun)
+	log.Printf("scan: %d files, %d created, %d updated, %d moved, %d unchanged, %d skipped, %d failed (dry run: %v)\n",
+		report.Files, report.Created, report.Updated, report.Moved, report.Unchanged, report.Skipped, report.Failed, *dryRun)
+	if err != nil {
+		log.Fatalf("scan: %v", err)
+	}
+}
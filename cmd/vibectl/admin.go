@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/validate"
+	"vibe-music-server/internal/repo"
+	"vibe-music-server/internal/service"
+)
+
+func runAdmin(args []string) error {
+	fs := newFlagSet("admin", "-username NAME [-password PASSWORD]")
+	username := fs.String("username", "", "管理员用户名")
+	password := fs.String("password", "", "新密码, 为空时随机生成并输出")
+	_ = fs.Parse(args)
+	if *username == "" {
+		fs.Usage()
+		return errors.New("-username is required")
+	}
+	generated := *password == ""
+	if generated {
+		var err error
+		if *password, err = randomPassword(); err != nil {
+			return err
+		}
+	}
+	// 与管理员注册接口的校验一致
+	if err := validate.Validate.Struct(&dto.AdminDTO{Username: *username, Password: *password}); err != nil {
+		return err
+	}
+
+	db.Init()
+	adminService := service.NewAdminService(repo.NewAdminRepo())
+	created, err := adminService.ResetAdmin(*username, *password)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Printf("admin %s created\n", *username)
+	} else {
+		fmt.Printf("admin %s password reset\n", *username)
+	}
+	if generated {
+		fmt.Printf("password: %s\n", *password)
+	}
+	return nil
+}
+
+// randomPassword 16 位随机密码, 满足 password 校验规则(不全为数字或字母)
+func randomPassword() (string, error) {
+	buf := make([]byte, 12)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		password := base64.RawURLEncoding.EncodeToString(buf)
+		if validate.Validate.Var(password, "password") == nil {
+			return password, nil
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"vibe-music-server/internal/pkg/cache"
+	"vibe-music-server/internal/pkg/util"
+)
+
+// runPurgeCache 登录 token 同样存放在缓存中, 因此不允许空前缀
+func runPurgeCache(args []string) error {
+	fs := newFlagSet("purge-cache", "PREFIX...")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no prefix given")
+	}
+	for _, prefix := range fs.Args() {
+		if prefix == "" {
+			return errors.New("empty prefix would purge login tokens too")
+		}
+	}
+
+	cache.Init()
+	for _, prefix := range fs.Args() {
+		fmt.Printf("%s*: %d keys deleted\n", prefix, util.DeleteCacheByPattern(prefix+"*"))
+	}
+	return nil
+}
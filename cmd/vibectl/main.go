@@ -0,0 +1,74 @@
+// vibectl 运维命令行, 复用服务端的配置与服务:
+//
+//	go run ./cmd/vibectl <command> [flags]
+//
+// 命令:
+//
+//	admin          创建管理员, 已存在时重置密码
+//	disable-user   禁用(或 -enable 启用)用户
+//	purge-cache    按前缀删除缓存
+//	rebuild-stats  由源表重算按天统计(播放量、榜单与仪表盘)
+//	migrate        执行 scripts/migrations 中尚未执行的脚本
+//	verify-storage 检查数据库引用的存储对象是否存在
+//
+// 各命令的参数见 vibectl <command> -h
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"admin", "创建管理员, 已存在时重置密码", runAdmin},
+	{"disable-user", "禁用(或 -enable 启用)用户", runDisableUser},
+	{"purge-cache", "按前缀删除缓存", runPurgeCache},
+	{"rebuild-stats", "由源表重算按天统计", runRebuildStats},
+	{"migrate", "执行尚未执行的迁移脚本", runMigrate},
+	{"verify-storage", "检查数据库引用的存储对象是否存在", runVerifyStorage},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: vibectl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-15s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				log.Fatalf("vibectl %s: %v", name, err)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
+
+// newFlagSet 出错时退出, 与 flag.Parse 一致
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: vibectl %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
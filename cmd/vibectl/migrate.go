@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/repo"
+	"vibe-music-server/internal/service"
+)
+
+// runMigrate 已手动执行过迁移的库, 首次使用前用 -mark 补记已执行到的脚本
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate", "[-dir DIR] [-status | -mark VERSION]")
+	dir := fs.String("dir", "scripts/migrations", "迁移脚本目录")
+	status := fs.Bool("status", false, "只列出各脚本的执行情况")
+	mark := fs.String("mark", "", "将该脚本(文件名或编号)及之前的脚本记为已执行, 不执行")
+	_ = fs.Parse(args)
+
+	db.Init()
+	migrationService := service.NewMigrationService(repo.NewMigrationRepo())
+	switch {
+	case *status:
+		migrations, err := migrationService.GetMigrations(*dir)
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			appliedAt := "pending"
+			if migration.AppliedAt != nil {
+				appliedAt = migration.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-40s %s\n", migration.Version, appliedAt)
+		}
+	case *mark != "":
+		marked, err := migrationService.MarkApplied(*dir, *mark)
+		for _, version := range marked {
+			fmt.Printf("%s marked as applied\n", version)
+		}
+		return err
+	default:
+		applied, err := migrationService.Migrate(*dir)
+		for _, version := range applied {
+			fmt.Printf("%s applied\n", version)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("nothing to migrate")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/repo"
+	"vibe-music-server/internal/service"
+)
+
+const dateLayout = "2006-01-02"
+
+// runRebuildStats 默认重算最早有数据的一天到今天
+func runRebuildStats(args []string) error {
+	fs := newFlagSet("rebuild-stats", "[-from yyyy-mm-dd] [-to yyyy-mm-dd]")
+	from := fs.String("from", "", "开始日期, 默认最早有数据的一天")
+	to := fs.String("to", "", "结束日期, 默认今天")
+	_ = fs.Parse(args)
+	end := time.Now()
+	if *to != "" {
+		var err error
+		if end, err = time.ParseInLocation(dateLayout, *to, time.Local); err != nil {
+			return fmt.Errorf("-to: %w", err)
+		}
+	}
+
+	db.Init()
+	statService := service.NewStatService(repo.NewStatRepo())
+	var start time.Time
+	if *from != "" {
+		var err error
+		if start, err = time.ParseInLocation(dateLayout, *from, time.Local); err != nil {
+			return fmt.Errorf("-from: %w", err)
+		}
+	} else {
+		var err error
+		if start, err = statService.GetFirstActivityDay(); err != nil {
+			return err
+		}
+	}
+	if start.After(end) {
+		return errors.New("-from is after -to")
+	}
+	if err := statService.Rebuild(start, end); err != nil {
+		return err
+	}
+	fmt.Printf("daily stats rebuilt from %s to %s\n", start.Format(dateLayout), end.Format(dateLayout))
+	return nil
+}
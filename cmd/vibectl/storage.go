@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/storage"
+	"vibe-music-server/internal/repo"
+	"vibe-music-server/internal/service"
+)
+
+// runVerifyStorage 列出悬空引用(数据库引用了、存储中没有的对象), 存在时以非零状态退出; 孤儿对象只计数, 由管理端清理
+func runVerifyStorage(args []string) error {
+	fs := newFlagSet("verify-storage", "")
+	_ = fs.Parse(args)
+
+	db.Init()
+	store, err := storage.New()
+	if err != nil {
+		return fmt.Errorf("init storage: %w", err)
+	}
+	storageObjectRepo := repo.NewStorageObjectRepo()
+	storageService := service.NewStorageService(store, storageObjectRepo)
+	reconcileService := service.NewReconcileService(repo.NewStorageRefRepo(), storageObjectRepo, storageService)
+	res := reconcileService.Reconcile(false)
+	if res.Code != 0 {
+		return errors.New(res.Message)
+	}
+	report := res.Data
+	for _, ref := range report.Dangling {
+		fmt.Printf("dangling: %s.%s id=%d key=%s\n", ref.Table, ref.Column, ref.ID, ref.Key)
+	}
+	fmt.Printf("%d objects, %d references, %d external, %d orphans (%d bytes), %d dangling\n",
+		report.Objects, report.References, report.External, len(report.Orphans), report.OrphanBytes, len(report.Dangling))
+	if len(report.Dangling) > 0 {
+		return fmt.Errorf("%d dangling references", len(report.Dangling))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/cache"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/storage"
+	"vibe-music-server/internal/repo"
+	"vibe-music-server/internal/service"
+)
+
+// runDisableUser 用户可以由 ID、邮箱或用户名指定. 已签发的 token 在过期前仍然有效
+func runDisableUser(args []string) error {
+	fs := newFlagSet("disable-user", "[-enable] USER...")
+	enable := fs.Bool("enable", false, "启用而不是禁用")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no user given")
+	}
+	status, action := entity.UserStatusDisable, "disabled"
+	if *enable {
+		status, action = entity.UserStatusEnable, "enabled"
+	}
+
+	db.Init()
+	cache.Init()
+	store, err := storage.New()
+	if err != nil {
+		return fmt.Errorf("init storage: %w", err)
+	}
+	userRepo := repo.NewUserRepo()
+	storageService := service.NewStorageService(store, repo.NewStorageObjectRepo())
+	deletionService := service.NewDeletionService(repo.NewDeletionRepo(), storageService, service.NewSearchService(repo.NewSearchRepo(), storageService))
+	userService := service.NewUserService(userRepo, service.NewEmailService(), storageService, deletionService)
+
+	failed := 0
+	for _, arg := range fs.Args() {
+		var user entity.User
+		if err := findUser(userRepo, &user, arg); err != nil {
+			fmt.Printf("%s: %v\n", arg, err)
+			failed++
+			continue
+		}
+		if res := userService.UpdateUserStatus(user.UserId, status); res.Code != 0 {
+			fmt.Printf("%s: %s\n", arg, res.Message)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: user %d (%s) %s\n", arg, user.UserId, user.Username, action)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d users failed", failed, fs.NArg())
+	}
+	return nil
+}
+
+// findUser 纯数字先按 ID 查找(用户名也可能是纯数字), 含 @ 按邮箱查找, 否则按用户名查找
+func findUser(userRepo *repo.UserRepo, user *entity.User, arg string) error {
+	if id, err := strconv.ParseUint(arg, 10, 64); err == nil {
+		if err := userRepo.GetUserById(user, id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if strings.Contains(arg, "@") {
+		return userRepo.GetUserByEmail(user, arg)
+	}
+	return userRepo.GetUserByName(user, arg)
+}
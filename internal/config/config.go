@@ -1,5 +1,7 @@
 package config
 
+import "testing"
+
 var (
 	cfg Config
 )
@@ -7,6 +9,10 @@ var (
 func init() {
 	v, err := load()
 	if err != nil {
+		// 单元测试的工作目录为各包目录, 读不到配置文件, 使用零值配置
+		if testing.Testing() {
+			return
+		}
 		panic(err)
 	}
 	if err = v.Unmarshal(&cfg); err != nil {
@@ -5,9 +5,14 @@ type Config struct {
 	Database            Database
 	Redis               Redis
 	Minio               Minio
+	Storage             Storage
 	Mail                Mail
 	RolePathPermissions RolePathPermissions `mapstructure:"role-path-permissions"`
 	Jwt                 Jwt
+	Upload              Upload
+	Stream              Stream
+	Trash               Trash
+	Backup              Backup
 }
 
 type App struct {
@@ -53,6 +58,19 @@ type Minio struct {
 	AccessKey string `mapstructure:"accessKey"`
 	SecretKey string `mapstructure:"secretKey"`
 	Bucket    string
+	Region    string // 兼容 S3 的服务按需填写, 留空自动探测
+}
+
+type Storage struct {
+	Driver string // minio(兼容 S3) / local / memory, 留空为 minio
+	Local  LocalStorage
+	GC     StorageGC `mapstructure:"gc"`
+}
+
+type LocalStorage struct {
+	Root    string // 文件存放目录
+	BaseURL string `mapstructure:"base-url"` // 对外访问地址, 路径部分即应用提供文件的路由前缀
+	Secret  string // 签名下载地址的密钥, 留空使用 jwt.secret
 }
 
 type Mail struct {
@@ -73,3 +91,38 @@ type Jwt struct {
 	Secret     string
 	Expiration int64
 }
+
+type Upload struct {
+	Cwebp    string // cwebp 可执行文件路径, 留空则在 PATH 中查找
+	Policies map[string]UploadPolicy
+}
+
+type UploadPolicy struct {
+	MaxSize      int64    `mapstructure:"max-size"` // 单位 MB
+	MaxWidth     int      `mapstructure:"max-width"`
+	MaxHeight    int      `mapstructure:"max-height"`
+	AllowedTypes []string `mapstructure:"allowed-types"`
+	Renditions   []int    // 图片缩略图宽度
+}
+
+type Stream struct {
+	URLExpiration int               `mapstructure:"url-expiration"` // 播放地址有效期, 单位分钟
+	Entitlements  map[string]string // 角色 -> 可播放的最高音质, 未登录为 anonymous
+}
+
+// Trash 管理端删除的歌曲、歌手、歌单、用户与轮播图先进入回收站
+type Trash struct {
+	RetentionDays int `mapstructure:"retention-days"` // 超过该天数后连同文件一并清除, 0 表示不自动清除
+}
+
+// Backup 管理端导出的目录与用户数据备份
+type Backup struct {
+	Dir string // 备份文件存放目录, 留空为 ./backups
+}
+
+// StorageGC 定期对账数据库引用与存储中的对象
+type StorageGC struct {
+	Interval      int  // 单位小时, 0 表示不定期执行
+	GracePeriod   int  `mapstructure:"grace-period"`   // 单位小时, 孤儿对象超过该时长才会删除
+	DeleteOrphans bool `mapstructure:"delete-orphans"` // false 时只记录日志
+}
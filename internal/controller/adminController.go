@@ -1,36 +1,70 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"time"
+	"unicode/utf8"
 	"vibe-music-server/internal/model/dto"
 	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/lrc"
 	"vibe-music-server/internal/pkg/result"
 	"vibe-music-server/internal/pkg/result/consts"
 	"vibe-music-server/internal/service"
 )
 
 type AdminCtrl struct {
-	adminService    *service.AdminService
-	userService     *service.UserService
-	artistService   *service.ArtistService
-	songService     *service.SongService
-	playlistService *service.PlaylistService
-	minioService    *service.MinioService
+	adminService     *service.AdminService
+	userService      *service.UserService
+	artistService    *service.ArtistService
+	songService      *service.SongService
+	playlistService  *service.PlaylistService
+	albumService     *service.AlbumService
+	styleService     *service.StyleService
+	storageService   *service.StorageService
+	uploadService    *service.UploadService
+	renditionService *service.RenditionService
+	reconcileService *service.ReconcileService
+	deletionService  *service.DeletionService
+	trashService     *service.TrashService
+	auditService     *service.AuditService
+	statService      *service.StatService
+	importService    *service.ImportService
+	backupService    *service.BackupService
 }
 
 func NewAdminCtrl(adminService *service.AdminService,
 	userService *service.UserService, artistService *service.ArtistService,
 	songService *service.SongService, playlistService *service.PlaylistService,
-	minioService *service.MinioService) *AdminCtrl {
+	albumService *service.AlbumService, styleService *service.StyleService,
+	storageService *service.StorageService, uploadService *service.UploadService,
+	renditionService *service.RenditionService, reconcileService *service.ReconcileService,
+	deletionService *service.DeletionService, trashService *service.TrashService,
+	auditService *service.AuditService, statService *service.StatService,
+	importService *service.ImportService, backupService *service.BackupService) *AdminCtrl {
 	return &AdminCtrl{
-		adminService:    adminService,
-		userService:     userService,
-		artistService:   artistService,
-		songService:     songService,
-		playlistService: playlistService,
-		minioService:    minioService,
+		adminService:     adminService,
+		userService:      userService,
+		artistService:    artistService,
+		songService:      songService,
+		playlistService:  playlistService,
+		albumService:     albumService,
+		styleService:     styleService,
+		storageService:   storageService,
+		uploadService:    uploadService,
+		renditionService: renditionService,
+		reconcileService: reconcileService,
+		deletionService:  deletionService,
+		trashService:     trashService,
+		auditService:     auditService,
+		statService:      statService,
+		importService:    importService,
+		backupService:    backupService,
 	}
 }
 
@@ -196,9 +230,9 @@ func (a *AdminCtrl) UpdateArtistAvatar(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
 		return
 	}
-	avatarUrl, err := a.minioService.UploadFile(avatar, "artists")
+	avatarUrl, err := a.storageService.UploadFile(avatar, "artists")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, result.Error[result.Nil](consts.FileUpload+consts.Failed))
+		uploadFailed(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, a.artistService.UpdateArtistAvatar(artistId, avatarUrl))
@@ -266,6 +300,61 @@ func (a *AdminCtrl) UpdateSong(c *gin.Context) {
 	c.JSON(http.StatusOK, a.songService.UpdateSong(&songUpdateDTO))
 }
 
+func (a *AdminCtrl) UpdateSongLyric(c *gin.Context) {
+	var songLyricDTO dto.SongLyricDTO
+	if err := c.ShouldBindJSON(&songLyricDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.songService.UpdateSongLyric(&songLyricDTO))
+}
+
+// UploadSongLyric 上传 .lrc 文件, 字段 lyric 必填, translation 可选
+func (a *AdminCtrl) UploadSongLyric(c *gin.Context) {
+	songId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	lyricFile, err := c.FormFile("lyric")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	songLyricDTO := dto.SongLyricDTO{SongID: songId}
+	if songLyricDTO.Lyric, err = readTextFile(lyricFile); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.Lyric+consts.FormatError))
+		return
+	}
+	if translationFile, err := c.FormFile("translation"); err == nil {
+		if songLyricDTO.Translation, err = readTextFile(translationFile); err != nil {
+			c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.Translation+consts.FormatError))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, a.songService.UpdateSongLyric(&songLyricDTO))
+}
+
+// readTextFile 读取 UTF-8 文本文件, 超过歌词长度上限或编码不对时报错
+func readTextFile(fh *multipart.FileHeader) (string, error) {
+	if fh.Size > lrc.MaxSize {
+		return "", errors.New("file too large")
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, lrc.MaxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > lrc.MaxSize || !utf8.Valid(data) {
+		return "", errors.New("invalid text file")
+	}
+	return string(data), nil
+}
+
 func (a *AdminCtrl) UpdateSongCover(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
@@ -282,9 +371,9 @@ func (a *AdminCtrl) UpdateSongCover(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
 		return
 	}
-	coverUrl, err := a.minioService.UploadFile(cover, "songCovers")
+	coverUrl, err := a.storageService.UploadFile(cover, "songCovers")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, result.Error[result.Nil](consts.FileUpload+consts.Failed))
+		uploadFailed(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, a.songService.UpdateSongCover(songId, coverUrl))
@@ -306,12 +395,139 @@ func (a *AdminCtrl) UpdateSongAudio(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
 		return
 	}
-	audioUrl, err := a.minioService.UploadFile(audio, "songs")
+	audioUrl, err := a.storageService.UploadFile(audio, "songs")
+	if err != nil {
+		uploadFailed(c, err)
+		return
+	}
+	src, err := audio.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, result.Error[result.Nil](consts.FileUpload+consts.Failed))
+		return
+	}
+	defer src.Close()
+	c.JSON(http.StatusOK, a.songService.UpdateSongAudio(songId, audioUrl, src, audio.Size))
+}
+
+func (a *AdminCtrl) InitSongAudioUpload(c *gin.Context) {
+	var uploadDTO dto.SongAudioUploadDTO
+	if err := c.ShouldBindJSON(&uploadDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.uploadService.InitSongAudioUpload(&uploadDTO))
+}
+
+// UploadSongAudioPart 请求体为分片原始字节, 请求头 X-Chunk-SHA256 为其 SHA-256
+func (a *AdminCtrl) UploadSongAudioPart(c *gin.Context) {
+	partNumber, err := strconv.Atoi(c.Param("partNumber"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	checksum := c.GetHeader("X-Chunk-SHA256")
+	if checksum == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, service.MaxChunkSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.FileUpload+consts.Failed))
+		return
+	}
+	if len(data) > service.MaxChunkSize {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.ChunkSizeError))
+		return
+	}
+	c.JSON(http.StatusOK, a.uploadService.UploadPart(c.Param("uploadId"), partNumber, data, checksum))
+}
+
+func (a *AdminCtrl) GetSongAudioUpload(c *gin.Context) {
+	c.JSON(http.StatusOK, a.uploadService.GetUpload(c.Param("uploadId")))
+}
+
+func (a *AdminCtrl) CompleteSongAudioUpload(c *gin.Context) {
+	c.JSON(http.StatusOK, a.uploadService.CompleteSongAudioUpload(c.Param("uploadId")))
+}
+
+func (a *AdminCtrl) AbortSongAudioUpload(c *gin.Context) {
+	c.JSON(http.StatusOK, a.uploadService.AbortUpload(c.Param("uploadId")))
+}
+
+func (a *AdminCtrl) GetSongRenditions(c *gin.Context) {
+	songId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.renditionService.GetSongRenditions(songId))
+}
+
+// UploadSongRendition 表单字段 quality 与文件 audio
+func (a *AdminCtrl) UploadSongRendition(c *gin.Context) {
+	songId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	var renditionDTO dto.SongRenditionDTO
+	if err = c.ShouldBind(&renditionDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	audio, err := c.FormFile("audio")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	audioUrl, err := a.storageService.UploadFile(audio, "songs")
+	if err != nil {
+		uploadFailed(c, err)
+		return
+	}
+	src, err := audio.Open()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, result.Error[result.Nil](consts.FileUpload+consts.Failed))
 		return
 	}
-	c.JSON(http.StatusOK, a.songService.UpdateSongAudio(songId, audioUrl))
+	defer src.Close()
+	c.JSON(http.StatusOK, a.renditionService.SaveSongRendition(songId, &renditionDTO, audioUrl, src, audio.Size))
+}
+
+func (a *AdminCtrl) DeleteSongRendition(c *gin.Context) {
+	renditionId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.renditionService.DeleteSongRendition(renditionId))
+}
+
+func (a *AdminCtrl) GetSongAudioMeta(c *gin.Context) {
+	songId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.songService.GetSongAudioMeta(songId))
+}
+
+func (a *AdminCtrl) ApplySongAudioMeta(c *gin.Context) {
+	var applyDTO dto.SongAudioMetaApplyDTO
+	if err := c.ShouldBindJSON(&applyDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.songService.ApplySongAudioMeta(&applyDTO))
+}
+
+func (a *AdminCtrl) DiscardSongAudioMeta(c *gin.Context) {
+	songId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.songService.DiscardSongAudioMeta(songId))
 }
 
 func (a *AdminCtrl) DeleteSong(c *gin.Context) {
@@ -388,9 +604,9 @@ func (a *AdminCtrl) UpdatePlaylistCover(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
 		return
 	}
-	coverUrl, err := a.minioService.UploadFile(cover, "playlistCovers")
+	coverUrl, err := a.storageService.UploadFile(cover, "playlistCovers")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, result.Error[result.Nil](consts.FileUpload+consts.Failed))
+		uploadFailed(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, a.playlistService.UpdatePlaylistCover(playlistId, coverUrl))
@@ -418,3 +634,287 @@ func (a *AdminCtrl) DeletePlaylists(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, a.playlistService.DeletePlaylists(ids))
 }
+
+func (a *AdminCtrl) GetAllAlbums(c *gin.Context) {
+	var albumDTO dto.AlbumDTO
+	if err := c.ShouldBindJSON(&albumDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.GetAllAlbums(&albumDTO))
+}
+
+func (a *AdminCtrl) AddAlbum(c *gin.Context) {
+	var albumAddDTO dto.AlbumAddDTO
+	if err := c.ShouldBindJSON(&albumAddDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.AddAlbum(&albumAddDTO))
+}
+
+func (a *AdminCtrl) UpdateAlbum(c *gin.Context) {
+	var albumUpdateDTO dto.AlbumUpdateDTO
+	if err := c.ShouldBindJSON(&albumUpdateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.UpdateAlbum(&albumUpdateDTO))
+}
+
+func (a *AdminCtrl) UpdateAlbumCover(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	albumId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	cover, err := c.FormFile("cover")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	coverUrl, err := a.storageService.UploadFile(cover, "albumCovers")
+	if err != nil {
+		uploadFailed(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.UpdateAlbumCover(albumId, coverUrl))
+}
+
+func (a *AdminCtrl) UpdateAlbumTracks(c *gin.Context) {
+	var albumTracksDTO dto.AlbumTracksDTO
+	if err := c.ShouldBindJSON(&albumTracksDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.UpdateAlbumTracks(&albumTracksDTO))
+}
+
+func (a *AdminCtrl) DeleteAlbum(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	albumId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.DeleteAlbum(albumId))
+}
+
+func (a *AdminCtrl) DeleteAlbums(c *gin.Context) {
+	var ids []uint64
+	if err := c.ShouldBindJSON(&ids); err != nil || len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.DeleteAlbums(ids))
+}
+
+func (a *AdminCtrl) GetAllStyles(c *gin.Context) {
+	c.JSON(http.StatusOK, a.styleService.GetStyleList())
+}
+
+func (a *AdminCtrl) AddStyle(c *gin.Context) {
+	var styleAddDTO dto.StyleAddDTO
+	if err := c.ShouldBindJSON(&styleAddDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.styleService.AddStyle(&styleAddDTO))
+}
+
+func (a *AdminCtrl) UpdateStyle(c *gin.Context) {
+	var styleUpdateDTO dto.StyleUpdateDTO
+	if err := c.ShouldBindJSON(&styleUpdateDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.styleService.UpdateStyle(&styleUpdateDTO))
+}
+
+func (a *AdminCtrl) MergeStyles(c *gin.Context) {
+	var styleMergeDTO dto.StyleMergeDTO
+	if err := c.ShouldBindJSON(&styleMergeDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.styleService.MergeStyles(&styleMergeDTO))
+}
+
+// DeleteStyle 可选 query 参数 reassignTo: 将该风格的歌曲改挂到目标风格
+func (a *AdminCtrl) DeleteStyle(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	styleId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	var reassignTo *uint64
+	if target := c.Query("reassignTo"); target != "" {
+		targetId, err := strconv.ParseUint(target, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+			return
+		}
+		reassignTo = &targetId
+	}
+	c.JSON(http.StatusOK, a.styleService.DeleteStyle(styleId, reassignTo))
+}
+
+// GetStorageReport 对账存储与数据库, 只报告不删除
+func (a *AdminCtrl) GetStorageReport(c *gin.Context) {
+	c.JSON(http.StatusOK, a.reconcileService.Reconcile(false))
+}
+
+// CleanStorageOrphans 对账并删除上传超过宽限期的孤儿对象
+func (a *AdminCtrl) CleanStorageOrphans(c *gin.Context) {
+	c.JSON(http.StatusOK, a.reconcileService.Reconcile(true))
+}
+
+// PreviewDelete 预演删除, 返回各处关联的处理方式与影响行数, 不做修改
+func (a *AdminCtrl) PreviewDelete(c *gin.Context) {
+	var previewDTO dto.DeletePreviewDTO
+	if err := c.ShouldBindJSON(&previewDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.deletionService.Preview(previewDTO.Target, previewDTO.IDs))
+}
+
+// GetTrash 分页列出回收站中的行, 可按类型筛选
+func (a *AdminCtrl) GetTrash(c *gin.Context) {
+	var trashDTO dto.TrashDTO
+	if err := c.ShouldBindQuery(&trashDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.trashService.GetTrash(&trashDTO))
+}
+
+func (a *AdminCtrl) RestoreTrash(c *gin.Context) {
+	var itemsDTO dto.TrashItemsDTO
+	if err := c.ShouldBindJSON(&itemsDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.trashService.Restore(&itemsDTO))
+}
+
+// PurgeTrash 彻底删除回收站中的行及其文件, 不可恢复
+func (a *AdminCtrl) PurgeTrash(c *gin.Context) {
+	var itemsDTO dto.TrashItemsDTO
+	if err := c.ShouldBindJSON(&itemsDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.trashService.Purge(&itemsDTO))
+}
+
+// GetAuditLogs 分页查询审计日志
+func (a *AdminCtrl) GetAuditLogs(c *gin.Context) {
+	var auditLogDTO dto.AuditLogDTO
+	if err := c.ShouldBindQuery(&auditLogDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.auditService.GetAuditLogs(&auditLogDTO))
+}
+
+// ExportAuditLogs 按查询条件导出审计日志为 CSV 或 JSON 文件
+func (a *AdminCtrl) ExportAuditLogs(c *gin.Context) {
+	var auditLogDTO dto.AuditLogDTO
+	if err := c.ShouldBindQuery(&auditLogDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	res := a.auditService.ExportAuditLogs(&auditLogDTO)
+	if res.Code != 0 {
+		c.JSON(http.StatusOK, res)
+		return
+	}
+	format := auditLogDTO.Format
+	if format == "" {
+		format = "csv"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-log-%s.%s"`, time.Now().Format("20060102150405"), format))
+	if format == "json" {
+		c.JSON(http.StatusOK, *res.Data)
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := service.WriteAuditLogsCSV(c.Writer, *res.Data); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// GetDashboard 仪表盘: 各类总数、按粒度汇总的新增用户/播放/收藏、热门风格与存储用量
+func (a *AdminCtrl) GetDashboard(c *gin.Context) {
+	var dashboardDTO dto.DashboardDTO
+	if err := c.ShouldBindQuery(&dashboardDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.statService.GetDashboard(&dashboardDTO))
+}
+
+// ImportSongs 表单字段 manifest 为 CSV 或 JSON 清单, media 为可选的媒体 ZIP, dryRun=true 时只校验
+func (a *AdminCtrl) ImportSongs(c *gin.Context) {
+	manifest, err := c.FormFile("manifest")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	media, err := c.FormFile("media")
+	if err != nil && !errors.Is(err, http.ErrMissingFile) {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	dryRun := false
+	if v := c.PostForm("dryRun"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, a.importService.ImportSongs(manifest, media, dryRun))
+}
+
+func (a *AdminCtrl) GetImportJob(c *gin.Context) {
+	c.JSON(http.StatusOK, a.importService.GetImportJob(c.Param("id")))
+}
+
+// StartBackup 在后台导出备份, includeUsers=true 时连同用户、收藏与评论
+func (a *AdminCtrl) StartBackup(c *gin.Context) {
+	var backupDTO dto.BackupDTO
+	if err := c.ShouldBindQuery(&backupDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.backupService.StartBackup(&backupDTO))
+}
+
+func (a *AdminCtrl) GetBackups(c *gin.Context) {
+	c.JSON(http.StatusOK, a.backupService.GetBackups())
+}
+
+func (a *AdminCtrl) DownloadBackup(c *gin.Context) {
+	file, ok := a.backupService.BackupPath(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, result.Error[result.Nil](consts.DataNotFound))
+		return
+	}
+	c.FileAttachment(file, c.Param("name"))
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/service"
+)
+
+type AlbumCtrl struct {
+	albumService *service.AlbumService
+}
+
+func NewAlbumCtrl(albumService *service.AlbumService) *AlbumCtrl {
+	return &AlbumCtrl{
+		albumService: albumService,
+	}
+}
+
+func (a *AlbumCtrl) GetAllAlbums(c *gin.Context) {
+	var albumDTO dto.AlbumDTO
+	if err := c.ShouldBindJSON(&albumDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.GetAllAlbums(&albumDTO))
+}
+
+func (a *AlbumCtrl) GetAlbumDetail(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	albumId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusOK, a.albumService.GetAlbumDetail(albumId, nil))
+		return
+	}
+	c.JSON(http.StatusOK, a.albumService.GetAlbumDetail(albumId, claims.(*util.Claims)))
+}
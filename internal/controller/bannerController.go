@@ -12,14 +12,14 @@ import (
 )
 
 type BannerCtrl struct {
-	bannerService *service.BannerService
-	minioService  *service.MinioService
+	bannerService  *service.BannerService
+	storageService *service.StorageService
 }
 
-func NewBannerCtrl(bannerService *service.BannerService, minioService *service.MinioService) *BannerCtrl {
+func NewBannerCtrl(bannerService *service.BannerService, storageService *service.StorageService) *BannerCtrl {
 	return &BannerCtrl{
-		bannerService: bannerService,
-		minioService:  minioService,
+		bannerService:  bannerService,
+		storageService: storageService,
 	}
 }
 
@@ -38,9 +38,9 @@ func (b *BannerCtrl) AddBanner(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
 		return
 	}
-	bannerUrl, err := b.minioService.UploadFile(banner, "banners")
+	bannerUrl, err := b.storageService.UploadFile(banner, "banners")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, result.Error[result.Nil](consts.InternalError))
+		uploadFailed(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, b.bannerService.AddBanner(bannerUrl))
@@ -62,9 +62,9 @@ func (b *BannerCtrl) UpdateBanner(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
 		return
 	}
-	bannerUrl, err := b.minioService.UploadFile(banner, "banners")
+	bannerUrl, err := b.storageService.UploadFile(banner, "banners")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, result.Error[result.Nil](consts.InternalError))
+		uploadFailed(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, b.bannerService.UpdateBanner(bannerId, bannerUrl))
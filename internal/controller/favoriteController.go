@@ -21,6 +21,22 @@ func NewFavoriteCtrl(favoriteService *service.FavoriteService) *FavoriteCtrl {
 	}
 }
 
+// GetFavoriteSongs 获取用户收藏的歌曲
+// need authMiddleware
+func (f *FavoriteCtrl) GetFavoriteSongs(c *gin.Context) {
+	var favoriteSongDTO dto.FavoriteSongDTO
+	if err := c.ShouldBindJSON(&favoriteSongDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exist := c.Get("claims")
+	if !exist {
+		c.JSON(http.StatusUnauthorized, result.Error[result.Nil](consts.NotLogin))
+		return
+	}
+	c.JSON(http.StatusOK, f.favoriteService.GetUserFavoriteSongs(&favoriteSongDTO, claims.(*util.Claims)))
+}
+
 // CollectSong 收藏歌曲
 // need authMiddleware
 func (f *FavoriteCtrl) CollectSong(c *gin.Context) {
@@ -120,3 +136,135 @@ func (f *FavoriteCtrl) CancelCollectPlaylist(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, f.favoriteService.CancelCollectPlaylist(playlistId, claims.(*util.Claims)))
 }
+
+// GetFavoriteArtists 获取用户收藏的歌手
+// need authMiddleware
+func (f *FavoriteCtrl) GetFavoriteArtists(c *gin.Context) {
+	var artistDTO dto.ArtistDTO
+	if err := c.ShouldBindJSON(&artistDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exist := c.Get("claims")
+	if !exist {
+		c.JSON(http.StatusUnauthorized, result.Error[result.Nil](consts.NotLogin))
+		return
+	}
+	c.JSON(http.StatusOK, f.favoriteService.GetUserFavoriteArtists(&artistDTO, claims.(*util.Claims)))
+}
+
+// CollectArtist 收藏歌手
+// need authMiddleware
+func (f *FavoriteCtrl) CollectArtist(c *gin.Context) {
+	artistIdStr := c.Query("artistId")
+	if artistIdStr == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	artistId, err := strconv.ParseUint(artistIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exist := c.Get("claims")
+	if !exist {
+		c.JSON(http.StatusUnauthorized, result.Error[result.Nil](consts.NotLogin))
+		return
+	}
+	c.JSON(http.StatusOK, f.favoriteService.CollectArtist(artistId, claims.(*util.Claims)))
+}
+
+// CancelCollectArtist 取消收藏歌手
+// need authMiddleware
+func (f *FavoriteCtrl) CancelCollectArtist(c *gin.Context) {
+	artistIdStr := c.Query("artistId")
+	if artistIdStr == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	artistId, err := strconv.ParseUint(artistIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exist := c.Get("claims")
+	if !exist {
+		c.JSON(http.StatusUnauthorized, result.Error[result.Nil](consts.NotLogin))
+		return
+	}
+	c.JSON(http.StatusOK, f.favoriteService.CancelCollectArtist(artistId, claims.(*util.Claims)))
+}
+
+// GetFavoriteAlbums 获取用户收藏的专辑
+// need authMiddleware
+func (f *FavoriteCtrl) GetFavoriteAlbums(c *gin.Context) {
+	var albumDTO dto.AlbumDTO
+	if err := c.ShouldBindJSON(&albumDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exist := c.Get("claims")
+	if !exist {
+		c.JSON(http.StatusUnauthorized, result.Error[result.Nil](consts.NotLogin))
+		return
+	}
+	c.JSON(http.StatusOK, f.favoriteService.GetUserFavoriteAlbums(&albumDTO, claims.(*util.Claims)))
+}
+
+// CollectAlbum 收藏专辑
+// need authMiddleware
+func (f *FavoriteCtrl) CollectAlbum(c *gin.Context) {
+	albumIdStr := c.Query("albumId")
+	if albumIdStr == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	albumId, err := strconv.ParseUint(albumIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exist := c.Get("claims")
+	if !exist {
+		c.JSON(http.StatusUnauthorized, result.Error[result.Nil](consts.NotLogin))
+		return
+	}
+	c.JSON(http.StatusOK, f.favoriteService.CollectAlbum(albumId, claims.(*util.Claims)))
+}
+
+// CancelCollectAlbum 取消收藏专辑
+// need authMiddleware
+func (f *FavoriteCtrl) CancelCollectAlbum(c *gin.Context) {
+	albumIdStr := c.Query("albumId")
+	if albumIdStr == "" {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	albumId, err := strconv.ParseUint(albumIdStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exist := c.Get("claims")
+	if !exist {
+		c.JSON(http.StatusUnauthorized, result.Error[result.Nil](consts.NotLogin))
+		return
+	}
+	c.JSON(http.StatusOK, f.favoriteService.CancelCollectAlbum(albumId, claims.(*util.Claims)))
+}
+
+// IsFavorited 批量查询收藏状态
+// need authMiddleware
+func (f *FavoriteCtrl) IsFavorited(c *gin.Context) {
+	var favoriteCheckDTO dto.FavoriteCheckDTO
+	if err := c.ShouldBindJSON(&favoriteCheckDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, exist := c.Get("claims")
+	if !exist {
+		c.JSON(http.StatusUnauthorized, result.Error[result.Nil](consts.NotLogin))
+		return
+	}
+	c.JSON(http.StatusOK, f.favoriteService.CheckFavorites(&favoriteCheckDTO, claims.(*util.Claims)))
+}
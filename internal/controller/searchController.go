@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/service"
+)
+
+type SearchCtrl struct {
+	searchService *service.SearchService
+}
+
+func NewSearchCtrl(searchService *service.SearchService) *SearchCtrl {
+	return &SearchCtrl{
+		searchService: searchService,
+	}
+}
+
+// Search GET /search?q=&type=&pageNum=&pageSize=
+func (s *SearchCtrl) Search(c *gin.Context) {
+	var searchDTO dto.SearchDTO
+	if err := c.ShouldBindQuery(&searchDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, s.searchService.Search(&searchDTO))
+}
+
+// Suggest GET /search/suggest?q=&limit=
+func (s *SearchCtrl) Suggest(c *gin.Context) {
+	var suggestDTO dto.SearchSuggestDTO
+	if err := c.ShouldBindQuery(&suggestDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, s.searchService.Suggest(&suggestDTO))
+}
+
+// GetTrending GET /search/trending?days=&limit=
+func (s *SearchCtrl) GetTrending(c *gin.Context) {
+	var trendingDTO dto.SearchTrendingDTO
+	if err := c.ShouldBindQuery(&trendingDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, s.searchService.GetTrending(&trendingDTO))
+}
@@ -12,12 +12,14 @@ import (
 )
 
 type SongCtrl struct {
-	songService *service.SongService
+	songService      *service.SongService
+	renditionService *service.RenditionService
 }
 
-func NewSongCtrl(songService *service.SongService) *SongCtrl {
+func NewSongCtrl(songService *service.SongService, renditionService *service.RenditionService) *SongCtrl {
 	return &SongCtrl{
-		songService: songService,
+		songService:      songService,
+		renditionService: renditionService,
 	}
 }
 
@@ -62,3 +64,35 @@ func (s *SongCtrl) GetSongDetail(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, s.songService.GetSongDetail(songId, claims.(*util.Claims)))
 }
+
+// GetSongLyrics GET /song/lyrics/:id
+func (s *SongCtrl) GetSongLyrics(c *gin.Context) {
+	songId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	c.JSON(http.StatusOK, s.songService.GetSongLyrics(songId))
+}
+
+// GetSongStream GET /song/stream/:id?quality=high&redirect=true
+func (s *SongCtrl) GetSongStream(c *gin.Context) {
+	songId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	var streamDTO dto.SongStreamDTO
+	if err = c.ShouldBindQuery(&streamDTO); err != nil {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.InvalidParams))
+		return
+	}
+	claims, _ := c.Get("claims")
+	userClaims, _ := claims.(*util.Claims)
+	res := s.renditionService.GetSongStream(songId, &streamDTO, userClaims)
+	if streamDTO.Redirect && res.Data != nil {
+		c.Redirect(http.StatusFound, res.Data.URL)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
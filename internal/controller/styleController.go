@@ -0,0 +1,21 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"vibe-music-server/internal/service"
+)
+
+type StyleCtrl struct {
+	styleService *service.StyleService
+}
+
+func NewStyleCtrl(styleService *service.StyleService) *StyleCtrl {
+	return &StyleCtrl{
+		styleService: styleService,
+	}
+}
+
+func (s *StyleCtrl) GetStyleList(c *gin.Context) {
+	c.JSON(http.StatusOK, s.styleService.GetStyleList())
+}
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/upload"
+)
+
+// uploadFailed 根据上传错误写回响应: 不符合上传策略返回 400 及具体原因, 其余为 500
+func uploadFailed(c *gin.Context, err error) {
+	var rejectErr *upload.RejectError
+	if errors.As(err, &rejectErr) {
+		c.JSON(http.StatusBadRequest, result.Error[result.Nil](consts.FileUpload+consts.Failed+": "+rejectErr.Msg))
+		return
+	}
+	log.Printf("upload err: %v\n", err)
+	c.JSON(http.StatusInternalServerError, result.Error[result.Nil](consts.FileUpload+consts.Failed))
+}
@@ -12,14 +12,14 @@ import (
 )
 
 type UserCtrl struct {
-	userService  *service.UserService
-	minioService *service.MinioService
+	userService    *service.UserService
+	storageService *service.StorageService
 }
 
-func NewUserCtrl(userService *service.UserService, minioService *service.MinioService) *UserCtrl {
+func NewUserCtrl(userService *service.UserService, storageService *service.StorageService) *UserCtrl {
 	return &UserCtrl{
-		userService:  userService,
-		minioService: minioService,
+		userService:    userService,
+		storageService: storageService,
 	}
 }
 
@@ -93,9 +93,9 @@ func (u *UserCtrl) UpdateUserAvatar(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, result.Error[string](consts.InvalidParams))
 		return
 	}
-	avatarUrl, err := u.minioService.UploadFile(avatar, "users")
+	avatarUrl, err := u.storageService.UploadFile(avatar, "users")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, result.Error[string](consts.FileUpload+consts.Failed))
+		uploadFailed(c, err)
 		return
 	}
 	claims, exists := c.Get("claims")
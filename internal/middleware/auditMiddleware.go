@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"io"
+	"strings"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/service"
+)
+
+// 超过该大小的请求体不记录, 响应只保留开头用于读取结果
+const (
+	maxAuditBody     = 64 << 10
+	maxAuditResponse = 4 << 10
+)
+
+// auditWriter 在写出响应的同时保留响应开头
+type auditWriter struct {
+	gin.ResponseWriter
+	head bytes.Buffer
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if n := maxAuditResponse - w.head.Len(); n > 0 {
+		w.head.Write(b[:min(n, len(b))])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// AuditMiddleware 记录管理端写请求的审计日志, 放在 AdminAuthMiddleware 之后以取得管理员身份
+func AuditMiddleware(auditService *service.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		action := handlerAction(c.HandlerName())
+		if !auditService.Audited(c.Request.Method, action) {
+			c.Next()
+			return
+		}
+		req := service.AuditRequest{
+			Action:    action,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Params:    make(map[string]string, len(c.Params)),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		for _, p := range c.Params {
+			req.Params[p.Key] = p.Value
+		}
+		if claims, ok := c.Get("claims"); ok {
+			if adminClaims, ok := claims.(*util.Claims); ok && adminClaims != nil {
+				req.AdminID = adminClaims.UserId
+				req.AdminName = adminClaims.Username
+			}
+		}
+		// 读出 JSON 请求体后放回, 供后续绑定
+		if c.ContentType() == "application/json" && c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			_ = c.Request.Body.Close()
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if err == nil && len(body) <= maxAuditBody {
+				req.Body = body
+			}
+		}
+		done := auditService.Begin(req)
+		w := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		done(w.Status(), w.head.Bytes())
+	}
+}
+
+// handlerAction 从处理函数名中取出方法名, 如 "...controller.(*AdminCtrl).UpdateSong-fm" 取 UpdateSong
+func handlerAction(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
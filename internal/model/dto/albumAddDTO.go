@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+type AlbumAddDTO struct {
+	ArtistID    uint64    `json:"artistId" binding:"required"`
+	Title       string    `json:"title" binding:"required,max=200"`
+	ReleaseDate time.Time `json:"releaseDate" time_format:"2006-01-02"` // yyyy-MM-dd
+	Type        uint8     `json:"type" binding:"max=2"`                 // 0-LP 1-EP 2-单曲
+	Description string    `json:"description"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+type AlbumDTO struct {
+	PageNum    int     `json:"pageNum" binding:"required"`
+	PageSize   int     `json:"pageSize" binding:"required"`
+	Title      *string `json:"title"`
+	ArtistID   *uint64 `json:"artistId"`
+	ArtistName *string `json:"artistName"`
+	Type       *uint8  `json:"type"` // 0-LP 1-EP 2-单曲
+}
@@ -0,0 +1,12 @@
+package dto
+
+type AlbumTrackDTO struct {
+	SongID      uint64 `json:"songId" binding:"required"`
+	DiscNumber  uint   `json:"discNumber" binding:"min=1"`
+	TrackNumber uint   `json:"trackNumber" binding:"min=1"`
+}
+
+type AlbumTracksDTO struct {
+	AlbumID uint64          `json:"albumId" binding:"required"`
+	Tracks  []AlbumTrackDTO `json:"tracks" binding:"required,dive"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// AuditLogDTO 审计日志查询条件, 导出时忽略分页
+type AuditLogDTO struct {
+	AdminID    *uint64   `form:"adminId"`
+	Action     string    `form:"action" binding:"max=64"`
+	EntityType string    `form:"entityType" binding:"max=32"`
+	EntityID   *uint64   `form:"entityId"`
+	Success    *bool     `form:"success"`
+	StartTime  time.Time `form:"startTime" time_format:"2006-01-02"`
+	EndTime    time.Time `form:"endTime" time_format:"2006-01-02"` // 包含当天
+	PageNum    int       `form:"pageNum" binding:"omitempty,min=1"`
+	PageSize   int       `form:"pageSize" binding:"omitempty,min=1,max=100"`
+	Format     string    `form:"format" binding:"omitempty,oneof=csv json"` // 导出格式, 默认 csv
+}
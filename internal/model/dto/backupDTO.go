@@ -0,0 +1,6 @@
+package dto
+
+// BackupDTO 导出备份, IncludeUsers 为 true 时连同用户、收藏与评论
+type BackupDTO struct {
+	IncludeUsers bool `form:"includeUsers"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+// DashboardDTO 仪表盘的日期范围与粒度, 未指定时为最近 30 天、按天
+type DashboardDTO struct {
+	StartDate   time.Time `form:"startDate" time_format:"2006-01-02"`
+	EndDate     time.Time `form:"endDate" time_format:"2006-01-02"` // 包含当天
+	Granularity string    `form:"granularity" binding:"omitempty,oneof=day week month"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+// DeletePreviewDTO 预演删除 target 类型的 ids
+type DeletePreviewDTO struct {
+	Target string   `json:"target" binding:"required,oneof=song playlist artist album user banner"`
+	IDs    []uint64 `json:"ids" binding:"required,min=1"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type FavoriteCheckDTO struct {
+	Type uint8    `json:"type" binding:"max=3"` // 0-歌曲 1-歌单 2-歌手 3-专辑
+	IDs  []uint64 `json:"ids" binding:"required,max=500"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type FavoriteSongDTO struct {
+	PageNum    int     `json:"pageNum" binding:"required"`
+	PageSize   int     `json:"pageSize" binding:"required"`
+	SongName   *string `json:"songName"`
+	ArtistName *string `json:"artistName"`
+	Album      *string `json:"album"`
+	SortBy     string  `json:"sortBy" binding:"omitempty,oneof=createTime songName artistName"` // 默认 createTime
+	Order      string  `json:"order" binding:"omitempty,oneof=asc desc"`                        // 默认 createTime 倒序, 其余正序
+}
@@ -0,0 +1,8 @@
+package dto
+
+type SearchDTO struct {
+	Q        string `form:"q" binding:"required,max=100"`
+	Type     string `form:"type" binding:"omitempty,oneof=song artist album playlist"` // 为空时返回全部分组
+	PageNum  int    `form:"pageNum" binding:"omitempty,min=1"`
+	PageSize int    `form:"pageSize" binding:"omitempty,min=1,max=50"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type SearchSuggestDTO struct {
+	Q     string `form:"q" binding:"required,max=50"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=10"`
+}
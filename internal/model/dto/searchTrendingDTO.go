@@ -0,0 +1,6 @@
+package dto
+
+type SearchTrendingDTO struct {
+	Days  int `form:"days" binding:"omitempty,min=1,max=30"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
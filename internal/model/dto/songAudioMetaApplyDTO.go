@@ -0,0 +1,7 @@
+package dto
+
+// SongAudioMetaApplyDTO Fields 为空时应用全部已提取的字段
+type SongAudioMetaApplyDTO struct {
+	SongID uint64   `json:"songId" binding:"required"`
+	Fields []string `json:"fields" binding:"omitempty,dive,oneof=duration format title album releaseTime track lyric cover"`
+}
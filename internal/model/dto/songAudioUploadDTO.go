@@ -0,0 +1,9 @@
+package dto
+
+// SongAudioUploadDTO 发起分片上传, ChunkSize 为 0 时使用默认分片大小
+type SongAudioUploadDTO struct {
+	SongID    uint64 `json:"songId" binding:"required"`
+	Filename  string `json:"filename" binding:"required,max=255"`
+	Size      int64  `json:"size" binding:"required,gt=0"`
+	ChunkSize int64  `json:"chunkSize" binding:"omitempty,gte=5242880,lte=67108864"`
+}
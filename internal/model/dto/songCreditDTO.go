@@ -0,0 +1,6 @@
+package dto
+
+type SongCreditDTO struct {
+	ArtistID uint64 `json:"artistId" binding:"required"`
+	Role     uint8  `json:"role" binding:"max=4"` // 0-主要 1-合作 2-作曲 3-作词 4-制作
+}
@@ -6,4 +6,5 @@ type SongDTO struct {
 	SongName   *string `json:"songName"`
 	ArtistName *string `json:"artistName"`
 	Album      *string `json:"album"`
+	Style      *string `json:"style"` // 按风格名精确筛选
 }
@@ -0,0 +1,16 @@
+package dto
+
+// SongImportRowDTO 导入清单中的一行, CSV 表头与 JSON 字段名相同
+type SongImportRowDTO struct {
+	SongName    string `json:"songName"`
+	Artist      string `json:"artist"`     // 主歌手名, 不存在时新建
+	Artists     string `json:"artists"`    // 合作歌手名, 以 ; 分隔, 不存在时新建
+	Album       string `json:"album"`      // 主歌手名下有同名专辑时关联该专辑
+	DiscNumber  uint   `json:"discNumber"` // 默认 1
+	TrackNumber uint   `json:"trackNumber"`
+	Style       string `json:"style"`       // 以逗号分隔, 须为已登记的风格
+	ReleaseTime string `json:"releaseTime"` // yyyy-MM-dd
+	Audio       string `json:"audio"`       // 媒体压缩包中的路径, 下同
+	Cover       string `json:"cover"`       // 为空时使用音频内嵌的封面
+	Lyric       string `json:"lyric"`       // LRC 文件
+}
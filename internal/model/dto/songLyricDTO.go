@@ -0,0 +1,8 @@
+package dto
+
+// SongLyricDTO 歌词为空表示清除
+type SongLyricDTO struct {
+	SongID      uint64 `json:"songId" binding:"required"`
+	Lyric       string `json:"lyric" binding:"max=65535"`
+	Translation string `json:"translation" binding:"max=65535"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+// SongRenditionDTO 上传某一音质版本, 文件在表单字段 audio 中; 同一音质重复上传会替换
+type SongRenditionDTO struct {
+	Quality string `form:"quality" binding:"required,oneof=standard high lossless"`
+}
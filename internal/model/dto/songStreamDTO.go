@@ -0,0 +1,7 @@
+package dto
+
+// SongStreamDTO Quality 为空时取可播放的最高音质; Redirect 为 true 时直接重定向到音频地址, 便于 <audio> 使用
+type SongStreamDTO struct {
+	Quality  string `form:"quality" binding:"omitempty,oneof=standard high lossless"`
+	Redirect bool   `form:"redirect"`
+}
@@ -3,10 +3,14 @@ package dto
 import "time"
 
 type SongUpdateDTO struct {
-	SongID      uint64    `json:"songId"`
-	ArtistID    uint64    `json:"artistId"`
-	SongName    string    `json:"songName"`
-	Album       string    `json:"album"`
-	Style       string    `json:"style"`
-	ReleaseTime time.Time `json:"releaseTime" time_format:"2006-01-02"` // yyyy-MM-dd
+	SongID      uint64          `json:"songId"`
+	ArtistID    uint64          `json:"artistId"`
+	Artists     []SongCreditDTO `json:"artists" binding:"omitempty,max=20,dive"` // 署名列表, 按顺序排列; 为空时仅署名主歌手
+	SongName    string          `json:"songName"`
+	Album       string          `json:"album"`
+	AlbumID     *uint64         `json:"albumId"` // 为空时解除专辑关联
+	DiscNumber  uint            `json:"discNumber"`
+	TrackNumber uint            `json:"trackNumber"`
+	Style       string          `json:"style"`
+	ReleaseTime time.Time       `json:"releaseTime" time_format:"2006-01-02"` // yyyy-MM-dd
 }
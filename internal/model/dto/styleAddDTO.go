@@ -0,0 +1,5 @@
+package dto
+
+type StyleAddDTO struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type StyleMergeDTO struct {
+	SourceIDs []uint64 `json:"sourceIds" binding:"required,min=1"`
+	TargetID  uint64   `json:"targetId" binding:"required"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type StyleUpdateDTO struct {
+	StyleID uint64 `json:"styleId" binding:"required"`
+	Name    string `json:"name" binding:"required,max=50"`
+}
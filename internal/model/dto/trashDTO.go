@@ -0,0 +1,14 @@
+package dto
+
+// TrashDTO 回收站列表, Type 为空时列出全部类型
+type TrashDTO struct {
+	Type     string `form:"type" binding:"omitempty,oneof=song artist playlist user banner"`
+	PageNum  int    `form:"pageNum" binding:"required,min=1"`
+	PageSize int    `form:"pageSize" binding:"required,min=1,max=100"`
+}
+
+// TrashItemsDTO 恢复或清除回收站中 Type 类型的 ids
+type TrashItemsDTO struct {
+	Type string   `json:"type" binding:"required,oneof=song artist playlist user banner"`
+	IDs  []uint64 `json:"ids" binding:"required,min=1"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+type AlbumType uint8
+
+const (
+	AlbumTypeLP     AlbumType = 0 // 录音室专辑
+	AlbumTypeEP     AlbumType = 1 // EP
+	AlbumTypeSingle AlbumType = 2 // 单曲
+)
+
+type Album struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement;column:id"`
+	ArtistID    uint64    `gorm:"index;not null;column:artist_id"`
+	Title       string    `gorm:"size:200;not null;column:title"`
+	CoverURL    string    `gorm:"size:500;column:cover_url"`
+	ReleaseDate time.Time `gorm:"type:date;column:release_date"`     // yyyy-MM-dd
+	Type        AlbumType `gorm:"type:tinyint;not null;column:type"` // 0-LP 1-EP 2-单曲
+	Description string    `gorm:"type:text;column:description"`
+}
+
+func (Album) TableName() string { return "tb_album" }
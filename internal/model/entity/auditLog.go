@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// AuditLog 管理端一次写请求的审计记录
+type AuditLog struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement;column:id"`
+	AdminID    uint64    `gorm:"index;column:admin_id"`
+	AdminName  string    `gorm:"size:50;column:admin_name"`
+	Action     string    `gorm:"size:64;index;column:action"` // 处理请求的方法名, 如 UpdateSong
+	Method     string    `gorm:"size:10;column:method"`
+	Path       string    `gorm:"size:255;column:path"`
+	EntityType string    `gorm:"size:32;index;column:entity_type"`
+	EntityIDs  string    `gorm:"size:1000;column:entity_ids"` // 逗号分隔
+	Diff       string    `gorm:"type:mediumtext;column:diff"` // JSON, 各实体修改前后变化的字段
+	Status     int       `gorm:"column:status"`               // HTTP 状态码
+	Success    bool      `gorm:"column:success"`              // 业务结果 code 为 0
+	Message    string    `gorm:"size:255;column:message"`
+	IP         string    `gorm:"size:64;column:ip"`
+	UserAgent  string    `gorm:"size:255;column:user_agent"`
+	CreateTime time.Time `gorm:"type:datetime;not null;index;column:create_time"`
+}
+
+func (AuditLog) TableName() string { return "tb_audit_log" }
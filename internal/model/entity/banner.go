@@ -1,5 +1,7 @@
 package entity
 
+import "gorm.io/gorm"
+
 type BannerStatus uint8
 
 const (
@@ -8,9 +10,10 @@ const (
 )
 
 type Banner struct {
-	ID        uint64       `gorm:"primaryKey;autoIncrement;column:id"`
-	BannerURL string       `gorm:"size:255;not null;column:banner_url"`
-	Status    BannerStatus `gorm:"type:tinyint;not null;column:status"`
+	ID        uint64         `gorm:"primaryKey;autoIncrement;column:id"`
+	BannerURL string         `gorm:"size:255;not null;column:banner_url"`
+	Status    BannerStatus   `gorm:"type:tinyint;not null;column:status"`
+	DeletedAt gorm.DeletedAt `gorm:"index;column:deleted_at" json:"-"` // 软删除, 进入回收站
 }
 
 func (Banner) TableName() string { return "tb_banner" }
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+const (
+	MetricNewUsers   = "new_users"
+	MetricPlays      = "plays"
+	MetricFavorites  = "favorites"
+	MetricStylePlays = "style_plays" // 按风格统计的播放, DimID 为风格 id
+)
+
+// DailyStat 按天预聚合的统计值, 由源表重算得到, 可随时删除重建
+type DailyStat struct {
+	StatDate time.Time `gorm:"type:date;primaryKey;column:stat_date"`
+	Metric   string    `gorm:"size:32;primaryKey;column:metric"`
+	DimID    uint64    `gorm:"primaryKey;column:dim_id"`
+	Value    int64     `gorm:"not null;column:value"`
+}
+
+func (DailyStat) TableName() string { return "tb_daily_stat" }
@@ -7,14 +7,18 @@ type FavoriteType uint8
 const (
 	FavoriteTypeSong     FavoriteType = 0 // 歌曲
 	FavoriteTypePlaylist FavoriteType = 1 // 歌单
+	FavoriteTypeArtist   FavoriteType = 2 // 歌手
+	FavoriteTypeAlbum    FavoriteType = 3 // 专辑
 )
 
 type Favorite struct {
 	ID         uint64       `gorm:"primaryKey;autoIncrement;column:id"`
 	UserID     uint64       `gorm:"index;not null;column:user_id"`
-	Type       FavoriteType `gorm:"type:tinyint;not null;column:type"` // 0-歌曲 1-歌单
+	Type       FavoriteType `gorm:"type:tinyint;not null;column:type"` // 0-歌曲 1-歌单 2-歌手 3-专辑
 	SongID     *uint64      `gorm:"index;column:song_id"`              // 收藏歌曲时非空
 	PlaylistID *uint64      `gorm:"index;column:playlist_id"`          // 收藏歌单时非空
+	ArtistID   *uint64      `gorm:"index;column:artist_id"`            // 收藏歌手时非空
+	AlbumID    *uint64      `gorm:"index;column:album_id"`             // 收藏专辑时非空
 	CreateTime time.Time    `gorm:"type:datetime;not null;column:create_time"`
 }
 
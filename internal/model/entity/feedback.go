@@ -4,7 +4,7 @@ import "time"
 
 type Feedback struct {
 	ID         uint      `gorm:"primaryKey;autoIncrement;column:id"`
-	UserID     uint64    `gorm:"index;not null;column:user_id"`
+	UserID     *uint64   `gorm:"index;column:user_id"` // 用户删除后为空
 	Feedback   string    `gorm:"type:text;not null;column:feedback"`
 	CreateTime time.Time `gorm:"type:datetime;not null;column:create_time"`
 }
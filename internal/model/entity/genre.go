@@ -1,8 +1,9 @@
 package entity
 
+// Genre 歌曲与风格的多对多关联, 联合主键：歌曲+风格
 type Genre struct {
 	SongID  uint64 `gorm:"primaryKey;column:song_id"`
-	StyleID uint64 `gorm:"column:style_id"`
+	StyleID uint64 `gorm:"primaryKey;index;column:style_id"`
 }
 
 func (Genre) TableName() string { return "tb_genre" }
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	ImportPending = "pending"
+	ImportRunning = "running"
+	ImportDone    = "done"
+	ImportFailed  = "failed" // 任务整体失败, 如服务重启; 单行失败不影响任务状态
+)
+
+// ImportJob 批量导入任务, 逐行结果在完成后以 JSON 写入 Report
+type ImportJob struct {
+	ID         string     `gorm:"primaryKey;size:36;column:id"`
+	Status     string     `gorm:"size:16;not null;column:status"`
+	DryRun     bool       `gorm:"not null;column:dry_run"`
+	Manifest   string     `gorm:"size:255;not null;column:manifest"` // 清单文件名
+	Total      int        `gorm:"not null;column:total"`
+	Processed  int        `gorm:"not null;column:processed"`
+	Succeeded  int        `gorm:"not null;column:succeeded"`
+	Failed     int        `gorm:"not null;column:failed"`
+	Message    string     `gorm:"size:255;not null;column:message"`
+	Report     string     `gorm:"type:mediumtext;column:report"`
+	CreateTime time.Time  `gorm:"type:datetime;not null;column:create_time"`
+	FinishTime *time.Time `gorm:"type:datetime;column:finish_time"`
+}
+
+func (ImportJob) TableName() string { return "tb_import_job" }
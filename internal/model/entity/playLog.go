@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// PlayLog 一次播放, 在返回播放地址时记录
+type PlayLog struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement;column:id"`
+	SongID     uint64    `gorm:"index;not null;column:song_id"`
+	UserID     *uint64   `gorm:"column:user_id"` // 未登录为空
+	CreateTime time.Time `gorm:"type:datetime;index;not null;column:create_time"`
+}
+
+func (PlayLog) TableName() string { return "tb_play_log" }
@@ -1,11 +1,14 @@
 package entity
 
+import "gorm.io/gorm"
+
 type Playlist struct {
-	ID           uint   `gorm:"primaryKey;autoIncrement;column:id"`
-	Title        string `gorm:"size:200;not null;column:title"`
-	CoverURL     string `gorm:"size:500;column:cover_url"`
-	Introduction string `gorm:"type:text;column:introduction"`
-	Style        string `gorm:"size:100;column:style"`
+	ID           uint           `gorm:"primaryKey;autoIncrement;column:id"`
+	Title        string         `gorm:"size:200;not null;column:title"`
+	CoverURL     string         `gorm:"size:500;column:cover_url"`
+	Introduction string         `gorm:"type:text;column:introduction"`
+	Style        string         `gorm:"size:100;column:style"`
+	DeletedAt    gorm.DeletedAt `gorm:"index;column:deleted_at" json:"-"` // 软删除, 进入回收站
 }
 
 func (Playlist) TableName() string { return "tb_playlist" }
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// ScanFile 本地曲库中已扫描的音频文件, 按路径唯一
+type ScanFile struct {
+	ID       uint64    `gorm:"primaryKey;autoIncrement;column:id"`
+	Path     string    `gorm:"size:700;not null;column:path"`
+	Size     int64     `gorm:"not null;column:size"`
+	ModTime  int64     `gorm:"not null;column:mod_time"` // Unix 纳秒
+	Sha256   string    `gorm:"size:64;not null;column:sha256"`
+	SongID   *uint64   `gorm:"column:song_id"` // 歌曲彻底删除后为空
+	ScanTime time.Time `gorm:"type:datetime;not null;column:scan_time"`
+}
+
+func (ScanFile) TableName() string { return "tb_scan_file" }
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// SchemaMigration 已执行的迁移脚本, 以文件名为版本
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey;size:255;column:version"`
+	AppliedAt time.Time `gorm:"type:datetime;not null;column:applied_at"`
+}
+
+func (SchemaMigration) TableName() string { return "tb_schema_migration" }
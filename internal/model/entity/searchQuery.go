@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// SearchQuery 按天汇总的搜索词次数, 不记录用户信息
+type SearchQuery struct {
+	Day   time.Time `gorm:"primaryKey;type:date;column:day"`
+	Query string    `gorm:"primaryKey;size:100;column:query"` // 规范化后的搜索词
+	Count uint64    `gorm:"not null;default:0;column:count"`
+}
+
+func (SearchQuery) TableName() string { return "tb_search_query" }
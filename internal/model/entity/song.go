@@ -1,18 +1,29 @@
 package entity
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type Song struct {
-	ID          uint      `gorm:"primaryKey;autoIncrement;column:id"`
-	ArtistID    uint      `gorm:"index;not null;column:artist_id"`
-	Name        string    `gorm:"size:200;not null;column:name"`
-	Album       string    `gorm:"size:200;column:album"`
-	Lyric       string    `gorm:"type:text;column:lyric"`
-	Duration    string    `gorm:"size:10;column:duration"` // mm:ss 或 ss
-	Style       string    `gorm:"size:100;column:style"`
-	CoverURL    string    `gorm:"size:500;column:cover_url"`
-	AudioURL    string    `gorm:"size:500;column:audio_url"`
-	ReleaseTime time.Time `gorm:"type:date;column:release_time"`
+	ID          uint           `gorm:"primaryKey;autoIncrement;column:id"`
+	ArtistID    uint           `gorm:"index;not null;column:artist_id"`
+	AlbumID     *uint64        `gorm:"index;column:album_id"` // 可为空, 未归属专辑
+	Name        string         `gorm:"size:200;not null;column:name"`
+	Album       string         `gorm:"size:200;column:album"` // 专辑名冗余, 与 tb_album.title 同步
+	DiscNumber  uint           `gorm:"default:1;column:disc_number"`
+	TrackNumber uint           `gorm:"default:0;column:track_number"`
+	Lyric       string         `gorm:"type:text;column:lyric"`             // LRC 格式, 可含逐字时间
+	Translation string         `gorm:"type:text;column:lyric_translation"` // 翻译歌词, LRC 格式
+	Duration    string         `gorm:"size:10;column:duration"`            // mm:ss 或 ss
+	Codec       string         `gorm:"size:20;column:codec"`
+	Bitrate     int            `gorm:"default:0;column:bitrate"` // kbps
+	SampleRate  int            `gorm:"default:0;column:sample_rate"`
+	Style       string         `gorm:"size:100;column:style"`
+	CoverURL    string         `gorm:"size:500;column:cover_url"`
+	AudioURL    string         `gorm:"size:500;column:audio_url"`
+	ReleaseTime time.Time      `gorm:"type:date;column:release_time"`
+	DeletedAt   gorm.DeletedAt `gorm:"index;column:deleted_at" json:"-"` // 软删除, 进入回收站
 }
 
 func (Song) TableName() string { return "tb_song" }
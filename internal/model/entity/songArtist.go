@@ -0,0 +1,20 @@
+package entity
+
+type CreditRole uint8
+
+const (
+	CreditRolePrimary  CreditRole = 0 // 主唱/主要歌手
+	CreditRoleFeatured CreditRole = 1 // 合作/特邀
+	CreditRoleComposer CreditRole = 2 // 作曲
+	CreditRoleLyricist CreditRole = 3 // 作词
+	CreditRoleProducer CreditRole = 4 // 制作人
+)
+
+type SongArtist struct {
+	SongID   uint64     `gorm:"primaryKey;column:song_id"`
+	ArtistID uint64     `gorm:"primaryKey;index;column:artist_id"`
+	Role     CreditRole `gorm:"primaryKey;type:tinyint;column:role"` // 0-主要 1-合作 2-作曲 3-作词 4-制作
+	Sort     uint       `gorm:"not null;default:0;column:sort"`      // 同一歌曲内的署名顺序
+}
+
+func (SongArtist) TableName() string { return "tb_song_artist" }
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// SongAudioMeta 上传音频时提取的元数据, 待管理员确认后写入歌曲
+type SongAudioMeta struct {
+	SongID      uint64    `gorm:"primaryKey;column:song_id"`
+	AudioURL    string    `gorm:"size:500;column:audio_url"` // 提取来源
+	Codec       string    `gorm:"size:20;column:codec"`
+	DurationMs  int64     `gorm:"column:duration_ms"`
+	Bitrate     int       `gorm:"column:bitrate"` // kbps
+	SampleRate  int       `gorm:"column:sample_rate"`
+	Channels    int       `gorm:"column:channels"`
+	Title       string    `gorm:"size:200;column:title"`
+	Artist      string    `gorm:"size:200;column:artist"`
+	Album       string    `gorm:"size:200;column:album"`
+	ReleaseDate string    `gorm:"size:20;column:release_date"` // 标签原值
+	Genre       string    `gorm:"size:100;column:genre"`
+	TrackNumber uint      `gorm:"column:track_number"`
+	DiscNumber  uint      `gorm:"column:disc_number"`
+	Lyric       string    `gorm:"type:text;column:lyric"`
+	CoverURL    string    `gorm:"size:500;column:cover_url"` // 内嵌封面, 已上传但未应用
+	CreateTime  time.Time `gorm:"type:datetime;not null;column:create_time"`
+}
+
+func (SongAudioMeta) TableName() string { return "tb_song_audio_meta" }
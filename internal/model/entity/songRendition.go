@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// 音质, 由低到高
+const (
+	QualityStandard = "standard" // 标准, 约 128kbps 有损
+	QualityHigh     = "high"     // 高品质, 约 320kbps 有损
+	QualityLossless = "lossless" // 无损
+)
+
+var qualityRanks = map[string]int{
+	QualityStandard: 1,
+	QualityHigh:     2,
+	QualityLossless: 3,
+}
+
+// QualityRank 音质高低, 未知音质为 0
+func QualityRank(quality string) int {
+	return qualityRanks[quality]
+}
+
+// SongRendition 歌曲的一个音质版本, 与 tb_song.audio_url(原始上传)并存
+type SongRendition struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement;column:id"`
+	SongID     uint64    `gorm:"not null;column:song_id"`
+	Quality    string    `gorm:"size:20;not null;column:quality"`
+	Codec      string    `gorm:"size:20;column:codec"`
+	Bitrate    int       `gorm:"column:bitrate"` // kbps
+	Size       int64     `gorm:"column:size"`    // 字节
+	ObjectKey  string    `gorm:"size:500;not null;column:object_key"`
+	CreateTime time.Time `gorm:"type:datetime;not null;column:create_time"`
+}
+
+func (SongRendition) TableName() string { return "tb_song_rendition" }
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// StorageObject 按内容寻址的存储对象: 同一上传策略下相同内容只存一份, 每次上传引用计数加一, 删除时减一, 归零才删除对象
+type StorageObject struct {
+	ObjectKey    string    `gorm:"primaryKey;size:255;column:object_key"`
+	Folder       string    `gorm:"size:32;not null;column:folder"` // 上传策略名, 即去重范围
+	Sha256       string    `gorm:"size:64;not null;column:sha256"` // 原始上传内容的 SHA-256
+	Size         int64     `gorm:"not null;column:size"`
+	Renditions   *string   `gorm:"size:255;column:renditions"` // 已生成的缩略图宽度, 逗号分隔; 空串表示没有, nil 表示未记录
+	RenditionExt string    `gorm:"size:16;not null;column:rendition_ext"`
+	RefCount     int       `gorm:"not null;column:ref_count"`
+	CreateTime   time.Time `gorm:"type:datetime;not null;column:create_time"`
+	UpdateTime   time.Time `gorm:"type:datetime;not null;column:update_time"`
+}
+
+func (StorageObject) TableName() string { return "tb_storage_object" }
@@ -2,7 +2,7 @@ package entity
 
 type Style struct {
 	ID   uint   `gorm:"primaryKey;autoIncrement;column:id"`
-	Name string `gorm:"size:100;not null;column:name"`
+	Name string `gorm:"size:50;uniqueIndex;not null;column:name"`
 }
 
 func (Style) TableName() string { return "tb_style" }
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// UploadSession 分片上传会话, 对应 MinIO 中一个未完成的分片上传
+type UploadSession struct {
+	ID         string    `gorm:"primaryKey;size:36;column:id"`
+	SongID     uint64    `gorm:"column:song_id"`
+	Filename   string    `gorm:"size:255;column:filename"`
+	Size       int64     `gorm:"column:size"`
+	ChunkSize  int64     `gorm:"column:chunk_size"`
+	ObjectKey  string    `gorm:"size:255;column:object_key"` // 临时对象, 完成后复制到 songs 目录
+	UploadID   string    `gorm:"size:255;column:upload_id"`  // MinIO 分片上传 id
+	CreateTime time.Time `gorm:"type:datetime;not null;column:create_time"`
+	UpdateTime time.Time `gorm:"type:datetime;not null;column:update_time"` // 最近一次上传分片的时间
+}
+
+func (UploadSession) TableName() string { return "tb_upload_session" }
+
+// TotalParts 按分片大小计算的分片总数
+func (s UploadSession) TotalParts() int {
+	return int((s.Size + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// PartSize 第 n 片应有的大小, 只有最后一片可以小于分片大小
+func (s UploadSession) PartSize(n int) int64 {
+	if n == s.TotalParts() {
+		return s.Size - int64(n-1)*s.ChunkSize
+	}
+	return s.ChunkSize
+}
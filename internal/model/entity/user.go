@@ -1,6 +1,9 @@
 package entity
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type UserStatus uint8
 
@@ -10,16 +13,17 @@ const (
 )
 
 type User struct {
-	UserId       uint64     `gorm:"primaryKey;autoIncrement;column:id"`
-	Username     string     `gorm:"size:16;unique;not null;column:username"`
-	Password     string     `gorm:"size:128;not null;column:password"` // 存哈希
-	Phone        *string    `gorm:"size:11;column:phone"`              // 可为空
-	Email        string     `gorm:"size:100;not null;column:email"`
-	UserAvatar   string     `gorm:"size:500;column:user_avatar"`
-	Introduction string     `gorm:"size:100;column:introduction"`
-	CreateTime   time.Time  `gorm:"type:datetime;not null;column:create_time"`
-	UpdateTime   time.Time  `gorm:"type:datetime;not null;column:update_time"`
-	Status       UserStatus `gorm:"type:tinyint;not null;column:status"` // 0-启用 1-禁用
+	UserId       uint64         `gorm:"primaryKey;autoIncrement;column:id"`
+	Username     string         `gorm:"size:16;unique;not null;column:username"`
+	Password     string         `gorm:"size:128;not null;column:password"` // 存哈希
+	Phone        *string        `gorm:"size:11;column:phone"`              // 可为空
+	Email        string         `gorm:"size:100;not null;column:email"`
+	UserAvatar   string         `gorm:"size:500;column:user_avatar"`
+	Introduction string         `gorm:"size:100;column:introduction"`
+	CreateTime   time.Time      `gorm:"type:datetime;not null;column:create_time"`
+	UpdateTime   time.Time      `gorm:"type:datetime;not null;column:update_time"`
+	Status       UserStatus     `gorm:"type:tinyint;not null;column:status"` // 0-启用 1-禁用
+	DeletedAt    gorm.DeletedAt `gorm:"index;column:deleted_at" json:"-"`    // 软删除, 进入回收站
 }
 
 func (User) TableName() string { return "tb_user" }
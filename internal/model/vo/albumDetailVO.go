@@ -0,0 +1,29 @@
+package vo
+
+import "time"
+
+type AlbumDetailVO struct {
+	AlbumID     uint64            `json:"albumId"`
+	Title       string            `json:"title"`
+	ArtistID    uint64            `json:"artistId"`
+	ArtistName  string            `json:"artistName"`
+	CoverURL    string            `json:"coverUrl"`
+	CoverSrcset map[string]string `json:"coverSrcset,omitempty" gorm:"-"`
+	ReleaseDate time.Time         `json:"releaseDate" time_format:"2006-01-02"`
+	Type        uint8             `json:"type"` // 0-LP 1-EP 2-单曲
+	Description string            `json:"description"`
+	LikeStatus  uint8             `json:"likeStatus"`      // 0-默认 1-喜欢
+	Tracks      []AlbumTrackVO    `json:"tracks" gorm:"-"` // 按碟号、曲目号排序
+}
+
+type AlbumTrackVO struct {
+	SongID      uint64            `json:"songId"`
+	SongName    string            `json:"songName"`
+	ArtistName  string            `json:"artistName"`
+	DiscNumber  uint              `json:"discNumber"`
+	TrackNumber uint              `json:"trackNumber"`
+	Duration    string            `json:"duration"`
+	CoverURL    string            `json:"coverUrl"`
+	CoverSrcset map[string]string `json:"coverSrcset,omitempty" gorm:"-"`
+	LikeStatus  uint8             `json:"likeStatus"` // 0-默认 1-喜欢
+}
@@ -0,0 +1,14 @@
+package vo
+
+import "time"
+
+type AlbumVO struct {
+	AlbumID     uint64            `json:"albumId"`
+	Title       string            `json:"title"`
+	ArtistID    uint64            `json:"artistId"`
+	ArtistName  string            `json:"artistName"`
+	CoverURL    string            `json:"coverUrl"`
+	CoverSrcset map[string]string `json:"coverSrcset,omitempty" gorm:"-"`
+	ReleaseDate time.Time         `json:"releaseDate" time_format:"2006-01-02"`
+	Type        uint8             `json:"type"` // 0-LP 1-EP 2-单曲
+}
@@ -1,7 +1,8 @@
 package vo
 
 type ArtistVO struct {
-	ArtistID   uint64 `json:"artistId"`
-	ArtistName string `json:"artistName"`
-	Avatar     string `json:"avatar"`
+	ArtistID     uint64            `json:"artistId"`
+	ArtistName   string            `json:"artistName"`
+	Avatar       string            `json:"avatar"`
+	AvatarSrcset map[string]string `json:"avatarSrcset,omitempty" gorm:"-"`
 }
@@ -0,0 +1,31 @@
+package vo
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditChangeVO 一个实体在请求前后变化的字段; 新增时只有 after, 删除时只有 before
+type AuditChangeVO struct {
+	ID     uint64         `json:"id,omitempty"`
+	Before map[string]any `json:"before,omitempty"`
+	After  map[string]any `json:"after,omitempty"`
+}
+
+type AuditLogVO struct {
+	ID         uint64          `json:"id"`
+	AdminID    uint64          `json:"adminId"`
+	AdminName  string          `json:"adminName"`
+	Action     string          `json:"action"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	EntityType string          `json:"entityType"`
+	EntityIDs  []uint64        `json:"entityIds"`
+	Diff       json.RawMessage `json:"diff"` // []AuditChangeVO
+	Status     int             `json:"status"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"userAgent"`
+	CreateTime time.Time       `json:"createTime"`
+}
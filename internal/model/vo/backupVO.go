@@ -0,0 +1,11 @@
+package vo
+
+import "time"
+
+// BackupVO 备份目录中的一个备份文件
+type BackupVO struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	Status     string    `json:"status"` // running 导出中, done 可下载, failed 导出中断
+	CreateTime time.Time `json:"createTime"`
+}
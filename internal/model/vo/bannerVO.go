@@ -1,6 +1,7 @@
 package vo
 
 type BannerVO struct {
-	BannerID  uint64 `json:"bannerId"`
-	BannerURL string `json:"bannerUrl"`
+	BannerID     uint64            `json:"bannerId"`
+	BannerURL    string            `json:"bannerUrl"`
+	BannerSrcset map[string]string `json:"bannerSrcset,omitempty" gorm:"-"`
 }
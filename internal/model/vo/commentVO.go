@@ -3,10 +3,11 @@ package vo
 import "time"
 
 type CommentVO struct {
-	CommentID  uint64    `json:"commentId"`
-	Username   string    `json:"username"`
-	UserAvatar string    `json:"userAvatar"`
-	Content    string    `json:"content"`
-	CreateTime time.Time `json:"createTime" time_format:"2006-01-02"` // 仅日期
-	LikeCount  uint64    `json:"likeCount"`
+	CommentID        uint64            `json:"commentId"`
+	Username         string            `json:"username"`
+	UserAvatar       string            `json:"userAvatar"`
+	UserAvatarSrcset map[string]string `json:"userAvatarSrcset,omitempty" gorm:"-"`
+	Content          string            `json:"content"`
+	CreateTime       time.Time         `json:"createTime" time_format:"2006-01-02"` // 仅日期
+	LikeCount        uint64            `json:"likeCount"`
 }
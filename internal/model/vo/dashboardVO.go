@@ -0,0 +1,42 @@
+package vo
+
+// DashboardTotalsVO 各类实体的当前总数, 不含回收站中的行
+type DashboardTotalsVO struct {
+	Users     int64 `json:"users"`
+	Artists   int64 `json:"artists"`
+	Songs     int64 `json:"songs"`
+	Albums    int64 `json:"albums"`
+	Playlists int64 `json:"playlists"`
+}
+
+// DashboardPointVO 时间序列中的一个点, Period 为该周期的第一天
+type DashboardPointVO struct {
+	Period string `json:"period"`
+	Value  int64  `json:"value"`
+}
+
+type DashboardStyleVO struct {
+	StyleID uint64 `json:"styleId"`
+	Name    string `json:"name"`
+	Plays   int64  `json:"plays"`
+}
+
+// DashboardStorageVO 一种上传策略下的存储用量
+type DashboardStorageVO struct {
+	Folder  string `json:"folder"`
+	Objects int64  `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+	Refs    int64  `json:"refs"`
+}
+
+type DashboardVO struct {
+	StartDate   string               `json:"startDate"`
+	EndDate     string               `json:"endDate"`
+	Granularity string               `json:"granularity"`
+	Totals      DashboardTotalsVO    `json:"totals"`
+	NewUsers    []DashboardPointVO   `json:"newUsers"`
+	Plays       []DashboardPointVO   `json:"plays"`
+	Favorites   []DashboardPointVO   `json:"favorites"`
+	TopStyles   []DashboardStyleVO   `json:"topStyles"`
+	Storage     []DashboardStorageVO `json:"storage"`
+}
@@ -0,0 +1,19 @@
+package vo
+
+// DeleteEffectVO 删除时一处关联的处理方式与影响行数
+type DeleteEffectVO struct {
+	Parent string `json:"parent"` // 被删除行所在的表
+	Table  string `json:"table"`  // 引用方的表
+	Column string `json:"column"` // 引用列
+	Action string `json:"action"` // cascade-随之删除 nullify-引用置空 block-阻止删除
+	Count  int64  `json:"count"`
+}
+
+// DeletePreviewVO 删除的预演结果, 不做任何修改
+type DeletePreviewVO struct {
+	Target  string           `json:"target"`
+	Count   int64            `json:"count"`   // 实际存在的待删除行数
+	Blocked bool             `json:"blocked"` // 存在 block 关联, 删除会被拒绝
+	Effects []DeleteEffectVO `json:"effects"`
+	Files   int              `json:"files"` // 提交后释放的文件数
+}
@@ -0,0 +1,6 @@
+package vo
+
+type FavoriteStatusVO struct {
+	ID         uint64 `json:"id"`
+	LikeStatus uint8  `json:"likeStatus"` // 0-默认 1-喜欢
+}
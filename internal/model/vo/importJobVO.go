@@ -0,0 +1,29 @@
+package vo
+
+import "time"
+
+// ImportRowVO 清单中一行的导入结果; 预演时 Success 表示校验通过
+type ImportRowVO struct {
+	Row        int      `json:"row"` // CSV 为文件行号, JSON 为数组下标加一
+	SongName   string   `json:"songName"`
+	Artist     string   `json:"artist"`
+	SongID     uint64   `json:"songId,omitempty"`
+	NewArtists []string `json:"newArtists,omitempty"` // 新建(预演时为将要新建)的歌手
+	Success    bool     `json:"success"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+type ImportJobVO struct {
+	ID         string        `json:"id"`
+	Status     string        `json:"status"`
+	DryRun     bool          `json:"dryRun"`
+	Manifest   string        `json:"manifest"`
+	Total      int           `json:"total"`
+	Processed  int           `json:"processed"`
+	Succeeded  int           `json:"succeeded"`
+	Failed     int           `json:"failed"`
+	Message    string        `json:"message,omitempty"`
+	Rows       []ImportRowVO `json:"rows,omitempty"` // 完成后才有
+	CreateTime time.Time     `json:"createTime"`
+	FinishTime *time.Time    `json:"finishTime"`
+}
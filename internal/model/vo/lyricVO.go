@@ -0,0 +1,26 @@
+package vo
+
+// LyricVO 解析后的歌词, 时间单位均为毫秒
+type LyricVO struct {
+	SongID uint64        `json:"songId"`
+	Synced bool          `json:"synced"` // false 表示旧数据无法按 LRC 解析, 仅逐行文本
+	Title  string        `json:"title,omitempty"`
+	Artist string        `json:"artist,omitempty"`
+	Album  string        `json:"album,omitempty"`
+	By     string        `json:"by,omitempty"`
+	Lines  []LyricLineVO `json:"lines"`
+}
+
+type LyricLineVO struct {
+	Time        int64         `json:"time"`
+	Duration    int64         `json:"duration"`
+	Text        string        `json:"text"`
+	Translation string        `json:"translation,omitempty"`
+	Words       []LyricWordVO `json:"words,omitempty"` // 逐字时间
+}
+
+type LyricWordVO struct {
+	Time     int64  `json:"time"`
+	Duration int64  `json:"duration"`
+	Text     string `json:"text"`
+}
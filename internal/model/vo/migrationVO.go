@@ -0,0 +1,9 @@
+package vo
+
+import "time"
+
+// MigrationVO 迁移脚本及其执行时间, 未执行时为 nil
+type MigrationVO struct {
+	Version   string     `json:"version"`
+	AppliedAt *time.Time `json:"appliedAt"`
+}
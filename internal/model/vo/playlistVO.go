@@ -1,7 +1,8 @@
 package vo
 
 type PlaylistVO struct {
-	PlaylistID uint64 `json:"playlistId"`
-	Title      string `json:"title"`
-	CoverURL   string `json:"coverUrl"`
+	PlaylistID  uint64            `json:"playlistId"`
+	Title       string            `json:"title"`
+	CoverURL    string            `json:"coverUrl"`
+	CoverSrcset map[string]string `json:"coverSrcset,omitempty" gorm:"-"`
 }
@@ -0,0 +1,12 @@
+package vo
+
+// ScanReportVO 一次曲库扫描的文件数统计, 预演时为将要执行的操作
+type ScanReportVO struct {
+	Files     int `json:"files"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Moved     int `json:"moved"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"` // 缺少歌手标签、歌曲已存在或在回收站中
+	Failed    int `json:"failed"`
+}
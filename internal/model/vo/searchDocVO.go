@@ -0,0 +1,11 @@
+package vo
+
+// SearchDocVO 构建搜索索引所需的数据行
+type SearchDocVO struct {
+	ID         uint64
+	Title      string
+	Subtitle   string
+	CoverURL   string
+	ArtistID   uint64
+	Popularity int64 // 被收藏次数
+}
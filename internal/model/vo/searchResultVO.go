@@ -0,0 +1,24 @@
+package vo
+
+type SearchHitVO struct {
+	Type        string            `json:"type"` // song/artist/album/playlist
+	ID          uint64            `json:"id"`
+	Title       string            `json:"title"`
+	Subtitle    string            `json:"subtitle"`
+	CoverURL    string            `json:"coverUrl"`
+	CoverSrcset map[string]string `json:"coverSrcset,omitempty" gorm:"-"`
+	Score       float64           `json:"score"`
+}
+
+type SearchResultVO struct {
+	Top       *SearchHitVO   `json:"top"`             // 最佳匹配, 无结果时为 null
+	Songs     *SearchGroupVO `json:"songs,omitempty"` // 按 type 过滤时仅返回对应分组
+	Artists   *SearchGroupVO `json:"artists,omitempty"`
+	Albums    *SearchGroupVO `json:"albums,omitempty"`
+	Playlists *SearchGroupVO `json:"playlists,omitempty"`
+}
+
+type SearchGroupVO struct {
+	Total int64         `json:"total"`
+	Items []SearchHitVO `json:"items"`
+}
@@ -0,0 +1,8 @@
+package vo
+
+type SearchSuggestVO struct {
+	Type     string `json:"type"`
+	ID       uint64 `json:"id"`
+	Text     string `json:"text"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
@@ -0,0 +1,9 @@
+package vo
+
+type SongArtistVO struct {
+	SongID     uint64 `json:"-"`
+	ArtistID   uint64 `json:"artistId"`
+	ArtistName string `json:"artistName"`
+	Role       uint8  `json:"role"` // 0-主要 1-合作 2-作曲 3-作词 4-制作
+	Sort       uint   `json:"sort"`
+}
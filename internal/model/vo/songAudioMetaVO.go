@@ -0,0 +1,39 @@
+package vo
+
+import "time"
+
+// SongAudioMetaVO 待审核的音频元数据, Current 为歌曲当前值, 便于对比
+type SongAudioMetaVO struct {
+	SongID      uint64             `json:"songId"`
+	AudioURL    string             `json:"audioUrl"`
+	Codec       string             `json:"codec"`
+	Duration    string             `json:"duration"` // 秒, 与歌曲时长格式一致
+	Bitrate     int                `json:"bitrate"`
+	SampleRate  int                `json:"sampleRate"`
+	Channels    int                `json:"channels"`
+	Title       string             `json:"title"`
+	Artist      string             `json:"artist"`
+	Album       string             `json:"album"`
+	ReleaseDate string             `json:"releaseDate"`
+	Genre       string             `json:"genre"`
+	TrackNumber uint               `json:"trackNumber"`
+	DiscNumber  uint               `json:"discNumber"`
+	Lyric       string             `json:"lyric"`
+	CoverURL    string             `json:"coverUrl"`
+	CreateTime  time.Time          `json:"createTime"`
+	Current     SongAudioCurrentVO `json:"current"`
+}
+
+type SongAudioCurrentVO struct {
+	SongName    string    `json:"songName"`
+	Album       string    `json:"album"`
+	Duration    string    `json:"duration"`
+	Codec       string    `json:"codec"`
+	Bitrate     int       `json:"bitrate"`
+	SampleRate  int       `json:"sampleRate"`
+	ReleaseTime time.Time `json:"releaseTime" time_format:"2006-01-02"`
+	TrackNumber uint      `json:"trackNumber"`
+	DiscNumber  uint      `json:"discNumber"`
+	HasLyric    bool      `json:"hasLyric"`
+	CoverURL    string    `json:"coverUrl"`
+}
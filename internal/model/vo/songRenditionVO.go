@@ -0,0 +1,23 @@
+package vo
+
+import "time"
+
+type SongRenditionVO struct {
+	RenditionID uint64 `json:"renditionId"`
+	SongID      uint64 `json:"songId"`
+	Quality     string `json:"quality"`
+	Codec       string `json:"codec"`
+	Bitrate     int    `json:"bitrate"`
+	Size        int64  `json:"size"`
+	ObjectKey   string `json:"objectKey"`
+	AudioURL    string `json:"audioUrl"`
+}
+
+type SongStreamVO struct {
+	SongID    uint64    `json:"songId"`
+	Quality   string    `json:"quality"` // 实际返回的音质, 可能低于请求的音质
+	Codec     string    `json:"codec"`
+	Bitrate   int       `json:"bitrate"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
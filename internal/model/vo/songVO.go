@@ -3,13 +3,14 @@ package vo
 import "time"
 
 type SongVO struct {
-	SongID      uint64    `json:"songId"`
-	SongName    string    `json:"songName"`
-	ArtistName  string    `json:"artistName"`
-	Album       string    `json:"album"`
-	Duration    string    `json:"duration"`
-	CoverURL    string    `json:"coverUrl"`
-	AudioURL    string    `json:"audioUrl"`
-	LikeStatus  uint8     `json:"likeStatus"` // 0-默认 1-喜欢
-	ReleaseTime time.Time `json:"releaseTime" time_format:"2006-01-02"`
+	SongID      uint64            `json:"songId"`
+	SongName    string            `json:"songName"`
+	ArtistName  string            `json:"artistName"`
+	Artists     []SongArtistVO    `json:"artists" gorm:"-"` // 全部署名歌手
+	Album       string            `json:"album"`
+	Duration    string            `json:"duration"`
+	CoverURL    string            `json:"coverUrl"`
+	CoverSrcset map[string]string `json:"coverSrcset,omitempty" gorm:"-"`
+	LikeStatus  uint8             `json:"likeStatus"` // 0-默认 1-喜欢
+	ReleaseTime time.Time         `json:"releaseTime" time_format:"2006-01-02"`
 }
@@ -0,0 +1,66 @@
+package vo
+
+// ImageSrcset 带图片缩略图地址(键为宽度)的 VO, 返回图片地址与要填充的字段, 由 StorageService.FillSrcsets 统一填充
+type ImageSrcset interface {
+	SrcsetImage() (string, *map[string]string)
+}
+
+func (v *AlbumDetailVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *AlbumTrackVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *AlbumVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *ArtistDetailVO) SrcsetImage() (string, *map[string]string) {
+	return v.Avatar, &v.AvatarSrcset
+}
+
+func (v *ArtistVO) SrcsetImage() (string, *map[string]string) {
+	return v.Avatar, &v.AvatarSrcset
+}
+
+func (v *BannerVO) SrcsetImage() (string, *map[string]string) {
+	return v.BannerURL, &v.BannerSrcset
+}
+
+func (v *CommentVO) SrcsetImage() (string, *map[string]string) {
+	return v.UserAvatar, &v.UserAvatarSrcset
+}
+
+func (v *PlaylistDetailVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *PlaylistVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *SearchHitVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *SongAdminVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *SongDetailVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *SongVO) SrcsetImage() (string, *map[string]string) {
+	return v.CoverURL, &v.CoverSrcset
+}
+
+func (v *UserManagementVO) SrcsetImage() (string, *map[string]string) {
+	return v.UserAvatar, &v.UserAvatarSrcset
+}
+
+func (v *UserVO) SrcsetImage() (string, *map[string]string) {
+	return v.UserAvatar, &v.UserAvatarSrcset
+}
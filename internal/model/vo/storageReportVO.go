@@ -0,0 +1,34 @@
+package vo
+
+import "time"
+
+// StorageRefVO 数据库中对存储对象的一处引用
+type StorageRefVO struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	ID     uint64 `json:"id"`
+	URL    string `json:"url,omitempty"`
+	Key    string `json:"key"`
+}
+
+type StorageOrphanVO struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	Deleted      bool      `json:"deleted"`
+}
+
+// StorageReportVO 一次对账的结果: 孤儿对象(存储中有、数据库未引用)与悬空引用(数据库引用了、存储中没有)
+type StorageReportVO struct {
+	Objects       int               `json:"objects"`
+	References    int               `json:"references"`
+	External      int               `json:"external"` // 不属于当前存储的地址, 不参与对账
+	Orphans       []StorageOrphanVO `json:"orphans"`
+	OrphanBytes   int64             `json:"orphanBytes"`
+	Deleted       int               `json:"deleted"`
+	DeletedBytes  int64             `json:"deletedBytes"`
+	Dangling      []StorageRefVO    `json:"dangling"`
+	RefCountFixed int               `json:"refCountFixed"`
+	StartTime     time.Time         `json:"startTime"`
+	Duration      string            `json:"duration"`
+}
@@ -0,0 +1,7 @@
+package vo
+
+type StyleVO struct {
+	StyleID   uint64 `json:"styleId"`
+	Name      string `json:"name"`
+	SongCount int64  `json:"songCount"`
+}
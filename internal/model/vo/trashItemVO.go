@@ -0,0 +1,13 @@
+package vo
+
+import "time"
+
+// TrashItemVO 回收站中的一行
+type TrashItemVO struct {
+	Type      string     `json:"type"`
+	ID        uint64     `json:"id"`
+	Title     string     `json:"title"`
+	CoverURL  string     `json:"coverUrl"`
+	DeletedAt time.Time  `json:"deletedAt"`
+	PurgeAt   *time.Time `json:"purgeAt"` // 预计被自动清除的时间, 未开启自动清除时为空
+}
@@ -0,0 +1,6 @@
+package vo
+
+type TrendingQueryVO struct {
+	Query string `json:"query"`
+	Count int64  `json:"count"`
+}
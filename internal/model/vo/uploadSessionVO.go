@@ -0,0 +1,17 @@
+package vo
+
+type UploadSessionVO struct {
+	UploadID      string         `json:"uploadId"`
+	SongID        uint64         `json:"songId"`
+	Filename      string         `json:"filename"`
+	Size          int64          `json:"size"`
+	ChunkSize     int64          `json:"chunkSize"`
+	TotalParts    int            `json:"totalParts"`
+	UploadedParts []UploadPartVO `json:"uploadedParts"` // 已上传的分片, 断点续传时只需补传缺失的分片
+}
+
+type UploadPartVO struct {
+	PartNumber int    `json:"partNumber"`
+	Size       int64  `json:"size"`
+	ETag       string `json:"etag"`
+}
@@ -0,0 +1,114 @@
+package audiometa
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 单个嵌入图片或标签块的读取上限, 防止畸形文件撑爆内存
+const maxBlockSize = 16 << 20
+
+var (
+	ErrUnsupported = errors.New("audiometa: unsupported format")
+	ErrMalformed   = errors.New("audiometa: malformed file")
+)
+
+type Picture struct {
+	MIME string
+	Data []byte
+}
+
+// Metadata 从音频文件中提取的技术参数与标签, 未识别的字段保持零值
+type Metadata struct {
+	Codec      string // mp3, flac, vorbis, opus, aac, alac
+	Duration   time.Duration
+	Bitrate    int // kbps
+	SampleRate int // Hz
+	Channels   int
+
+	Title       string
+	Artist      string
+	Album       string
+	Date        string // 原样保留, 常见为 yyyy 或 yyyy-mm-dd
+	Genre       string
+	TrackNumber int
+	TrackTotal  int
+	DiscNumber  int
+	Lyrics      string
+	Cover       *Picture
+}
+
+// Read 按文件头识别 MP3、FLAC、OGG(Vorbis/Opus)、MP4/M4A 并解析
+func Read(r io.ReaderAt, size int64) (*Metadata, error) {
+	head := make([]byte, 12)
+	if n, _ := r.ReadAt(head, 0); n < len(head) {
+		return nil, ErrUnsupported
+	}
+	m := &Metadata{}
+	var err error
+	switch {
+	case bytes.HasPrefix(head, []byte("ID3")) || isFrameSync(head):
+		err = readMP3(r, size, m)
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		err = readFLAC(r, size, m)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		err = readOgg(r, size, m)
+	case string(head[4:8]) == "ftyp":
+		err = readMP4(r, size, m)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.Title = strings.TrimSpace(m.Title)
+	m.Artist = strings.TrimSpace(m.Artist)
+	m.Album = strings.TrimSpace(m.Album)
+	m.Date = strings.TrimSpace(m.Date)
+	m.Genre = strings.TrimSpace(m.Genre)
+	m.Lyrics = strings.TrimSpace(m.Lyrics)
+	return m, nil
+}
+
+// Year 从 Date 中取年份, 无法识别时返回 0
+func (m *Metadata) Year() int {
+	if len(m.Date) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(m.Date[:4])
+	if err != nil {
+		return 0
+	}
+	return year
+}
+
+// bitrateFromSize 没有头信息时以平均码率估算
+func bitrateFromSize(audioBytes int64, d time.Duration) int {
+	if d <= 0 || audioBytes <= 0 {
+		return 0
+	}
+	return int(float64(audioBytes*8) / d.Seconds() / 1000)
+}
+
+// parsePair 解析 "3/12" 形式的曲目号
+func parsePair(s string) (int, int) {
+	a, b, _ := strings.Cut(strings.TrimSpace(s), "/")
+	n, _ := strconv.Atoi(strings.TrimSpace(a))
+	total, _ := strconv.Atoi(strings.TrimSpace(b))
+	return n, total
+}
+
+func readAt(r io.ReaderAt, off int64, n int) ([]byte, error) {
+	if n < 0 || n > maxBlockSize {
+		return nil, ErrMalformed
+	}
+	buf := make([]byte, n)
+	if read, _ := r.ReadAt(buf, off); read < n {
+		return nil, ErrMalformed
+	}
+	return buf, nil
+}
@@ -0,0 +1,352 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+var (
+	testJPEG = []byte("\xff\xd8\xff\xe0jpeg-data")
+	testPNG  = []byte("\x89PNG\r\n\x1a\npng-data")
+)
+
+func be16(v int) []byte { return binary.BigEndian.AppendUint16(nil, uint16(v)) }
+func be32(v int) []byte { return binary.BigEndian.AppendUint32(nil, uint32(v)) }
+func le32(v int) []byte { return binary.LittleEndian.AppendUint32(nil, uint32(v)) }
+
+func join(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+// id3Frame 构造 ID3v2.3/2.4 帧, 2.4 的帧长度为 syncsafe
+func id3Frame(version byte, id string, data []byte) []byte {
+	size := be32(len(data))
+	if version == 4 {
+		size = syncsafeBytes(len(data))
+	}
+	return join([]byte(id), size, []byte{0, 0}, data)
+}
+
+func id3Tag(version byte, frames ...[]byte) []byte {
+	body := join(frames...)
+	return join([]byte("ID3"), []byte{version, 0, 0}, syncsafeBytes(len(body)), body)
+}
+
+func utf16Text(s string) []byte {
+	b := []byte{1, 0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+// mp3Frames MPEG-1 Layer III 128kbps 44.1kHz 立体声, 每帧 417 字节
+func mp3Frames(n int) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return bytes.Repeat(frame, n)
+}
+
+func vorbisComment(entries ...string) []byte {
+	b := join(le32(len("test")), []byte("test"), le32(len(entries)))
+	for _, e := range entries {
+		b = join(b, le32(len(e)), []byte(e))
+	}
+	return b
+}
+
+func flacPictureBlock(picType int, mime string, data []byte) []byte {
+	return join(be32(picType), be32(len(mime)), []byte(mime), be32(0),
+		be32(1), be32(1), be32(24), be32(0), be32(len(data)), data)
+}
+
+func flacBlock(blockType byte, last bool, data []byte) []byte {
+	if last {
+		blockType |= 0x80
+	}
+	return join([]byte{blockType, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))}, data)
+}
+
+func oggPageBytes(serial int, granule int64, packets ...[]byte) []byte {
+	var segments, payload []byte
+	for _, p := range packets {
+		n := len(p)
+		for ; n >= 255; n -= 255 {
+			segments = append(segments, 255)
+		}
+		segments = append(segments, byte(n))
+		payload = append(payload, p...)
+	}
+	return join([]byte("OggS"), []byte{0, 0}, binary.LittleEndian.AppendUint64(nil, uint64(granule)),
+		le32(serial), le32(0), le32(0), []byte{byte(len(segments))}, segments, payload)
+}
+
+func mp4Atom(typ string, children ...[]byte) []byte {
+	body := join(children...)
+	return join(be32(8+len(body)), []byte(typ), body)
+}
+
+func mp4Item(typ string, kind int, value []byte) []byte {
+	return mp4Atom(typ, mp4Atom("data", be32(kind), be32(0), value))
+}
+
+func mp3Fixture() ([]byte, Metadata) {
+	file := join(
+		id3Tag(3,
+			id3Frame(3, "TIT2", utf16Text("晴天")),
+			id3Frame(3, "TPE1", append([]byte{0}, "Jay Chou"...)),
+			id3Frame(3, "TALB", append([]byte{3}, "叶惠美\x00"...)),
+			id3Frame(3, "TYER", append([]byte{0}, "2003"...)),
+			id3Frame(3, "TCON", append([]byte{0}, "(13)Pop"...)),
+			id3Frame(3, "TRCK", append([]byte{0}, "3/11"...)),
+			id3Frame(3, "TPOS", append([]byte{0}, "1/1"...)),
+			id3Frame(3, "USLT", join([]byte{3}, []byte("chi"), []byte("\x00"), []byte("[00:01.00]故事的小黄花"))),
+			id3Frame(3, "APIC", join([]byte{0}, []byte("image/jpeg\x00"), []byte{4}, []byte("back\x00"), testPNG)),
+			id3Frame(3, "APIC", join([]byte{0}, []byte("image/jpeg\x00"), []byte{3}, []byte("\x00"), testJPEG)),
+		),
+		mp3Frames(10),
+	)
+	return file, Metadata{
+		Codec:       "mp3",
+		Duration:    260625 * time.Microsecond, // 4170 字节 / 128kbps
+		Bitrate:     128,
+		SampleRate:  44100,
+		Channels:    2,
+		Title:       "晴天",
+		Artist:      "Jay Chou",
+		Album:       "叶惠美",
+		Date:        "2003",
+		Genre:       "Pop",
+		TrackNumber: 3,
+		TrackTotal:  11,
+		DiscNumber:  1,
+		Lyrics:      "[00:01.00]故事的小黄花",
+		Cover:       &Picture{MIME: "image/jpeg", Data: testJPEG},
+	}
+}
+
+func mp3V24Fixture() ([]byte, Metadata) {
+	// 第一帧为 Xing 头: 100 帧, 52000 字节
+	xing := mp3Frames(1)
+	copy(xing[4+32:], join([]byte("Xing"), be32(3), be32(100), be32(52000)))
+	file := join(
+		id3Tag(4,
+			id3Frame(4, "TIT2", append([]byte{3}, "Title"...)),
+			id3Frame(4, "TDRC", append([]byte{3}, "2020-05-01"...)),
+		),
+		xing,
+		mp3Frames(3),
+	)
+	return file, Metadata{
+		Codec:      "mp3",
+		Duration:   2612 * time.Millisecond, // 100 * 1152 / 44100
+		Bitrate:    159,
+		SampleRate: 44100,
+		Channels:   2,
+		Title:      "Title",
+		Date:       "2020-05-01",
+	}
+}
+
+func mp3V1Fixture() ([]byte, Metadata) {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:], "Old Song")
+	copy(tag[33:], "Old Artist")
+	copy(tag[93:], "1999")
+	tag[126] = 7
+	return join(mp3Frames(4), tag), Metadata{
+		Codec:       "mp3",
+		Duration:    104250 * time.Microsecond,
+		Bitrate:     128,
+		SampleRate:  44100,
+		Channels:    2,
+		Title:       "Old Song",
+		Artist:      "Old Artist",
+		Date:        "1999",
+		TrackNumber: 7,
+	}
+}
+
+func flacFixture() ([]byte, Metadata) {
+	streamInfo := make([]byte, 34)
+	// 44100Hz, 2 声道, 441000 个采样
+	copy(streamInfo[10:], []byte{0x0A, 0xC4, 0x42, 0xF0})
+	copy(streamInfo[14:], be32(441000))
+	file := join(
+		[]byte("fLaC"),
+		flacBlock(flacStreamInfo, false, streamInfo),
+		flacBlock(flacVorbisComment, false, vorbisComment(
+			"TITLE=稻香", "ARTIST=周杰伦", "ARTIST=Other", "ALBUM=魔杰座", "DATE=2008",
+			"GENRE=Pop", "TRACKNUMBER=1", "TRACKTOTAL=11", "DISCNUMBER=1/1", "LYRICS=对这个世界如果你有太多的抱怨",
+		)),
+		flacBlock(flacPicture, true, flacPictureBlock(3, "image/PNG", testPNG)),
+		make([]byte, 125000),
+	)
+	return file, Metadata{
+		Codec:       "flac",
+		Duration:    10 * time.Second,
+		Bitrate:     100,
+		SampleRate:  44100,
+		Channels:    2,
+		Title:       "稻香",
+		Artist:      "周杰伦",
+		Album:       "魔杰座",
+		Date:        "2008",
+		Genre:       "Pop",
+		TrackNumber: 1,
+		TrackTotal:  11,
+		DiscNumber:  1,
+		Lyrics:      "对这个世界如果你有太多的抱怨",
+		Cover:       &Picture{MIME: "image/png", Data: testPNG},
+	}
+}
+
+func oggVorbisFixture() ([]byte, Metadata) {
+	id := join([]byte("\x01vorbis"), le32(0), []byte{2}, le32(44100), le32(0), le32(160000), le32(0), []byte{0xB8, 1})
+	// 注释包超过一个分段, 验证按分段表拼包
+	picture := base64.StdEncoding.EncodeToString(flacPictureBlock(3, "image/jpeg", bytes.Repeat(testJPEG, 40)))
+	comment := join([]byte("\x03vorbis"), vorbisComment("TITLE=Song", "ALBUM=Album", "METADATA_BLOCK_PICTURE="+picture), []byte{1})
+	file := join(
+		oggPageBytes(1, 0, id),
+		oggPageBytes(1, 0, comment),
+		oggPageBytes(1, 441000*3, make([]byte, 100)),
+	)
+	return file, Metadata{
+		Codec:      "vorbis",
+		Duration:   30 * time.Second,
+		Bitrate:    160,
+		SampleRate: 44100,
+		Channels:   2,
+		Title:      "Song",
+		Album:      "Album",
+		Cover:      &Picture{MIME: "image/jpeg", Data: bytes.Repeat(testJPEG, 40)},
+	}
+}
+
+func oggOpusFixture() ([]byte, Metadata) {
+	id := join([]byte("OpusHead"), []byte{1, 1}, []byte{0x38, 0x01}, le32(48000), []byte{0, 0, 0})
+	comment := join([]byte("OpusTags"), vorbisComment("title=Opus Song", "COVERART="+base64.StdEncoding.EncodeToString(testPNG), "COVERARTMIME=image/png"))
+	file := join(
+		oggPageBytes(7, 0, id, comment),
+		oggPageBytes(7, 48000*5+312, make([]byte, 62188)),
+	)
+	return file, Metadata{
+		Codec:      "opus",
+		Duration:   5 * time.Second,
+		Bitrate:    bitrateFromSize(int64(len(file)), 5*time.Second),
+		SampleRate: 48000,
+		Channels:   1,
+		Title:      "Opus Song",
+		Cover:      &Picture{MIME: "image/png", Data: testPNG},
+	}
+}
+
+func mp4Fixture() ([]byte, Metadata) {
+	mvhd := join(be32(0), be32(0), be32(0), be32(1000), be32(7000), make([]byte, 80))
+	sampleEntry := join(be32(36), []byte("mp4a"), make([]byte, 6), be16(1), make([]byte, 8), be16(2), be16(16), be16(0), be16(0), be32(44100<<16))
+	trak := mp4Atom("trak", mp4Atom("mdia",
+		mp4Atom("hdlr", be32(0), be32(0), []byte("soun"), make([]byte, 12)),
+		mp4Atom("minf", mp4Atom("stbl", mp4Atom("stsd", be32(0), be32(1), sampleEntry))),
+	))
+	ilst := mp4Atom("ilst",
+		mp4Item("\xa9nam", 1, []byte("七里香")),
+		mp4Item("aART", 1, []byte("Album Artist")),
+		mp4Item("\xa9alb", 1, []byte("七里香")),
+		mp4Item("\xa9day", 1, []byte("2004-08-03T00:00:00Z")),
+		mp4Item("\xa9gen", 1, []byte("Pop")),
+		mp4Item("trkn", 0, join(be16(0), be16(1), be16(10), be16(0))),
+		mp4Item("disk", 0, join(be16(0), be16(1), be16(1))),
+		mp4Item("covr", 14, testPNG),
+		mp4Item("covr", 13, testJPEG),
+	)
+	udta := mp4Atom("udta", mp4Atom("meta", be32(0), mp4Atom("hdlr", be32(0), be32(0), []byte("mdir"), make([]byte, 12)), ilst))
+	file := join(
+		mp4Atom("ftyp", []byte("M4A "), be32(0)),
+		mp4Atom("mdat", make([]byte, 87500)),
+		mp4Atom("moov", mp4Atom("mvhd", mvhd), trak, udta),
+	)
+	return file, Metadata{
+		Codec:       "aac",
+		Duration:    7 * time.Second,
+		Bitrate:     bitrateFromSize(int64(len(file)), 7*time.Second),
+		SampleRate:  44100,
+		Channels:    2,
+		Title:       "七里香",
+		Artist:      "Album Artist",
+		Album:       "七里香",
+		Date:        "2004-08-03T00:00:00Z",
+		Genre:       "Pop",
+		TrackNumber: 1,
+		TrackTotal:  10,
+		DiscNumber:  1,
+		Cover:       &Picture{MIME: "image/png", Data: testPNG},
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture func() ([]byte, Metadata)
+	}{
+		{"id3v2.3 utf-16 text and front cover", mp3Fixture},
+		{"id3v2.4 with xing vbr header", mp3V24Fixture},
+		{"id3v1 only", mp3V1Fixture},
+		{"flac", flacFixture},
+		{"ogg vorbis with multi-segment comment", oggVorbisFixture},
+		{"ogg opus", oggOpusFixture},
+		{"mp4", mp4Fixture},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, want := tt.fixture()
+			got, err := Read(bytes.NewReader(file), int64(len(file)))
+			if err != nil {
+				t.Fatalf("Read err: %v", err)
+			}
+			got.Duration = got.Duration.Round(time.Millisecond)
+			want.Duration = want.Duration.Round(time.Millisecond)
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("got  %+v\nwant %+v", *got, want)
+			}
+		})
+	}
+}
+
+func TestReadError(t *testing.T) {
+	tests := []struct {
+		name string
+		file []byte
+		want error
+	}{
+		{"too short", []byte("ID3"), ErrUnsupported},
+		{"unknown format", join([]byte("RIFF"), le32(4), []byte("WAVEfmt ")), ErrUnsupported},
+		{"id3 without audio frames", join(id3Tag(3, id3Frame(3, "TIT2", []byte{0, 'x'})), make([]byte, 64)), ErrMalformed},
+		{"truncated flac", join([]byte("fLaC"), []byte{0, 0, 0, 34}, make([]byte, 10)), ErrMalformed},
+		{"mp4 without moov", mp4Atom("ftyp", []byte("M4A "), be32(0)), ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(tt.file), int64(len(tt.file)))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestYear(t *testing.T) {
+	for date, want := range map[string]int{"2003": 2003, "2020-05-01": 2020, "03": 0, "n/a": 0} {
+		if got := (&Metadata{Date: date}).Year(); got != want {
+			t.Errorf("Year(%q) = %d, want %d", date, got, want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package audiometa
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+const (
+	flacStreamInfo    = 0
+	flacVorbisComment = 4
+	flacPicture       = 6
+)
+
+func readFLAC(r io.ReaderAt, size int64, m *Metadata) error {
+	m.Codec = "flac"
+	off := int64(4)
+	var totalSamples uint64
+	for {
+		header, err := readAt(r, off, 4)
+		if err != nil {
+			return err
+		}
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		off += 4
+		switch blockType {
+		case flacStreamInfo:
+			b, err := readAt(r, off, length)
+			if err != nil || len(b) < 18 {
+				return ErrMalformed
+			}
+			m.SampleRate = int(b[10])<<12 | int(b[11])<<4 | int(b[12])>>4
+			m.Channels = int((b[12]>>1)&0x07) + 1
+			totalSamples = uint64(b[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(b[14:18]))
+		case flacVorbisComment:
+			if b, err := readAt(r, off, length); err == nil {
+				parseVorbisComment(b, m)
+			}
+		case flacPicture:
+			if b, err := readAt(r, off, length); err == nil {
+				if pic, picType := parseFLACPicture(b); pic != nil && (m.Cover == nil || picType == 3) {
+					m.Cover = pic
+				}
+			}
+		}
+		off += int64(length)
+		if last || off >= size {
+			break
+		}
+	}
+	if m.SampleRate > 0 && totalSamples > 0 {
+		m.Duration = time.Duration(float64(totalSamples) / float64(m.SampleRate) * float64(time.Second))
+		m.Bitrate = bitrateFromSize(size-off, m.Duration)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// readID3v2 解析文件头部的 ID3v2.2/2.3/2.4 标签, 返回标签总长度(不存在时为 0)
+func readID3v2(r io.ReaderAt, m *Metadata) (int64, error) {
+	header, err := readAt(r, 0, 10)
+	if err != nil || !bytes.HasPrefix(header, []byte("ID3")) {
+		return 0, nil
+	}
+	version, flags := header[3], header[5]
+	size := int(syncsafe(header[6:10]))
+	total := int64(10 + size)
+	if flags&0x10 != 0 {
+		total += 10 // footer
+	}
+	if version < 2 || version > 4 {
+		return total, nil
+	}
+	tag, err := readAt(r, 10, size)
+	if err != nil {
+		return total, err
+	}
+	if version < 4 && flags&0x80 != 0 {
+		tag = unsync(tag)
+	}
+	if flags&0x40 != 0 && len(tag) >= 4 {
+		// 跳过扩展头
+		var ext int
+		if version == 3 {
+			ext = 4 + int(binary.BigEndian.Uint32(tag))
+		} else {
+			ext = int(syncsafe(tag[:4]))
+		}
+		if ext > len(tag) {
+			return total, nil
+		}
+		tag = tag[ext:]
+	}
+
+	idLen, headLen := 4, 10
+	if version == 2 {
+		idLen, headLen = 3, 6
+	}
+	var cover *Picture
+	for len(tag) >= headLen && tag[0] != 0 {
+		id := string(tag[:idLen])
+		var frameSize int
+		var frameFlags uint16
+		switch version {
+		case 2:
+			frameSize = int(tag[3])<<16 | int(tag[4])<<8 | int(tag[5])
+		case 3:
+			frameSize = int(binary.BigEndian.Uint32(tag[4:8]))
+			frameFlags = binary.BigEndian.Uint16(tag[8:10])
+		case 4:
+			frameSize = int(syncsafe(tag[4:8]))
+			frameFlags = binary.BigEndian.Uint16(tag[8:10])
+		}
+		if frameSize <= 0 || headLen+frameSize > len(tag) {
+			break
+		}
+		data := tag[headLen : headLen+frameSize]
+		tag = tag[headLen+frameSize:]
+
+		// 压缩、加密的帧不处理
+		if (version == 3 && frameFlags&0x00C0 != 0) || (version == 4 && frameFlags&0x000C != 0) {
+			continue
+		}
+		if version == 4 {
+			if frameFlags&0x0001 != 0 && len(data) >= 4 {
+				data = data[4:]
+			}
+			if frameFlags&0x0002 != 0 {
+				data = unsync(data)
+			}
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		switch id {
+		case "TIT2", "TT2":
+			m.Title = decodeText(data)
+		case "TPE1", "TP1":
+			m.Artist = decodeText(data)
+		case "TALB", "TAL":
+			m.Album = decodeText(data)
+		case "TDRC", "TYER", "TYE":
+			if m.Date == "" || id == "TDRC" {
+				m.Date = decodeText(data)
+			}
+		case "TCON", "TCO":
+			m.Genre = cleanGenre(decodeText(data))
+		case "TRCK", "TRK":
+			m.TrackNumber, m.TrackTotal = parsePair(decodeText(data))
+		case "TPOS", "TPA":
+			m.DiscNumber, _ = parsePair(decodeText(data))
+		case "TLEN", "TLE":
+			if ms, err := strconv.Atoi(decodeText(data)); err == nil && m.Duration == 0 {
+				m.Duration = time.Duration(ms) * time.Millisecond
+			}
+		case "USLT", "ULT":
+			if len(data) > 4 && m.Lyrics == "" {
+				enc := data[0]
+				_, text := splitTerminated(data[4:], enc)
+				m.Lyrics = decodeString(text, enc)
+			}
+		case "APIC", "PIC":
+			if pic, picType := parsePictureFrame(data, version == 2); pic != nil && (cover == nil || picType == 3) {
+				cover = pic
+			}
+		}
+	}
+	m.Cover = cover
+	return total, nil
+}
+
+// readID3v1 只补充 ID3v2 中缺失的字段
+func readID3v1(r io.ReaderAt, size int64, m *Metadata) bool {
+	if size < 128 {
+		return false
+	}
+	tag, err := readAt(r, size-128, 128)
+	if err != nil || !bytes.HasPrefix(tag, []byte("TAG")) {
+		return false
+	}
+	field := func(b []byte) string {
+		return strings.TrimSpace(decodeString(bytes.TrimRight(b, "\x00 "), 0))
+	}
+	if m.Title == "" {
+		m.Title = field(tag[3:33])
+	}
+	if m.Artist == "" {
+		m.Artist = field(tag[33:63])
+	}
+	if m.Album == "" {
+		m.Album = field(tag[63:93])
+	}
+	if m.Date == "" {
+		m.Date = field(tag[93:97])
+	}
+	// ID3v1.1: 注释第 29 字节为 0 时, 第 30 字节为曲目号
+	if m.TrackNumber == 0 && tag[125] == 0 && tag[126] != 0 {
+		m.TrackNumber = int(tag[126])
+	}
+	return true
+}
+
+func parsePictureFrame(data []byte, v22 bool) (*Picture, byte) {
+	enc := data[0]
+	rest := data[1:]
+	var mime string
+	if v22 {
+		if len(rest) < 3 {
+			return nil, 0
+		}
+		switch strings.ToUpper(string(rest[:3])) {
+		case "PNG":
+			mime = "image/png"
+		default:
+			mime = "image/jpeg"
+		}
+		rest = rest[3:]
+	} else {
+		i := bytes.IndexByte(rest, 0)
+		if i < 0 {
+			return nil, 0
+		}
+		mime = strings.ToLower(string(rest[:i]))
+		rest = rest[i+1:]
+		if !strings.Contains(mime, "/") {
+			mime = "image/" + strings.TrimPrefix(mime, "image/")
+		}
+	}
+	if len(rest) < 1 {
+		return nil, 0
+	}
+	picType := rest[0]
+	_, img := splitTerminated(rest[1:], enc)
+	if len(img) == 0 {
+		return nil, 0
+	}
+	return &Picture{MIME: mime, Data: bytes.Clone(img)}, picType
+}
+
+// splitTerminated 按编码对应的结束符切出前缀字符串
+func splitTerminated(b []byte, enc byte) ([]byte, []byte) {
+	if enc == 1 || enc == 2 {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return b[:i], b[i+2:]
+			}
+		}
+		return b, nil
+	}
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return b[:i], b[i+1:]
+	}
+	return b, nil
+}
+
+// decodeText 解码文本帧, 多值时取第一个
+func decodeText(data []byte) string {
+	first, _ := splitTerminated(data[1:], data[0])
+	return strings.TrimSpace(decodeString(first, data[0]))
+}
+
+// decodeString 0-ISO-8859-1 1-UTF-16(BOM) 2-UTF-16BE 3-UTF-8
+func decodeString(b []byte, enc byte) string {
+	switch enc {
+	case 1, 2:
+		bigEndian := enc == 2
+		if len(b) >= 2 {
+			switch {
+			case b[0] == 0xFF && b[1] == 0xFE:
+				bigEndian, b = false, b[2:]
+			case b[0] == 0xFE && b[1] == 0xFF:
+				bigEndian, b = true, b[2:]
+			}
+		}
+		u := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			if bigEndian {
+				u = append(u, binary.BigEndian.Uint16(b[i:]))
+			} else {
+				u = append(u, binary.LittleEndian.Uint16(b[i:]))
+			}
+		}
+		return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+	case 3:
+		return strings.TrimRight(string(b), "\x00")
+	}
+	// 不少工具在 ISO-8859-1 字段里直接写 UTF-8
+	if utf8.Valid(b) {
+		return strings.TrimRight(string(b), "\x00")
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return strings.TrimRight(string(runes), "\x00")
+}
+
+var genreRefRe = regexp.MustCompile(`^\(\d+\)`)
+
+// cleanGenre 去掉 "(17)Rock" 中的 ID3v1 流派编号引用
+func cleanGenre(s string) string {
+	if stripped := genreRefRe.ReplaceAllString(s, ""); stripped != "" {
+		return stripped
+	}
+	return s
+}
+
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+// unsync 还原非同步化: 0xFF 0x00 -> 0xFF
+func unsync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xFF, 0x00}, []byte{0xFF})
+}
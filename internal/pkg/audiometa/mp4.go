@@ -0,0 +1,209 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+type atom struct {
+	typ  string
+	data []byte // 不含头部
+}
+
+// readAtoms 解析一层 atom
+func readAtoms(b []byte) []atom {
+	var atoms []atom
+	for len(b) >= 8 {
+		size := int64(binary.BigEndian.Uint32(b))
+		typ := string(b[4:8])
+		header := int64(8)
+		switch size {
+		case 0:
+			size = int64(len(b))
+		case 1:
+			if len(b) < 16 {
+				return atoms
+			}
+			size = int64(binary.BigEndian.Uint64(b[8:]))
+			header = 16
+		}
+		if size < header || size > int64(len(b)) {
+			return atoms
+		}
+		atoms = append(atoms, atom{typ: typ, data: b[header:size]})
+		b = b[size:]
+	}
+	return atoms
+}
+
+func findAtom(atoms []atom, typ string) []byte {
+	for _, a := range atoms {
+		if a.typ == typ {
+			return a.data
+		}
+	}
+	return nil
+}
+
+// findPath 沿路径逐层查找, 如 "mdia/minf/stbl"
+func findPath(b []byte, path ...string) []byte {
+	for _, typ := range path {
+		b = findAtom(readAtoms(b), typ)
+		if b == nil {
+			return nil
+		}
+	}
+	return b
+}
+
+func readMP4(r io.ReaderAt, size int64, m *Metadata) error {
+	// 顶层只读头部定位 moov, 避免把 mdat 读进内存
+	var moov []byte
+	for off := int64(0); off+8 <= size; {
+		header, err := readAt(r, off, 16)
+		if err != nil {
+			header, err = readAt(r, off, 8)
+			if err != nil {
+				return err
+			}
+		}
+		atomSize := int64(binary.BigEndian.Uint32(header))
+		headerLen := int64(8)
+		switch atomSize {
+		case 0:
+			atomSize = size - off
+		case 1:
+			if len(header) < 16 {
+				return ErrMalformed
+			}
+			atomSize = int64(binary.BigEndian.Uint64(header[8:]))
+			headerLen = 16
+		}
+		if atomSize < headerLen {
+			return ErrMalformed
+		}
+		if string(header[4:8]) == "moov" {
+			moov, err = readAt(r, off+headerLen, int(atomSize-headerLen))
+			if err != nil {
+				return err
+			}
+			break
+		}
+		off += atomSize
+	}
+	if moov == nil {
+		return ErrMalformed
+	}
+
+	atoms := readAtoms(moov)
+	if mvhd := findAtom(atoms, "mvhd"); len(mvhd) >= 20 {
+		var timescale, duration uint64
+		if mvhd[0] == 1 && len(mvhd) >= 32 {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[20:]))
+			duration = binary.BigEndian.Uint64(mvhd[24:])
+		} else {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[12:]))
+			duration = uint64(binary.BigEndian.Uint32(mvhd[16:]))
+		}
+		if timescale > 0 {
+			m.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+		}
+	}
+	for _, a := range atoms {
+		if a.typ != "trak" {
+			continue
+		}
+		if hdlr := findPath(a.data, "mdia", "hdlr"); len(hdlr) < 12 || string(hdlr[8:12]) != "soun" {
+			continue
+		}
+		readSampleEntry(findPath(a.data, "mdia", "minf", "stbl", "stsd"), m)
+		break
+	}
+	if udta := findAtom(atoms, "udta"); udta != nil {
+		if meta := findAtom(readAtoms(udta), "meta"); meta != nil {
+			// meta 通常是 full box, 前 4 字节为 version/flags; QuickTime 写法则没有
+			if len(meta) >= 8 && string(meta[4:8]) != "hdlr" {
+				meta = meta[4:]
+			}
+			if ilst := findAtom(readAtoms(meta), "ilst"); ilst != nil {
+				readIlst(ilst, m)
+			}
+		}
+	}
+	m.Bitrate = bitrateFromSize(size, m.Duration)
+	return nil
+}
+
+// readSampleEntry 从 stsd 的第一个音频样本描述中读取编码、声道与采样率
+func readSampleEntry(stsd []byte, m *Metadata) {
+	// version/flags(4) + entry count(4) + 条目
+	if len(stsd) < 8+36 {
+		return
+	}
+	entry := stsd[8:]
+	switch format := string(entry[4:8]); format {
+	case "mp4a":
+		m.Codec = "aac"
+	case "alac":
+		m.Codec = "alac"
+	case "fLaC":
+		m.Codec = "flac"
+	case "Opus":
+		m.Codec = "opus"
+	default:
+		m.Codec = format
+	}
+	// 头部 8 + reserved 6 + data ref 2 + version 2 + revision 2 + vendor 4
+	m.Channels = int(binary.BigEndian.Uint16(entry[24:]))
+	m.SampleRate = int(binary.BigEndian.Uint32(entry[32:]) >> 16)
+}
+
+func readIlst(ilst []byte, m *Metadata) {
+	for _, item := range readAtoms(ilst) {
+		data := findAtom(readAtoms(item.data), "data")
+		if len(data) < 8 {
+			continue
+		}
+		kind := binary.BigEndian.Uint32(data) & 0x00FFFFFF
+		value := data[8:]
+		text := string(value)
+		switch item.typ {
+		case "\xa9nam":
+			m.Title = text
+		case "\xa9ART":
+			m.Artist = text
+		case "aART":
+			if m.Artist == "" {
+				m.Artist = text
+			}
+		case "\xa9alb":
+			m.Album = text
+		case "\xa9day":
+			m.Date = text
+		case "\xa9gen":
+			m.Genre = text
+		case "\xa9lyr":
+			m.Lyrics = text
+		case "trkn":
+			if len(value) >= 6 {
+				m.TrackNumber = int(binary.BigEndian.Uint16(value[2:]))
+				m.TrackTotal = int(binary.BigEndian.Uint16(value[4:]))
+			}
+		case "disk":
+			if len(value) >= 4 {
+				m.DiscNumber = int(binary.BigEndian.Uint16(value[2:]))
+			}
+		case "covr":
+			if len(value) == 0 || m.Cover != nil {
+				continue
+			}
+			mime := "image/jpeg"
+			if kind == 14 || bytes.HasPrefix(value, []byte("\x89PNG")) {
+				mime = "image/png"
+			}
+			m.Cover = &Picture{MIME: mime, Data: bytes.Clone(value)}
+		}
+	}
+}
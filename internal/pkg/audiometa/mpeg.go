@@ -0,0 +1,156 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+var (
+	// 码率表(kbps), 下标为帧头中的码率索引 1..14
+	bitratesV1 = [3][14]int{
+		{32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448}, // Layer I
+		{32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},    // Layer II
+		{32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},     // Layer III
+	}
+	bitratesV2 = [3][14]int{
+		{32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	sampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+type frameHeader struct {
+	version    byte // 3-MPEG1 2-MPEG2 0-MPEG2.5
+	layer      int  // 1..3
+	bitrate    int  // kbps
+	sampleRate int
+	channels   int
+	length     int // 帧长度(字节)
+	samples    int // 每帧采样数
+}
+
+func isFrameSync(b []byte) bool {
+	return len(b) >= 2 && b[0] == 0xFF && b[1]&0xE0 == 0xE0
+}
+
+func parseFrameHeader(b []byte) (frameHeader, bool) {
+	var h frameHeader
+	if len(b) < 4 || !isFrameSync(b) {
+		return h, false
+	}
+	h.version = (b[1] >> 3) & 0x03
+	layerBits := (b[1] >> 1) & 0x03
+	brIndex := b[2] >> 4
+	srIndex := (b[2] >> 2) & 0x03
+	if h.version == 1 || layerBits == 0 || brIndex == 0 || brIndex == 15 || srIndex == 3 {
+		return h, false
+	}
+	h.layer = 4 - int(layerBits)
+	if h.version == 3 {
+		h.bitrate = bitratesV1[h.layer-1][brIndex-1]
+	} else {
+		h.bitrate = bitratesV2[h.layer-1][brIndex-1]
+	}
+	h.sampleRate = sampleRates[h.version][srIndex]
+	h.channels = 2
+	if b[3]>>6 == 3 {
+		h.channels = 1
+	}
+	padding := int((b[2] >> 1) & 0x01)
+	switch {
+	case h.layer == 1:
+		h.samples = 384
+		h.length = (12*h.bitrate*1000/h.sampleRate + padding) * 4
+	case h.layer == 3 && h.version != 3:
+		h.samples = 576
+		h.length = 72*h.bitrate*1000/h.sampleRate + padding
+	default:
+		h.samples = 1152
+		h.length = 144*h.bitrate*1000/h.sampleRate + padding
+	}
+	return h, h.length > 4
+}
+
+func readMP3(r io.ReaderAt, size int64, m *Metadata) error {
+	start, err := readID3v2(r, m)
+	if err != nil {
+		return err
+	}
+	end := size
+	if readID3v1(r, size, m) {
+		end -= 128
+	}
+
+	// 在标签之后的 64KB 内寻找第一个有效帧, 并要求紧随其后还有一帧, 以排除误匹配
+	window, _ := readAt(r, start, int(min(64<<10, max(end-start, 0))))
+	var h frameHeader
+	offset := -1
+	for i := 0; i+4 <= len(window); i++ {
+		fh, ok := parseFrameHeader(window[i:])
+		if !ok {
+			continue
+		}
+		if next := i + fh.length; next+4 <= len(window) {
+			if _, ok := parseFrameHeader(window[next:]); !ok {
+				continue
+			}
+		}
+		h, offset = fh, i
+		break
+	}
+	if offset < 0 {
+		return ErrMalformed
+	}
+	m.Codec = [...]string{"", "mp1", "mp2", "mp3"}[h.layer]
+	m.SampleRate = h.sampleRate
+	m.Channels = h.channels
+	audioStart := start + int64(offset)
+	audioBytes := end - audioStart
+
+	// VBR 文件的第一帧通常是 Xing/Info 或 VBRI 头, 记录了总帧数
+	frame := window[offset:min(offset+h.length, len(window))]
+	sideInfo := 32
+	switch {
+	case h.version == 3 && h.channels == 1:
+		sideInfo = 17
+	case h.version != 3 && h.channels == 2:
+		sideInfo = 17
+	case h.version != 3:
+		sideInfo = 9
+	}
+	var frames, vbrBytes int64
+	if x := 4 + sideInfo; x+12 <= len(frame) && (bytes.Equal(frame[x:x+4], []byte("Xing")) || bytes.Equal(frame[x:x+4], []byte("Info"))) {
+		flags := binary.BigEndian.Uint32(frame[x+4:])
+		p := x + 8
+		if flags&0x1 != 0 && p+4 <= len(frame) {
+			frames = int64(binary.BigEndian.Uint32(frame[p:]))
+			p += 4
+		}
+		if flags&0x2 != 0 && p+4 <= len(frame) {
+			vbrBytes = int64(binary.BigEndian.Uint32(frame[p:]))
+		}
+	} else if v := 4 + 32; v+18 <= len(frame) && bytes.Equal(frame[v:v+4], []byte("VBRI")) {
+		vbrBytes = int64(binary.BigEndian.Uint32(frame[v+10:]))
+		frames = int64(binary.BigEndian.Uint32(frame[v+14:]))
+	}
+
+	if frames > 0 {
+		m.Duration = time.Duration(float64(frames*int64(h.samples)) / float64(h.sampleRate) * float64(time.Second))
+		if vbrBytes == 0 {
+			vbrBytes = audioBytes
+		}
+		m.Bitrate = bitrateFromSize(vbrBytes, m.Duration)
+		return nil
+	}
+	// CBR: 按首帧码率估算时长, 比 TLEN 更可靠
+	m.Bitrate = h.bitrate
+	m.Duration = time.Duration(float64(audioBytes*8) / float64(h.bitrate*1000) * float64(time.Second))
+	return nil
+}
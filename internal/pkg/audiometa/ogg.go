@@ -0,0 +1,128 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// 头部包之外不再读取, 注释包可能因内嵌封面跨越多页
+const maxOggHeaderBytes = maxBlockSize + 1<<20
+
+type oggPage struct {
+	serial   uint32
+	segments []byte
+	payload  []byte
+	next     int64
+}
+
+func readOggPage(r io.ReaderAt, off int64) (*oggPage, error) {
+	header, err := readAt(r, off, 27)
+	if err != nil || !bytes.HasPrefix(header, []byte("OggS")) {
+		return nil, ErrMalformed
+	}
+	segCount := int(header[26])
+	segments, err := readAt(r, off+27, segCount)
+	if err != nil {
+		return nil, err
+	}
+	payloadLen := 0
+	for _, s := range segments {
+		payloadLen += int(s)
+	}
+	payload, err := readAt(r, off+27+int64(segCount), payloadLen)
+	if err != nil {
+		return nil, err
+	}
+	return &oggPage{
+		serial:   binary.LittleEndian.Uint32(header[14:18]),
+		segments: segments,
+		payload:  payload,
+		next:     off + 27 + int64(segCount) + int64(payloadLen),
+	}, nil
+}
+
+func readOgg(r io.ReaderAt, size int64, m *Metadata) error {
+	// 按分段表拼出第一个逻辑流的前两个包: 识别头与注释头
+	var packets [][]byte
+	var current []byte
+	var serial uint32
+	off := int64(0)
+	for len(packets) < 2 && off < min(size, maxOggHeaderBytes) {
+		page, err := readOggPage(r, off)
+		if err != nil {
+			return err
+		}
+		if off == 0 {
+			serial = page.serial
+		}
+		off = page.next
+		if page.serial != serial {
+			continue
+		}
+		p := page.payload
+		for _, seg := range page.segments {
+			current = append(current, p[:seg]...)
+			p = p[seg:]
+			if seg < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == 2 {
+					break
+				}
+			}
+		}
+	}
+	if len(packets) < 2 {
+		return ErrMalformed
+	}
+
+	var preSkip int64
+	var rate int
+	id, comment := packets[0], packets[1]
+	switch {
+	case len(id) >= 30 && bytes.HasPrefix(id, []byte("\x01vorbis")):
+		m.Codec = "vorbis"
+		m.Channels = int(id[11])
+		rate = int(binary.LittleEndian.Uint32(id[12:16]))
+		m.SampleRate = rate
+		if nominal := int32(binary.LittleEndian.Uint32(id[20:24])); nominal > 0 {
+			m.Bitrate = int(nominal / 1000)
+		}
+		if bytes.HasPrefix(comment, []byte("\x03vorbis")) {
+			parseVorbisComment(comment[7:], m)
+		}
+	case len(id) >= 19 && bytes.HasPrefix(id, []byte("OpusHead")):
+		m.Codec = "opus"
+		m.Channels = int(id[9])
+		preSkip = int64(binary.LittleEndian.Uint16(id[10:12]))
+		m.SampleRate = int(binary.LittleEndian.Uint32(id[12:16]))
+		rate = 48000 // Opus 的 granule 固定按 48kHz 计
+		if bytes.HasPrefix(comment, []byte("OpusTags")) {
+			parseVorbisComment(comment[8:], m)
+		}
+	default:
+		return ErrUnsupported
+	}
+
+	// 时长取最后一页的 granule position
+	tailLen := int(min(size, 64<<10))
+	tail, err := readAt(r, size-int64(tailLen), tailLen)
+	if err != nil {
+		return nil
+	}
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if i+18 > len(tail) || binary.LittleEndian.Uint32(tail[i+14:i+18]) != serial {
+			continue
+		}
+		if granule := int64(binary.LittleEndian.Uint64(tail[i+6 : i+14])); granule > preSkip && rate > 0 {
+			m.Duration = time.Duration(float64(granule-preSkip) / float64(rate) * float64(time.Second))
+			break
+		}
+	}
+	if m.Bitrate == 0 {
+		m.Bitrate = bitrateFromSize(size, m.Duration)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// parseVorbisComment 解析 FLAC 与 OGG 共用的 Vorbis comment 块(小端长度前缀)
+func parseVorbisComment(b []byte, m *Metadata) {
+	next := func() ([]byte, bool) {
+		if len(b) < 4 {
+			return nil, false
+		}
+		n := int(binary.LittleEndian.Uint32(b))
+		if n < 0 || 4+n > len(b) {
+			return nil, false
+		}
+		v := b[4 : 4+n]
+		b = b[4+n:]
+		return v, true
+	}
+	if _, ok := next(); !ok { // vendor
+		return
+	}
+	if len(b) < 4 {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+	var coverArt []byte
+	var coverMIME string
+	for i := 0; i < count; i++ {
+		entry, ok := next()
+		if !ok {
+			return
+		}
+		key, value, ok := strings.Cut(string(entry), "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			m.Title = value
+		case "ARTIST":
+			if m.Artist == "" {
+				m.Artist = value
+			}
+		case "ALBUM":
+			m.Album = value
+		case "DATE", "YEAR":
+			if m.Date == "" {
+				m.Date = value
+			}
+		case "GENRE":
+			m.Genre = value
+		case "TRACKNUMBER":
+			n, total := parsePair(value)
+			m.TrackNumber = n
+			if total > 0 {
+				m.TrackTotal = total
+			}
+		case "TRACKTOTAL", "TOTALTRACKS":
+			m.TrackTotal, _ = strconv.Atoi(strings.TrimSpace(value))
+		case "DISCNUMBER":
+			m.DiscNumber, _ = parsePair(value)
+		case "LYRICS", "UNSYNCEDLYRICS":
+			m.Lyrics = value
+		case "METADATA_BLOCK_PICTURE":
+			if raw, err := base64.StdEncoding.DecodeString(value); err == nil {
+				if pic, picType := parseFLACPicture(raw); pic != nil && (m.Cover == nil || picType == 3) {
+					m.Cover = pic
+				}
+			}
+		case "COVERART": // 旧式写法, 图片直接 base64
+			coverArt, _ = base64.StdEncoding.DecodeString(value)
+		case "COVERARTMIME":
+			coverMIME = value
+		}
+	}
+	if m.Cover == nil && len(coverArt) > 0 {
+		if coverMIME == "" {
+			coverMIME = "image/jpeg"
+		}
+		m.Cover = &Picture{MIME: coverMIME, Data: coverArt}
+	}
+}
+
+// parseFLACPicture 解析 FLAC PICTURE 块(大端), 返回图片与图片类型(3 为封面)
+func parseFLACPicture(b []byte) (*Picture, uint32) {
+	u32 := func() (uint32, bool) {
+		if len(b) < 4 {
+			return 0, false
+		}
+		v := binary.BigEndian.Uint32(b)
+		b = b[4:]
+		return v, true
+	}
+	bytesN := func() ([]byte, bool) {
+		n, ok := u32()
+		if !ok || int(n) > len(b) {
+			return nil, false
+		}
+		v := b[:n]
+		b = b[n:]
+		return v, true
+	}
+	picType, ok := u32()
+	if !ok {
+		return nil, 0
+	}
+	mime, ok := bytesN()
+	if !ok {
+		return nil, 0
+	}
+	if _, ok = bytesN(); !ok { // description
+		return nil, 0
+	}
+	for i := 0; i < 4; i++ { // width, height, depth, colors
+		if _, ok = u32(); !ok {
+			return nil, 0
+		}
+	}
+	data, ok := bytesN()
+	if !ok || len(data) == 0 {
+		return nil, 0
+	}
+	return &Picture{MIME: strings.ToLower(string(mime)), Data: bytes.Clone(data)}, picType
+}
@@ -29,3 +29,9 @@ func Init() {
 func Get() *gorm.DB {
 	return db
 }
+
+// Use 以已打开的连接代替配置中的数据库, 供测试使用
+func Use(conn *gorm.DB) {
+	once.Do(func() {})
+	db = conn
+}
@@ -0,0 +1,64 @@
+package db
+
+import "gorm.io/gorm"
+
+// UnitOfWork 一次事务内的工作单元: 仓储调用通过 Tx 在同一事务中执行,
+// 存储、缓存等无法随事务回滚的副作用登记为提交后执行或回滚时补偿
+type UnitOfWork struct {
+	tx          *gorm.DB
+	afterCommit []func()
+	onRollback  []func()
+}
+
+func (u *UnitOfWork) Tx() *gorm.DB {
+	return u.tx
+}
+
+// AfterCommit 登记提交成功后执行的动作, 按登记顺序执行
+func (u *UnitOfWork) AfterCommit(fn func()) {
+	u.afterCommit = append(u.afterCommit, fn)
+}
+
+// OnRollback 登记回滚时执行的补偿, 按登记的逆序执行
+func (u *UnitOfWork) OnRollback(fn func()) {
+	u.onRollback = append(u.onRollback, fn)
+}
+
+// Transaction 在一个事务中执行 fn; fn 返回错误或 panic 时回滚并执行补偿, 提交成功后再执行提交后动作
+func Transaction(fn func(uow *UnitOfWork) error) error {
+	uow := &UnitOfWork{}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		for i := len(uow.onRollback) - 1; i >= 0; i-- {
+			uow.onRollback[i]()
+		}
+	}()
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		uow.tx = tx
+		return fn(uow)
+	}); err != nil {
+		return err
+	}
+	committed = true
+	for _, f := range uow.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// Snapshot 在只读的一致性快照事务中执行 fn, 其中的读取看到的都是事务开始时的数据; 结束后回滚, 不执行补偿与提交后动作
+func Snapshot(fn func(uow *UnitOfWork) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ").Error; err != nil {
+			return err
+		}
+		if err := conn.Exec("START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("ROLLBACK")
+		return fn(&UnitOfWork{tx: conn})
+	})
+}
@@ -0,0 +1,303 @@
+package lrc
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxSize 歌词文本上限, 与 text 列容量一致
+const MaxSize = 65535
+
+// Word 逐字歌词中的一个片段, 时间单位均为毫秒
+type Word struct {
+	Time     int64
+	Duration int64
+	Text     string
+}
+
+type Line struct {
+	Time        int64
+	Duration    int64 // 到下一行的间隔, 最后一行为 0
+	Text        string
+	Translation string
+	Words       []Word // 仅增强格式(逐字)歌词有值
+}
+
+type Lyrics struct {
+	Title  string
+	Artist string
+	Album  string
+	By     string
+	Offset int64 // 已应用到各行时间上
+	Lines  []Line
+}
+
+// ParseError 带行号的格式错误, Line 为 0 表示整体错误
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("第 %d 行%s", e.Line, e.Msg)
+}
+
+var (
+	timeTagRe = regexp.MustCompile(`^(\d{1,3}):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+	metaTagRe = regexp.MustCompile(`^([A-Za-z#]+):(.*)$`)
+	wordTagRe = regexp.MustCompile(`<(\d{1,3}:\d{1,2}(?:[.:]\d{1,3})?)>`)
+)
+
+// Clean 去掉 BOM、统一换行并去除首尾空白, 作为入库前的规范形式
+func Clean(text string) string {
+	text = strings.TrimPrefix(text, "\uFEFF")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Parse 解析标准 LRC 与增强(逐字) LRC.
+// 同一时间戳出现两行时, 第二行视为上一行的翻译
+func Parse(text string) (*Lyrics, error) {
+	if len(text) > MaxSize {
+		return nil, &ParseError{Msg: "歌词超出长度限制"}
+	}
+	lyrics := &Lyrics{}
+	var lines []Line
+	for i, raw := range strings.Split(Clean(text), "\n") {
+		lineNo := i + 1
+		if raw == "" {
+			continue
+		}
+		if !strings.HasPrefix(raw, "[") {
+			return nil, &ParseError{Line: lineNo, Msg: "缺少时间标签"}
+		}
+		var times []int64
+		rest := raw
+		for strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, &ParseError{Line: lineNo, Msg: "标签未闭合"}
+			}
+			tag := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if timeTagRe.MatchString(tag) {
+				t, err := parseTime(tag)
+				if err != nil {
+					return nil, &ParseError{Line: lineNo, Msg: err.Error()}
+				}
+				times = append(times, t)
+				continue
+			}
+			m := metaTagRe.FindStringSubmatch(tag)
+			if m == nil {
+				return nil, &ParseError{Line: lineNo, Msg: fmt.Sprintf("无法识别的标签 [%s]", tag)}
+			}
+			if err := lyrics.setMeta(strings.ToLower(m[1]), strings.TrimSpace(m[2])); err != nil {
+				return nil, &ParseError{Line: lineNo, Msg: err.Error()}
+			}
+		}
+		if len(times) == 0 {
+			// 纯元数据行
+			if strings.TrimSpace(rest) != "" {
+				return nil, &ParseError{Line: lineNo, Msg: "缺少时间标签"}
+			}
+			continue
+		}
+		text, words, err := parseWords(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, &ParseError{Line: lineNo, Msg: err.Error()}
+		}
+		for _, t := range times {
+			line := Line{Time: t, Text: text}
+			if len(words) > 0 {
+				line.Words = shiftWords(words, t-times[0])
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, &ParseError{Msg: "没有带时间标签的歌词行"}
+	}
+
+	slices.SortStableFunc(lines, func(a, b Line) int {
+		return int(a.Time - b.Time)
+	})
+	// 相同时间戳的第二行作为翻译, 空行(间奏)不接收翻译
+	merged := lines[:0]
+	for _, line := range lines {
+		if n := len(merged); n > 0 && merged[n-1].Time == line.Time && merged[n-1].Text != "" && merged[n-1].Translation == "" && line.Text != "" {
+			merged[n-1].Translation = line.Text
+			continue
+		}
+		merged = append(merged, line)
+	}
+	lyrics.Lines = merged
+	lyrics.applyOffset()
+	lyrics.fillDurations()
+	return lyrics, nil
+}
+
+// MergeTranslation 按时间戳将单独上传的翻译歌词并入对应行
+func (l *Lyrics) MergeTranslation(tr *Lyrics) {
+	byTime := make(map[int64]string, len(tr.Lines))
+	for _, line := range tr.Lines {
+		if line.Text != "" {
+			byTime[line.Time] = line.Text
+		}
+	}
+	for i := range l.Lines {
+		if text, ok := byTime[l.Lines[i].Time]; ok {
+			l.Lines[i].Translation = text
+		}
+	}
+}
+
+func (l *Lyrics) setMeta(key, value string) error {
+	switch key {
+	case "ti":
+		l.Title = value
+	case "ar":
+		l.Artist = value
+	case "al":
+		l.Album = value
+	case "by":
+		l.By = value
+	case "offset":
+		offset, err := strconv.ParseInt(strings.TrimPrefix(value, "+"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("offset 不是整数毫秒: %s", value)
+		}
+		l.Offset = offset
+	}
+	// 其余标签(length、re、ve 等)忽略
+	return nil
+}
+
+// applyOffset 正的 offset 表示歌词提前显示
+func (l *Lyrics) applyOffset() {
+	if l.Offset == 0 {
+		return
+	}
+	shift := func(t int64) int64 { return max(t-l.Offset, 0) }
+	for i := range l.Lines {
+		l.Lines[i].Time = shift(l.Lines[i].Time)
+		for j := range l.Lines[i].Words {
+			l.Lines[i].Words[j].Time = shift(l.Lines[i].Words[j].Time)
+		}
+	}
+}
+
+func (l *Lyrics) fillDurations() {
+	for i := range l.Lines {
+		line := &l.Lines[i]
+		var end int64
+		if i+1 < len(l.Lines) {
+			end = l.Lines[i+1].Time
+			line.Duration = end - line.Time
+		}
+		for j := range line.Words {
+			w := &line.Words[j]
+			if w.Duration > 0 {
+				continue
+			}
+			if j+1 < len(line.Words) {
+				w.Duration = line.Words[j+1].Time - w.Time
+			} else if end > w.Time {
+				w.Duration = end - w.Time
+			}
+		}
+	}
+}
+
+// parseWords 解析 <mm:ss.xx> 逐字时间, 末尾的空白时间标签表示最后一个字的结束时间
+func parseWords(text string) (string, []Word, error) {
+	locs := wordTagRe.FindAllStringSubmatchIndex(text, -1)
+	if len(locs) == 0 {
+		return text, nil, nil
+	}
+	var words []Word
+	var b strings.Builder
+	if lead := text[:locs[0][0]]; strings.TrimSpace(lead) != "" {
+		return "", nil, fmt.Errorf("逐字歌词需以时间标签开头")
+	}
+	for i, loc := range locs {
+		t, err := parseTime(text[loc[2]:loc[3]])
+		if err != nil {
+			return "", nil, err
+		}
+		if n := len(words); n > 0 && t < words[n-1].Time {
+			return "", nil, fmt.Errorf("逐字时间倒序 <%s>", text[loc[2]:loc[3]])
+		}
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		segment := text[loc[1]:end]
+		if segment == "" {
+			// 结束标记, 只用于计算上一个字的时长
+			if n := len(words); n > 0 {
+				words[n-1].Duration = t - words[n-1].Time
+			}
+			continue
+		}
+		words = append(words, Word{Time: t, Text: segment})
+		b.WriteString(segment)
+	}
+	return strings.TrimSpace(b.String()), words, nil
+}
+
+// shiftWords 一行有多个时间标签时, 逐字时间按行首时间平移
+func shiftWords(words []Word, delta int64) []Word {
+	out := make([]Word, len(words))
+	for i, w := range words {
+		w.Time += delta
+		out[i] = w
+	}
+	return out
+}
+
+// parseTime 解析 mm:ss、mm:ss.x、mm:ss.xx、mm:ss.xxx, 返回毫秒
+func parseTime(tag string) (int64, error) {
+	m := timeTagRe.FindStringSubmatch(tag)
+	if m == nil {
+		return 0, fmt.Errorf("时间格式不正确 [%s]", tag)
+	}
+	minutes, _ := strconv.ParseInt(m[1], 10, 64)
+	seconds, _ := strconv.ParseInt(m[2], 10, 64)
+	if seconds >= 60 {
+		return 0, fmt.Errorf("秒数超出范围 [%s]", tag)
+	}
+	var millis int64
+	if frac := m[3]; frac != "" {
+		millis, _ = strconv.ParseInt(frac, 10, 64)
+		switch len(frac) {
+		case 1:
+			millis *= 100
+		case 2:
+			millis *= 10
+		}
+	}
+	return (minutes*60+seconds)*1000 + millis, nil
+}
+
+// PlainLines 无法按 LRC 解析的旧数据按纯文本逐行返回
+func PlainLines(text string) []Line {
+	var lines []Line
+	for _, raw := range strings.Split(Clean(text), "\n") {
+		if raw != "" {
+			lines = append(lines, Line{Text: raw})
+		}
+	}
+	return lines
+}
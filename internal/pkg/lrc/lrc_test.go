@@ -0,0 +1,158 @@
+package lrc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		want  []Line
+		check func(t *testing.T, l *Lyrics)
+	}{
+		{
+			name: "metadata and fractions",
+			text: "\uFEFF[ti:Title]\r\n[ar:Artist]\r\n[00:01.5]a\r\n[00:02.25]b\r\n[01:03.123]c",
+			want: []Line{
+				{Time: 1500, Duration: 750, Text: "a"},
+				{Time: 2250, Duration: 60873, Text: "b"},
+				{Time: 63123, Text: "c"},
+			},
+			check: func(t *testing.T, l *Lyrics) {
+				if l.Title != "Title" || l.Artist != "Artist" {
+					t.Errorf("meta = %q %q", l.Title, l.Artist)
+				}
+			},
+		},
+		{
+			name: "positive offset shows lines earlier and clamps at zero",
+			text: "[offset:+500]\n[00:01.00]a\n[00:00.20]b",
+			want: []Line{
+				{Time: 0, Duration: 500, Text: "b"},
+				{Time: 500, Text: "a"},
+			},
+		},
+		{
+			name: "negative offset",
+			text: "[offset:-250]\n[00:01.00]a",
+			want: []Line{{Time: 1250, Text: "a"}},
+		},
+		{
+			name: "multiple time tags on one line",
+			text: "[00:01.00][00:05.00]chorus\n[00:03.00]verse",
+			want: []Line{
+				{Time: 1000, Duration: 2000, Text: "chorus"},
+				{Time: 3000, Duration: 2000, Text: "verse"},
+				{Time: 5000, Text: "chorus"},
+			},
+		},
+		{
+			name: "enhanced word timing with end tag",
+			text: "[00:01.00]<00:01.00>he<00:01.50>llo<00:02.00>\n[00:03.00]next",
+			want: []Line{
+				{Time: 1000, Duration: 2000, Text: "hello", Words: []Word{
+					{Time: 1000, Duration: 500, Text: "he"},
+					{Time: 1500, Duration: 500, Text: "llo"},
+				}},
+				{Time: 3000, Text: "next"},
+			},
+		},
+		{
+			name: "enhanced last word lasts until next line",
+			text: "[00:01.00]<00:01.00>a<00:01.20>b\n[00:02.00]c",
+			want: []Line{
+				{Time: 1000, Duration: 1000, Text: "ab", Words: []Word{
+					{Time: 1000, Duration: 200, Text: "a"},
+					{Time: 1200, Duration: 800, Text: "b"},
+				}},
+				{Time: 2000, Text: "c"},
+			},
+		},
+		{
+			name: "enhanced words shift with repeated time tags",
+			text: "[00:01.00][00:11.00]<00:01.00>x<00:01.50>",
+			want: []Line{
+				{Time: 1000, Duration: 10000, Text: "x", Words: []Word{{Time: 1000, Duration: 500, Text: "x"}}},
+				{Time: 11000, Text: "x", Words: []Word{{Time: 11000, Duration: 500, Text: "x"}}},
+			},
+		},
+		{
+			name: "second line with same time is translation",
+			text: "[00:01.00]hello\n[00:01.00]你好\n[00:02.00]world",
+			want: []Line{
+				{Time: 1000, Duration: 1000, Text: "hello", Translation: "你好"},
+				{Time: 2000, Text: "world"},
+			},
+		},
+		{
+			name: "line after blank line is not its translation",
+			text: "[00:01.00]\n[00:01.00]after blank\n[00:02.00]x",
+			want: []Line{
+				{Time: 1000, Text: ""},
+				{Time: 1000, Duration: 1000, Text: "after blank"},
+				{Time: 2000, Text: "x"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse err: %v", err)
+			}
+			if !reflect.DeepEqual(l.Lines, tt.want) {
+				t.Errorf("lines = %+v\nwant    %+v", l.Lines, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, l)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		line int
+	}{
+		{"empty", "", 0},
+		{"metadata only", "[ti:x]", 0},
+		{"missing time tag", "[00:01.00]a\nb", 2},
+		{"unclosed tag", "[00:01.00", 1},
+		{"seconds out of range", "[00:60.00]a", 1},
+		{"unknown tag", "[00:01.00]a\n[what is this]b", 2},
+		{"bad offset", "[offset:abc]\n[00:01.00]a", 1},
+		{"reversed word timing", "[00:01.00]<00:02.00>a<00:01.00>b", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.text)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("err = %v, want *ParseError", err)
+			}
+			if parseErr.Line != tt.line {
+				t.Errorf("line = %d, want %d (%v)", parseErr.Line, tt.line, err)
+			}
+		})
+	}
+}
+
+func TestMergeTranslation(t *testing.T) {
+	l, err := Parse("[00:01.00]hello\n[00:02.00]world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := Parse("[00:01.00]你好\n[00:03.00]多余")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.MergeTranslation(tr)
+	if l.Lines[0].Translation != "你好" || l.Lines[1].Translation != "" {
+		t.Errorf("translations = %q %q", l.Lines[0].Translation, l.Lines[1].Translation)
+	}
+}
@@ -22,14 +22,20 @@ const (
 	Update    = "更新"
 	Delete    = "删除"
 	Reset     = "重置"
+	Restore   = "恢复"
 	Invalid   = "无效"
 )
 
 // 业务实体
 const (
-	Artist   = "歌手"
-	Song     = "歌曲"
-	Playlist = "歌单"
+	Artist      = "歌手"
+	Song        = "歌曲"
+	Playlist    = "歌单"
+	Album       = "专辑"
+	Style       = "风格"
+	Lyric       = "歌词"
+	Translation = "翻译歌词"
+	Rendition   = "音质版本"
 )
 
 // 结果状态
@@ -48,6 +54,8 @@ const (
 	InsertFailed   = "添加失败"
 	UpdateFailed   = "更新失败"
 	DeleteFailed   = "删除失败"
+	DeleteBlocked  = "存在关联数据，不允许删除"
+	RestoreBlocked = "所属数据仍在回收站中，请先恢复"
 )
 
 // 权限/会话
@@ -56,6 +64,7 @@ const (
 	NoPermission   = "您没有权限访问此资源"
 	NotLogin       = "未登录，请先登录"
 	SessionExpired = "会话过期，请重新登录"
+	QualityDenied  = "当前账号无法播放该音质"
 )
 
 // 密码相关
@@ -79,7 +88,33 @@ const (
 
 // 文件
 const (
-	FileUpload = "文件上传"
+	FileUpload         = "文件上传"
+	FileEmpty          = "文件为空"
+	FileTypeNotAllowed = "文件类型不支持"
+	FileTooLarge       = "文件大小超出限制"
+	ImageTooLarge      = "图片尺寸超出限制"
+	UnknownFolder      = "未知的上传目录"
+	ChunkSizeError     = "分片大小不正确"
+	ChecksumMismatch   = "分片校验失败"
+	UploadIncomplete   = "分片未全部上传"
+	NotLossless        = "文件不是无损格式"
+	ReconcileRunning   = "存储对账正在进行中"
+)
+
+// 批量导入
+const (
+	ManifestFormatError = "清单格式不正确"
+	ManifestTooManyRows = "清单行数超出限制"
+	MediaFormatError    = "媒体压缩包格式不正确"
+	MediaNotFound       = "压缩包中不存在该文件"
+	DuplicateRow        = "与清单中前面的行重复"
+	ImportQueueFull     = "导入任务过多，请稍后再试"
+	ImportInterrupted   = "服务重启，导入中断"
+)
+
+// 备份
+const (
+	BackupRunning = "备份正在进行中"
 )
 
 // 其他
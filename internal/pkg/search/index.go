@@ -0,0 +1,385 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type DocType string
+
+const (
+	TypeArtist   DocType = "artist"
+	TypeSong     DocType = "song"
+	TypeAlbum    DocType = "album"
+	TypePlaylist DocType = "playlist"
+)
+
+// DocTypes 同分时按此顺序决定最佳结果
+var DocTypes = []DocType{TypeArtist, TypeSong, TypeAlbum, TypePlaylist}
+
+// Doc 索引文档, 同时保存展示所需的少量字段
+type Doc struct {
+	Type      DocType
+	ID        uint64
+	Title     string
+	Subtitle  string   // 歌曲、专辑为歌手名
+	CoverURL  string   // 歌手为头像
+	ArtistIDs []uint64 // 关联歌手, 歌手变更时用于级联刷新
+	Boost     float64  // 热度加权, 建议 0~5
+}
+
+type Hit struct {
+	Doc
+	Score float64
+}
+
+type Page struct {
+	Total int
+	Hits  []Hit
+}
+
+type Result struct {
+	Top    *Hit
+	Groups map[DocType]Page
+}
+
+const (
+	titleWeight    = 1.0
+	subtitleWeight = 0.6
+	pinyinWeight   = 0.9 // 拼音字段相对原文的权重
+	initialsWeight = 0.8 // 首字母字段相对原文的权重
+	minScore       = 15
+	maxExpansions  = 200
+)
+
+type docKey struct {
+	t  DocType
+	id uint64
+}
+
+type field struct {
+	text   string
+	tokens []string
+	weight float64
+}
+
+type entry struct {
+	doc    Doc
+	fields []field
+	terms  []string
+}
+
+// Index 内存倒排索引, 支持前缀匹配与拼写容错, 并发安全
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[docKey]*entry
+	postings map[string]map[docKey]struct{}
+	terms    []string // 有序, 用于前缀查找
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[docKey]*entry),
+		postings: make(map[string]map[docKey]struct{}),
+	}
+}
+
+// Replace 以 docs 全量重建索引
+func (ix *Index) Replace(docs []Doc) {
+	fresh := NewIndex()
+	for _, doc := range docs {
+		fresh.terms = append(fresh.terms, fresh.add(doc)...)
+	}
+	// 新词统一排序一次, 逐个插入会使重建退化为平方复杂度
+	slices.Sort(fresh.terms)
+	ix.mu.Lock()
+	ix.docs, ix.postings, ix.terms = fresh.docs, fresh.postings, fresh.terms
+	ix.mu.Unlock()
+}
+
+func (ix *Index) Upsert(docs ...Doc) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, doc := range docs {
+		ix.remove(docKey{doc.Type, doc.ID})
+		for _, term := range ix.add(doc) {
+			i, _ := slices.BinarySearch(ix.terms, term)
+			ix.terms = slices.Insert(ix.terms, i, term)
+		}
+	}
+}
+
+func (ix *Index) Remove(t DocType, ids ...uint64) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, id := range ids {
+		ix.remove(docKey{t, id})
+	}
+}
+
+// RemoveByArtist 删除关联到指定歌手的文档(含歌手本身)
+func (ix *Index) RemoveByArtist(artistIds ...uint64) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for key, e := range ix.docs {
+		if key.t == TypeArtist && slices.Contains(artistIds, key.id) {
+			ix.remove(key)
+			continue
+		}
+		for _, id := range e.doc.ArtistIDs {
+			if slices.Contains(artistIds, id) {
+				ix.remove(key)
+				break
+			}
+		}
+	}
+}
+
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// add 写入文档与倒排表, 返回此前不存在的索引词, 由调用方加入 ix.terms
+func (ix *Index) add(doc Doc) []string {
+	key := docKey{doc.Type, doc.ID}
+	e := &entry{doc: doc}
+	e.fields = append(e.fields, buildFields(doc.Title, titleWeight)...)
+	e.fields = append(e.fields, buildFields(doc.Subtitle, subtitleWeight)...)
+	var terms []string
+	for _, f := range e.fields {
+		terms = append(terms, f.tokens...)
+	}
+	e.terms = dedupe(terms)
+	var added []string
+	for _, term := range e.terms {
+		posting, ok := ix.postings[term]
+		if !ok {
+			posting = make(map[docKey]struct{})
+			ix.postings[term] = posting
+			added = append(added, term)
+		}
+		posting[key] = struct{}{}
+	}
+	ix.docs[key] = e
+	return added
+}
+
+func (ix *Index) remove(key docKey) {
+	e, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+	for _, term := range e.terms {
+		posting := ix.postings[term]
+		delete(posting, key)
+		if len(posting) == 0 {
+			delete(ix.postings, term)
+			if i, found := slices.BinarySearch(ix.terms, term); found {
+				ix.terms = slices.Delete(ix.terms, i, i+1)
+			}
+		}
+	}
+	delete(ix.docs, key)
+}
+
+// buildFields 原文之外, 含汉字时追加全拼(分音节与连写)及首字母字段
+func buildFields(text string, weight float64) []field {
+	normalized := Normalize(text)
+	if normalized == "" {
+		return nil
+	}
+	fields := []field{{text: normalized, tokens: Tokenize(normalized), weight: weight}}
+	if !hasPinyin(normalized) {
+		return fields
+	}
+	full, initials := toPinyin(normalized)
+	// 连写全拼按词收录, 使 "zhoujielun" 能命中 "周杰伦"
+	var compact []string
+	for _, word := range strings.Fields(normalized) {
+		if hasPinyin(word) {
+			py, _ := toPinyin(word)
+			compact = append(compact, strings.ReplaceAll(py, " ", ""))
+		}
+	}
+	return append(fields,
+		field{text: full, tokens: dedupe(append(Tokenize(full), compact...)), weight: weight * pinyinWeight},
+		field{text: strings.ReplaceAll(full, " ", ""), weight: weight * pinyinWeight},
+		field{text: initials, tokens: Tokenize(initials), weight: weight * initialsWeight},
+	)
+}
+
+// query 查询的一种写法及其分词扩展
+type query struct {
+	text       string
+	expansions []map[string]float64
+}
+
+// Search 查询并按类型分组, 每组按 offset/limit 分页; types 为空时查询全部类型
+func (ix *Index) Search(text string, types []DocType, offset, limit int) Result {
+	res := Result{Groups: make(map[DocType]Page)}
+	if len(types) == 0 {
+		types = DocTypes
+	}
+	for _, t := range types {
+		res.Groups[t] = Page{Hits: []Hit{}}
+	}
+	q := Normalize(text)
+	if q == "" {
+		return res
+	}
+	texts := []string{q}
+	// 中文与拼音混输时, 另以整句转拼音后的写法查询
+	if hasPinyin(q) {
+		full, _ := toPinyin(q)
+		texts = append(texts, full)
+	}
+
+	ix.mu.RLock()
+	queries := make([]query, len(texts))
+	candidates := make(map[docKey]struct{})
+	for i, t := range texts {
+		queries[i].text = t
+		for _, token := range Tokenize(t) {
+			exp := ix.expand(token)
+			queries[i].expansions = append(queries[i].expansions, exp)
+			for term := range exp {
+				for key := range ix.postings[term] {
+					candidates[key] = struct{}{}
+				}
+			}
+		}
+	}
+	hits := make(map[DocType][]Hit)
+	for key := range candidates {
+		if !slices.Contains(types, key.t) {
+			continue
+		}
+		e := ix.docs[key]
+		if score := scoreEntry(e, queries); score >= minScore {
+			hits[key.t] = append(hits[key.t], Hit{Doc: e.doc, Score: score})
+		}
+	}
+	ix.mu.RUnlock()
+
+	for _, t := range types {
+		list := hits[t]
+		sortHits(list)
+		if len(list) > 0 && (res.Top == nil || list[0].Score > res.Top.Score) {
+			top := list[0]
+			res.Top = &top
+		}
+		page := Page{Total: len(list), Hits: []Hit{}}
+		if offset < len(list) {
+			page.Hits = list[offset:min(offset+limit, len(list))]
+		}
+		res.Groups[t] = page
+	}
+	return res
+}
+
+// expand 返回查询词可匹配的索引词及匹配质量: 精确 1, 前缀 0.8, 纠错 0.75/0.6
+func (ix *Index) expand(token string) map[string]float64 {
+	matches := make(map[string]float64)
+	if _, ok := ix.postings[token]; ok {
+		matches[token] = 1
+	}
+	tokenLen := utf8.RuneCountInString(token)
+	r, _ := utf8.DecodeRuneInString(token)
+	if tokenLen >= 2 || isCJK(r) {
+		for i := sort.SearchStrings(ix.terms, token); i < len(ix.terms) && len(matches) < maxExpansions; i++ {
+			term := ix.terms[i]
+			if !strings.HasPrefix(term, token) {
+				break
+			}
+			if term != token {
+				matches[term] = 0.8
+			}
+		}
+	}
+	limit := maxEdits(tokenLen)
+	if limit == 0 || isCJK(r) {
+		return matches
+	}
+	tokenRunes := []rune(token)
+	for _, term := range ix.terms {
+		if len(matches) >= maxExpansions {
+			break
+		}
+		if _, ok := matches[term]; ok {
+			continue
+		}
+		if d := utf8.RuneCountInString(term) - tokenLen; d > limit || -d > limit {
+			continue
+		}
+		if dist := editDistance(tokenRunes, []rune(term), limit); dist <= limit {
+			matches[term] = 0.85 - 0.15*float64(dist)
+		}
+	}
+	return matches
+}
+
+func scoreEntry(e *entry, queries []query) float64 {
+	best := 0.0
+	for _, q := range queries {
+		for _, f := range e.fields {
+			if s := scoreField(f, q.text, q.expansions) * f.weight; s > best {
+				best = s
+			}
+		}
+	}
+	if best == 0 {
+		return 0
+	}
+	return best + e.doc.Boost
+}
+
+func scoreField(f field, q string, expansions []map[string]float64) float64 {
+	var score float64
+	switch {
+	case f.text == q:
+		score = 100
+	case strings.HasPrefix(f.text, q):
+		score = 85
+	case strings.Contains(f.text, q):
+		score = 70
+	default:
+		matched, sum := 0, 0.0
+		for _, exp := range expansions {
+			quality := 0.0
+			for _, token := range f.tokens {
+				quality = max(quality, exp[token])
+			}
+			if quality > 0 {
+				matched++
+				sum += quality
+			}
+		}
+		if matched == 0 {
+			return 0
+		}
+		score = 60 * sum / float64(len(expansions))
+		if matched < len(expansions) {
+			score *= 0.5
+		}
+	}
+	// 匹配越紧凑越靠前
+	ratio := float64(utf8.RuneCountInString(q)) / float64(max(utf8.RuneCountInString(f.text), 1))
+	return score + 10*min(ratio, 1)
+}
+
+func sortHits(hits []Hit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if li, lj := len(hits[i].Title), len(hits[j].Title); li != lj {
+			return li < lj
+		}
+		return hits[i].ID < hits[j].ID
+	})
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+var testDocs = []Doc{
+	{Type: TypeArtist, ID: 1, Title: "周杰伦", Boost: 3},
+	{Type: TypeArtist, ID: 2, Title: "林俊杰"},
+	{Type: TypeArtist, ID: 3, Title: "Taylor Swift"},
+	{Type: TypeSong, ID: 10, Title: "晴天", Subtitle: "周杰伦", ArtistIDs: []uint64{1}},
+	{Type: TypeSong, ID: 11, Title: "Love Story", Subtitle: "Taylor Swift", ArtistIDs: []uint64{3}},
+	{Type: TypeSong, ID: 12, Title: "Love Yourself", Subtitle: "Justin Bieber"},
+	{Type: TypeSong, ID: 13, Title: "江南", Subtitle: "林俊杰", ArtistIDs: []uint64{2}},
+	{Type: TypeSong, ID: 14, Title: "Shake It Off", Subtitle: "Taylor Swift", ArtistIDs: []uint64{3}},
+	{Type: TypeAlbum, ID: 20, Title: "叶惠美", Subtitle: "周杰伦", ArtistIDs: []uint64{1}},
+	{Type: TypePlaylist, ID: 30, Title: "晴天的午后"},
+}
+
+func newTestIndex() *Index {
+	ix := NewIndex()
+	ix.Replace(testDocs)
+	return ix
+}
+
+func TestSearch(t *testing.T) {
+	ix := newTestIndex()
+	tests := []struct {
+		name    string
+		q       string
+		types   []DocType
+		wantTop docKey
+		// 各分组的 id 顺序, 只检查列出的分组
+		want map[DocType][]uint64
+	}{
+		{name: "exact title", q: "晴天", wantTop: docKey{TypeSong, 10},
+			want: map[DocType][]uint64{TypeSong: {10}, TypePlaylist: {30}}},
+		{name: "exact title ranks above prefix", q: "love story", wantTop: docKey{TypeSong, 11},
+			want: map[DocType][]uint64{TypeSong: {11, 12}}},
+		{name: "prefix", q: "tay", wantTop: docKey{TypeArtist, 3},
+			want: map[DocType][]uint64{TypeArtist: {3}, TypeSong: {11, 14}}},
+		{name: "subtitle matches artist's songs", q: "周杰伦", wantTop: docKey{TypeArtist, 1},
+			want: map[DocType][]uint64{TypeSong: {10}, TypeAlbum: {20}}},
+		{name: "typo tolerance", q: "tayolr swfit", wantTop: docKey{TypeArtist, 3}},
+		{name: "transposed letters", q: "lvoe", want: map[DocType][]uint64{TypeSong: {11, 12}}},
+		{name: "typo in short word is not corrected", q: "lvo", want: map[DocType][]uint64{TypeSong: {}}},
+		{name: "full pinyin", q: "zhoujielun", wantTop: docKey{TypeArtist, 1}},
+		{name: "syllable pinyin", q: "qing tian", wantTop: docKey{TypeSong, 10}},
+		{name: "initials", q: "ljj", wantTop: docKey{TypeArtist, 2}},
+		{name: "mixed hanzi and pinyin", q: "江nan", wantTop: docKey{TypeSong, 13}},
+		{name: "full width", q: "ＬＯＶＥ　Ｓｔｏｒｙ", wantTop: docKey{TypeSong, 11}},
+		{name: "traditional", q: "周杰倫", wantTop: docKey{TypeArtist, 1}},
+		{name: "type filter", q: "周杰伦", types: []DocType{TypeSong}, wantTop: docKey{TypeSong, 10},
+			want: map[DocType][]uint64{TypeSong: {10}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ix.Search(tt.q, tt.types, 0, 10)
+			if tt.wantTop != (docKey{}) {
+				if res.Top == nil {
+					t.Fatalf("top = nil, want %v", tt.wantTop)
+				}
+				if got := (docKey{res.Top.Type, res.Top.ID}); got != tt.wantTop {
+					t.Errorf("top = %v, want %v", got, tt.wantTop)
+				}
+			}
+			for typ, want := range tt.want {
+				var got []uint64
+				for _, hit := range res.Groups[typ].Hits {
+					got = append(got, hit.ID)
+				}
+				if !slices.Equal(got, want) {
+					t.Errorf("%s = %v, want %v", typ, got, want)
+				}
+			}
+			if len(tt.types) > 0 && len(res.Groups) != len(tt.types) {
+				t.Errorf("groups = %d, want %d", len(res.Groups), len(tt.types))
+			}
+		})
+	}
+}
+
+func TestSearchPaging(t *testing.T) {
+	ix := newTestIndex()
+	res := ix.Search("taylor swift", []DocType{TypeSong}, 1, 1)
+	page := res.Groups[TypeSong]
+	if page.Total != 2 || len(page.Hits) != 1 || page.Hits[0].ID != 14 {
+		t.Errorf("page = %+v", page)
+	}
+	if res := ix.Search("taylor swift", []DocType{TypeSong}, 5, 10); len(res.Groups[TypeSong].Hits) != 0 {
+		t.Errorf("hits past the end = %v", res.Groups[TypeSong].Hits)
+	}
+}
+
+func TestUpsertAndRemove(t *testing.T) {
+	ix := newTestIndex()
+	ix.Upsert(Doc{Type: TypeSong, ID: 10, Title: "稻香", Subtitle: "周杰伦", ArtistIDs: []uint64{1}})
+	if res := ix.Search("晴天", []DocType{TypeSong}, 0, 10); res.Groups[TypeSong].Total != 0 {
+		t.Errorf("old title still indexed: %+v", res.Groups[TypeSong])
+	}
+	if res := ix.Search("daoxiang", nil, 0, 10); res.Top == nil || res.Top.ID != 10 {
+		t.Errorf("new title not indexed: %+v", res.Top)
+	}
+
+	ix.RemoveByArtist(1)
+	res := ix.Search("周杰伦", nil, 0, 10)
+	for _, page := range res.Groups {
+		for _, hit := range page.Hits {
+			if hit.Type == TypeArtist && hit.ID == 1 || slices.Contains(hit.ArtistIDs, 1) {
+				t.Errorf("hit after RemoveByArtist: %+v", hit)
+			}
+		}
+	}
+	ix.Remove(TypeSong, 11, 12)
+	if got, want := ix.Len(), len(testDocs)-5; got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+}
+
+// 全量重建与逐个写入得到相同的有序词表
+func TestReplaceMatchesUpsert(t *testing.T) {
+	rebuilt := newTestIndex()
+	incremental := NewIndex()
+	for _, doc := range testDocs {
+		incremental.Upsert(doc)
+	}
+	if !slices.IsSorted(rebuilt.terms) {
+		t.Fatal("terms not sorted after Replace")
+	}
+	if !slices.Equal(rebuilt.terms, incremental.terms) {
+		t.Errorf("terms differ:\nreplace %v\nupsert  %v", rebuilt.terms, incremental.terms)
+	}
+	for _, doc := range testDocs {
+		incremental.Remove(doc.Type, doc.ID)
+	}
+	if len(incremental.terms) != 0 || len(incremental.postings) != 0 {
+		t.Errorf("terms left after removing all docs: %v", incremental.terms)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  Hello,   World! ", "hello world"},
+		{"ＡＢＣ１２３", "abc123"},
+		{"Beyoncé", "beyonce"},
+		{"Don't Stop", "dont stop"},
+		{"周杰倫《晴天》", "周杰伦 晴天"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("love 晴天了")
+	want := []string{"love", "晴", "晴天", "天", "天了", "了"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// Normalize 统一全半角、繁简与大小写并压缩空白, 作为索引与查询共用的规范化形式
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	// 全角字母数字转半角, 半角片假名转全角
+	for _, r := range width.Fold.String(s) {
+		r = foldRune(r)
+		if isElided(r) {
+			continue
+		}
+		if unicode.IsSpace(r) || isSeparator(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// foldRune 单字符折叠: 转小写, 去掉常见拉丁字母的变音符号, 繁体转简体
+func foldRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if f, ok := latinFold[r]; ok {
+		return f
+	}
+	if f, ok := t2sTable[r]; ok {
+		return f
+	}
+	return r
+}
+
+func isSeparator(r rune) bool {
+	switch r {
+	case '-', '_', '/', '\\', '|', '·', '•', '&', '+', ',', '.', '!', '?', ':', ';', '"', '(', ')', '[', ']', '{', '}', '<', '>',
+		'、', '。', '《', '》', '「', '」', '『', '』', '【', '】', '〈', '〉', '～':
+		return true
+	}
+	return false
+}
+
+// isElided 撇号直接省略, 使 "fool's" 与 "fools" 等价
+func isElided(r rune) bool {
+	return r == '\'' || r == '’' || r == '`'
+}
+
+// isCJK 中日韩文字按单字切分
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// Tokenize 对规范化后的文本切词: 字母数字按连续片段成词, 中日韩文字输出单字及相邻二元组
+func Tokenize(normalized string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		for i := range cjk {
+			tokens = append(tokens, string(cjk[i]))
+			if i+1 < len(cjk) {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range normalized {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return dedupe(tokens)
+}
+
+func dedupe(tokens []string) []string {
+	seen := make(map[string]struct{}, len(tokens))
+	out := tokens[:0]
+	for _, t := range tokens {
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		out = append(out, t)
+	}
+	return out
+}
+
+var latinFold = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'ç': 'c', 'č': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i',
+	'ñ': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'š': 's', 'ž': 'z',
+}
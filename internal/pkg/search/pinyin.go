@@ -0,0 +1,89 @@
+package search
+
+import (
+	_ "embed"
+	"strings"
+	"unicode/utf8"
+)
+
+// 数据文件由 scripts/gen_search_data.sh 生成
+var (
+	//go:embed data/pinyin.txt
+	pinyinData string
+	//go:embed data/t2s.txt
+	t2sData string
+)
+
+var (
+	pinyinTable map[rune]string // 汉字 -> 无声调拼音
+	t2sTable    map[rune]rune   // 繁体 -> 简体
+)
+
+func init() {
+	pinyinTable = make(map[rune]string, 21000)
+	for _, line := range strings.Split(pinyinData, "\n") {
+		syllable, chars, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		for _, r := range chars {
+			pinyinTable[r] = syllable
+		}
+	}
+	t2sTable = make(map[rune]rune, 3000)
+	for _, line := range strings.Split(t2sData, "\n") {
+		t, s, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		tr, _ := utf8.DecodeRuneInString(t)
+		sr, _ := utf8.DecodeRuneInString(s)
+		t2sTable[tr] = sr
+	}
+}
+
+// hasPinyin 文本中是否含有可转拼音的汉字
+func hasPinyin(normalized string) bool {
+	for _, r := range normalized {
+		if _, ok := pinyinTable[r]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// toPinyin 将规范化文本中的汉字转为拼音, 其余字符原样保留.
+// full 以空格分隔音节, 如 "周杰伦 jay" -> "zhou jie lun jay";
+// initials 为连续汉字的首字母缩写, 如 "zjl jay". 多音字取常用读音.
+func toPinyin(normalized string) (full, initials string) {
+	var fb, ib strings.Builder
+	prevHan := false
+	for _, r := range normalized {
+		py, ok := pinyinTable[r]
+		switch {
+		case ok:
+			if fb.Len() > 0 && !strings.HasSuffix(fb.String(), " ") {
+				fb.WriteByte(' ')
+			}
+			fb.WriteString(py)
+			if !prevHan && ib.Len() > 0 && !strings.HasSuffix(ib.String(), " ") {
+				ib.WriteByte(' ')
+			}
+			ib.WriteByte(py[0])
+			prevHan = true
+		case r == ' ':
+			fb.WriteByte(' ')
+			ib.WriteByte(' ')
+			prevHan = false
+		default:
+			if prevHan {
+				fb.WriteByte(' ')
+				ib.WriteByte(' ')
+			}
+			fb.WriteRune(r)
+			ib.WriteRune(r)
+			prevHan = false
+		}
+	}
+	return fb.String(), ib.String()
+}
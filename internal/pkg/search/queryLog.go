@@ -0,0 +1,38 @@
+package search
+
+import "sync"
+
+const maxPendingQueries = 10000
+
+// QueryLog 在内存中累计查询次数, 由调用方定期取出落库.
+// 只记录规范化后的查询词, 不关联用户
+type QueryLog struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewQueryLog() *QueryLog {
+	return &QueryLog{counts: make(map[string]int)}
+}
+
+func (l *QueryLog) Add(query string) {
+	q := Normalize(query)
+	if q == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// 两次落库之间词条过多时丢弃新词, 防止内存膨胀
+	if _, ok := l.counts[q]; ok || len(l.counts) < maxPendingQueries {
+		l.counts[q]++
+	}
+}
+
+// Drain 取出并清空已累计的次数
+func (l *QueryLog) Drain() map[string]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	counts := l.counts
+	l.counts = make(map[string]int)
+	return counts
+}
@@ -0,0 +1,147 @@
+package search
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	suggestTopK   = 10 // 每个前缀最多保留的候选数
+	maxSuggestKey = 32 // 超出部分不再建树, 长前缀按截断后的结果返回
+)
+
+// Suggestion 联想候选
+type Suggestion struct {
+	Type     DocType
+	ID       uint64
+	Text     string
+	Subtitle string
+	Weight   float64
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	top      []int32 // 以该前缀开头的候选下标, 按权重降序
+}
+
+// Suggester 联想前缀树. 每个节点预存权重最高的若干候选, 查询只需沿前缀走到对应节点
+type Suggester struct {
+	mu    sync.RWMutex
+	root  *trieNode
+	items []Suggestion
+}
+
+func NewSuggester() *Suggester {
+	return &Suggester{root: &trieNode{}}
+}
+
+// Replace 以 items 全量重建前缀树
+func (sg *Suggester) Replace(items []Suggestion) {
+	items = slices.Clone(items)
+	// 按权重降序插入, 节点上的候选列表天然有序
+	slices.SortStableFunc(items, func(a, b Suggestion) int {
+		switch {
+		case a.Weight > b.Weight:
+			return -1
+		case a.Weight < b.Weight:
+			return 1
+		}
+		return len(a.Text) - len(b.Text)
+	})
+	root := &trieNode{}
+	for i, item := range items {
+		for _, key := range suggestKeys(item.Text) {
+			insertKey(root, key, int32(i))
+		}
+	}
+	sg.mu.Lock()
+	sg.root, sg.items = root, items
+	sg.mu.Unlock()
+}
+
+// Suggest 返回以 prefix 开头的候选, 中文可用全拼或首字母输入
+func (sg *Suggester) Suggest(prefix string, limit int) []Suggestion {
+	q := Normalize(prefix)
+	if q == "" {
+		return nil
+	}
+	keys := []string{q}
+	if strings.Contains(q, " ") {
+		keys = append(keys, strings.ReplaceAll(q, " ", ""))
+	}
+	if hasPinyin(q) {
+		full, _ := toPinyin(q)
+		keys = append(keys, strings.ReplaceAll(full, " ", ""))
+	}
+	limit = min(limit, suggestTopK)
+
+	sg.mu.RLock()
+	defer sg.mu.RUnlock()
+	var picked []int32
+	for _, key := range keys {
+		node := sg.root
+		for i, r := range []rune(key) {
+			if i >= maxSuggestKey || node == nil {
+				break
+			}
+			node = node.children[r]
+		}
+		if node == nil {
+			continue
+		}
+		for _, idx := range node.top {
+			if !slices.Contains(picked, idx) {
+				picked = append(picked, idx)
+			}
+		}
+	}
+	// 多个写法的结果合并后仍按权重排序
+	slices.Sort(picked)
+	out := make([]Suggestion, 0, min(len(picked), limit))
+	for _, idx := range picked[:min(len(picked), limit)] {
+		out = append(out, sg.items[idx])
+	}
+	return out
+}
+
+// suggestKeys 原文、各词起始的后缀, 以及中文的连写全拼与首字母
+func suggestKeys(text string) []string {
+	normalized := Normalize(text)
+	if normalized == "" {
+		return nil
+	}
+	keys := []string{normalized}
+	for i, r := range normalized {
+		if r == ' ' {
+			keys = append(keys, normalized[i+1:])
+		}
+	}
+	if hasPinyin(normalized) {
+		full, initials := toPinyin(normalized)
+		keys = append(keys, strings.ReplaceAll(full, " ", ""), strings.ReplaceAll(initials, " ", ""))
+	}
+	return dedupe(keys)
+}
+
+func insertKey(root *trieNode, key string, idx int32) {
+	node := root
+	for i, r := range []rune(key) {
+		if i >= maxSuggestKey {
+			break
+		}
+		child, ok := node.children[r]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+		// 同一候选的多个 key 连续插入, 只需与末尾比较去重
+		if len(node.top) < suggestTopK && (len(node.top) == 0 || node.top[len(node.top)-1] != idx) {
+			node.top = append(node.top, idx)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 未完成的分片上传放在根目录下的隐藏目录中, 以 "." 开头的路径不会作为对象列出或对外提供
+const multipartDir = ".multipart"
+
+// 系统 mime 表可能缺少音频类型, 这里补齐上传策略允许的格式
+var contentTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".flac": "audio/flac",
+	".ogg":  "audio/ogg",
+	".m4a":  "audio/x-m4a",
+	".mp4":  "audio/mp4",
+	".wav":  "audio/wav",
+	".jpg":  "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+}
+
+// Local 本地文件系统存储, 文件由应用自身在 base-url 的路径下提供访问.
+// 对象与公开桶一样可直接访问, signedPrefixes 下的对象(如音频)除外, 只能通过带签名的地址访问; 带签名的地址额外校验有效期
+type Local struct {
+	root           string
+	baseURL        string
+	prefix         string // baseURL 的路径部分, 即路由前缀
+	secret         []byte
+	signedPrefixes []string
+}
+
+func NewLocal(root, baseURL, secret string, signedPrefixes ...string) (*Local, error) {
+	if root == "" {
+		root = "data/storage"
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("local storage: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Join(root, multipartDir), 0o755); err != nil {
+		return nil, fmt.Errorf("local storage: %w", err)
+	}
+	if baseURL == "" {
+		baseURL = "/files"
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Path == "" || u.Path == "/" {
+		return nil, fmt.Errorf("local storage: base-url must contain a path, got %q", baseURL)
+	}
+	return &Local{
+		root:           root,
+		baseURL:        baseURL,
+		prefix:         u.Path,
+		secret:         []byte(secret),
+		signedPrefixes: signedPrefixes,
+	}, nil
+}
+
+func (l *Local) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	_, err = writeFile(p, r, "")
+	return err
+}
+
+func (l *Local) Get(_ context.Context, key string) (Object, ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, ObjectInfo{}, notFound(err)
+	}
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		f.Close()
+		return nil, ObjectInfo{}, ErrNotFound
+	}
+	return f, l.info(key, stat), nil
+}
+
+func (l *Local) Copy(ctx context.Context, srcKey, dstKey, _ string) error {
+	src, _, err := l.Get(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return l.Put(ctx, dstKey, src, 0, "")
+}
+
+func (l *Local) Remove(_ context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	l.removeEmptyDirs(filepath.Dir(p))
+	return nil
+}
+
+func (l *Local) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	// 从 prefix 所在的目录开始遍历, 避免扫描整个根目录
+	start := l.root
+	if dir := path.Dir(prefix + "x"); dir != "." {
+		start = filepath.Join(l.root, filepath.FromSlash(dir))
+	}
+	var objects []ObjectInfo
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != start {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, l.info(key, stat))
+		return nil
+	})
+	return objects, err
+}
+
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + key
+}
+
+func (l *Local) Key(fileURL string) (string, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid fileURL: %w", err)
+	}
+	key, ok := strings.CutPrefix(u.Path, l.prefix+"/")
+	if !ok || key == "" {
+		return "", ErrInvalidURL
+	}
+	return key, nil
+}
+
+// PresignedURL 在公开地址后附加到期时间与 HMAC 签名, 由 ServeHTTP 校验
+func (l *Local) PresignedURL(_ context.Context, key string, expiry time.Duration) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	return l.URL(key) + "?expires=" + expires + "&signature=" + l.sign(key, expires), nil
+}
+
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *Local) requiresSignature(key string) bool {
+	for _, prefix := range l.signedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Local) Prefix() string {
+	return l.prefix
+}
+
+// ServeHTTP 提供对象下载, 支持 Range 请求以便音频拖动播放
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := strings.CutPrefix(r.URL.Path, l.prefix+"/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if q := r.URL.Query(); q.Has("signature") || l.requiresSignature(key) {
+		expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+		if err != nil || time.Now().Unix() > expires ||
+			!hmac.Equal([]byte(l.sign(key, q.Get("expires"))), []byte(q.Get("signature"))) {
+			http.Error(w, "signature invalid or expired", http.StatusForbidden)
+			return
+		}
+	}
+	obj, info, err := l.Get(r.Context(), key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer obj.Close()
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	http.ServeContent(w, r, path.Base(key), info.LastModified, obj)
+}
+
+func (l *Local) NewMultipartUpload(_ context.Context, key string) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+	uploadId := uuid.NewString()
+	dir := l.uploadDir(uploadId)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "key"), []byte(key), 0o644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return uploadId, nil
+}
+
+func (l *Local) PutPart(_ context.Context, key, uploadId string, partNumber int, r io.Reader, _ int64, sha256Hex string) (Part, error) {
+	dir, err := l.openUpload(key, uploadId)
+	if err != nil {
+		return Part{}, err
+	}
+	partPath := filepath.Join(dir, fmt.Sprintf("%05d", partNumber))
+	etag, err := writeFile(partPath, r, sha256Hex)
+	if err != nil {
+		return Part{}, err
+	}
+	// ETag 单独保存, 列出分片时无需重新计算
+	if err = os.WriteFile(partPath+".etag", []byte(etag), 0o644); err != nil {
+		return Part{}, err
+	}
+	stat, err := os.Stat(partPath)
+	if err != nil {
+		return Part{}, err
+	}
+	return Part{PartNumber: partNumber, Size: stat.Size(), ETag: etag}, nil
+}
+
+func (l *Local) ListParts(_ context.Context, key, uploadId string) ([]Part, error) {
+	dir, err := l.openUpload(key, uploadId)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var parts []Part
+	for _, e := range entries {
+		n, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		// 没有 ETag 的分片未写完整, 视为未上传
+		etag, err := os.ReadFile(filepath.Join(dir, e.Name()+".etag"))
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, Part{PartNumber: n, Size: info.Size(), ETag: string(etag)})
+	}
+	return parts, nil
+}
+
+func (l *Local) CompleteMultipartUpload(_ context.Context, key, uploadId string, parts []Part) error {
+	dir, err := l.openUpload(key, uploadId)
+	if err != nil {
+		return err
+	}
+	p, _ := l.path(key)
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	files := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		partPath := filepath.Join(dir, fmt.Sprintf("%05d", part.PartNumber))
+		if etag, err := os.ReadFile(partPath + ".etag"); err != nil || string(etag) != strings.Trim(part.ETag, `"`) {
+			return fmt.Errorf("part %d: etag mismatch", part.PartNumber)
+		}
+		f, err := os.Open(partPath)
+		if err != nil {
+			return fmt.Errorf("part %d: %w", part.PartNumber, notFound(err))
+		}
+		defer f.Close()
+		files = append(files, f)
+	}
+	if _, err = writeFile(p, io.MultiReader(files...), ""); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+func (l *Local) AbortMultipartUpload(_ context.Context, key, uploadId string) error {
+	dir, err := l.openUpload(key, uploadId)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+func (l *Local) ListMultipartUploads(_ context.Context, prefix string) ([]MultipartUpload, error) {
+	entries, err := os.ReadDir(filepath.Join(l.root, multipartDir))
+	if err != nil {
+		return nil, err
+	}
+	var uploads []MultipartUpload
+	for _, e := range entries {
+		key, err := os.ReadFile(filepath.Join(l.root, multipartDir, e.Name(), "key"))
+		if err != nil || !strings.HasPrefix(string(key), prefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		uploads = append(uploads, MultipartUpload{Key: string(key), UploadID: e.Name(), Initiated: info.ModTime()})
+	}
+	return uploads, nil
+}
+
+// path 对象名对应的文件路径, 拒绝越出根目录或含隐藏路径段的对象名
+func (l *Local) path(key string) (string, error) {
+	if key == "" || path.Clean("/"+key) != "/"+key {
+		return "", ErrInvalidKey
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if strings.HasPrefix(seg, ".") {
+			return "", ErrInvalidKey
+		}
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+func (l *Local) uploadDir(uploadId string) string {
+	return filepath.Join(l.root, multipartDir, uploadId)
+}
+
+func (l *Local) openUpload(key, uploadId string) (string, error) {
+	if uuid.Validate(uploadId) != nil {
+		return "", ErrNoSuchUpload
+	}
+	dir := l.uploadDir(uploadId)
+	stored, err := os.ReadFile(filepath.Join(dir, "key"))
+	if err != nil || string(stored) != key {
+		return "", ErrNoSuchUpload
+	}
+	return dir, nil
+}
+
+func (l *Local) info(key string, stat fs.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  contentTypeOf(key),
+		LastModified: stat.ModTime(),
+	}
+}
+
+// removeEmptyDirs 删除对象后向上清理空目录, 直到根目录
+func (l *Local) removeEmptyDirs(dir string) {
+	for dir != l.root && strings.HasPrefix(dir, l.root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// writeFile 先写临时文件再改名, 读者不会看到写了一半的文件; sha256Hex 非空时校验内容, 返回内容的 MD5 作为 ETag
+func writeFile(p string, r io.Reader, sha256Hex string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	md5sum, sha := md5.New(), sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, md5sum, sha), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if sha256Hex != "" && !strings.EqualFold(hex.EncodeToString(sha.Sum(nil)), sha256Hex) {
+		return "", ErrChecksum
+	}
+	if err = os.Rename(tmp.Name(), p); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(md5sum.Sum(nil)), nil
+}
+
+func contentTypeOf(key string) string {
+	ext := strings.ToLower(path.Ext(key))
+	if t, ok := contentTypes[ext]; ok {
+		return t
+	}
+	return mime.TypeByExtension(ext)
+}
+
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+var _ Server = (*Local)(nil)
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const memoryBaseURL = "memory://objects"
+
+// Memory 内存存储, 进程退出即丢失, 用于测试与本地调试
+type Memory struct {
+	mu      sync.RWMutex
+	objects map[string]memObject
+	uploads map[string]*memUpload
+}
+
+type memObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+type memUpload struct {
+	key       string
+	initiated time.Time
+	parts     map[int][]byte
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		objects: make(map[string]memObject),
+		uploads: make(map[string]*memUpload),
+	}
+}
+
+// memReader 对象内容的只读副本, 关闭无需释放资源
+type memReader struct {
+	*bytes.Reader
+}
+
+func (memReader) Close() error { return nil }
+
+func (m *Memory) Put(_ context.Context, key string, r io.Reader, _ int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if contentType == "" {
+		contentType = contentTypeOf(key)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = memObject{data: data, contentType: contentType, modTime: time.Now()}
+	return nil
+}
+
+func (m *Memory) Get(_ context.Context, key string) (Object, ObjectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, ObjectInfo{}, ErrNotFound
+	}
+	return memReader{bytes.NewReader(obj.data)}, obj.info(key), nil
+}
+
+func (m *Memory) Copy(_ context.Context, srcKey, dstKey, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	obj, ok := m.objects[srcKey]
+	if !ok {
+		return ErrNotFound
+	}
+	if contentType != "" {
+		obj.contentType = contentType
+	}
+	obj.modTime = time.Now()
+	m.objects[dstKey] = obj
+	return nil
+}
+
+func (m *Memory) Remove(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *Memory) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var objects []ObjectInfo
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, obj.info(key))
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (m *Memory) URL(key string) string {
+	return memoryBaseURL + "/" + key
+}
+
+func (m *Memory) Key(fileURL string) (string, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid fileURL: %w", err)
+	}
+	key, ok := strings.CutPrefix(u.Path, "/")
+	if u.Scheme+"://"+u.Host != memoryBaseURL || !ok || key == "" {
+		return "", ErrInvalidURL
+	}
+	return key, nil
+}
+
+// PresignedURL 内存存储无法对外提供访问, 只附加到期时间以便调用方区分
+func (m *Memory) PresignedURL(_ context.Context, key string, expiry time.Duration) (string, error) {
+	return m.URL(key) + "?expires=" + strconv.FormatInt(time.Now().Add(expiry).Unix(), 10), nil
+}
+
+func (m *Memory) NewMultipartUpload(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	uploadId := uuid.NewString()
+	m.uploads[uploadId] = &memUpload{key: key, initiated: time.Now(), parts: make(map[int][]byte)}
+	return uploadId, nil
+}
+
+func (m *Memory) PutPart(_ context.Context, key, uploadId string, partNumber int, r io.Reader, _ int64, sha256Hex string) (Part, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Part{}, err
+	}
+	if sha256Hex != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), sha256Hex) {
+			return Part{}, ErrChecksum
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.uploads[uploadId]
+	if !ok || u.key != key {
+		return Part{}, ErrNoSuchUpload
+	}
+	u.parts[partNumber] = data
+	return Part{PartNumber: partNumber, Size: int64(len(data)), ETag: etagOf(data)}, nil
+}
+
+func (m *Memory) ListParts(_ context.Context, key, uploadId string) ([]Part, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	u, ok := m.uploads[uploadId]
+	if !ok || u.key != key {
+		return nil, ErrNoSuchUpload
+	}
+	parts := make([]Part, 0, len(u.parts))
+	for n, data := range u.parts {
+		parts = append(parts, Part{PartNumber: n, Size: int64(len(data)), ETag: etagOf(data)})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (m *Memory) CompleteMultipartUpload(_ context.Context, key, uploadId string, parts []Part) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.uploads[uploadId]
+	if !ok || u.key != key {
+		return ErrNoSuchUpload
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	var buf bytes.Buffer
+	for _, p := range parts {
+		data, ok := u.parts[p.PartNumber]
+		if !ok || etagOf(data) != strings.Trim(p.ETag, `"`) {
+			return fmt.Errorf("part %d: etag mismatch", p.PartNumber)
+		}
+		buf.Write(data)
+	}
+	m.objects[key] = memObject{data: buf.Bytes(), contentType: contentTypeOf(key), modTime: time.Now()}
+	delete(m.uploads, uploadId)
+	return nil
+}
+
+func (m *Memory) AbortMultipartUpload(_ context.Context, key, uploadId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.uploads[uploadId]
+	if !ok || u.key != key {
+		return ErrNoSuchUpload
+	}
+	delete(m.uploads, uploadId)
+	return nil
+}
+
+func (m *Memory) ListMultipartUploads(_ context.Context, prefix string) ([]MultipartUpload, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var uploads []MultipartUpload
+	for id, u := range m.uploads {
+		if strings.HasPrefix(u.key, prefix) {
+			uploads = append(uploads, MultipartUpload{Key: u.key, UploadID: id, Initiated: u.initiated})
+		}
+	}
+	return uploads, nil
+}
+
+func (o memObject) info(key string) ObjectInfo {
+	return ObjectInfo{
+		Key:          key,
+		Size:         int64(len(o.data)),
+		ContentType:  o.contentType,
+		LastModified: o.modTime,
+	}
+}
+
+func etagOf(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+var _ Storage = (*Memory)(nil)
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"vibe-music-server/internal/config"
+)
+
+// Minio MinIO 及其他兼容 S3 的对象存储
+type Minio struct {
+	client   *minio.Client
+	bucket   string
+	endpoint string
+}
+
+func NewMinio(cfg config.Minio) (*Minio, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("minio: %w", err)
+	}
+	return &Minio{
+		client:   client,
+		bucket:   cfg.Bucket,
+		endpoint: cfg.Endpoint,
+	}, nil
+}
+
+func (m *Minio) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := m.client.PutObject(ctx, m.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (m *Minio) Get(ctx context.Context, key string) (Object, ObjectInfo, error) {
+	obj, err := m.client.GetObject(ctx, m.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, ObjectInfo{}, mapErr(err)
+	}
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, ObjectInfo{}, mapErr(err)
+	}
+	return obj, toObjectInfo(stat), nil
+}
+
+func (m *Minio) Copy(ctx context.Context, srcKey, dstKey, contentType string) error {
+	_, err := m.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: m.bucket, Object: dstKey, ContentType: contentType, ReplaceMetadata: true},
+		minio.CopySrcOptions{Bucket: m.bucket, Object: srcKey})
+	return mapErr(err)
+}
+
+func (m *Minio) Remove(ctx context.Context, key string) error {
+	return m.client.RemoveObject(ctx, m.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (m *Minio) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for info := range m.client.ListObjects(ctx, m.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		objects = append(objects, toObjectInfo(info))
+	}
+	return objects, nil
+}
+
+func (m *Minio) URL(key string) string {
+	return fmt.Sprintf("%s/%s/%s", m.endpoint, m.bucket, key)
+}
+
+func (m *Minio) Key(fileURL string) (string, error) {
+	// 1. 解析 URL
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid fileURL: %w", err)
+	}
+
+	// 2. 去掉最前面 "/" 得到  bucket+对象 路径
+	fullPath := strings.TrimPrefix(u.Path, "/") // vibe-music-data/img/a/b.jpg
+
+	// 3. 去掉 bucket 前缀，拿到纯对象名
+	if !strings.HasPrefix(fullPath, m.bucket+"/") {
+		return "", ErrInvalidURL
+	}
+	return strings.TrimPrefix(fullPath, m.bucket+"/"), nil // img/a/b.jpg
+}
+
+func (m *Minio) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := m.client.PresignedGetObject(ctx, m.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (m *Minio) NewMultipartUpload(ctx context.Context, key string) (string, error) {
+	return m.core().NewMultipartUpload(ctx, m.bucket, key, minio.PutObjectOptions{})
+}
+
+func (m *Minio) PutPart(ctx context.Context, key, uploadId string, partNumber int, r io.Reader, size int64, sha256Hex string) (Part, error) {
+	part, err := m.core().PutObjectPart(ctx, m.bucket, key, uploadId, partNumber, r, size,
+		minio.PutObjectPartOptions{Sha256Hex: sha256Hex})
+	if err != nil {
+		return Part{}, mapErr(err)
+	}
+	return Part{PartNumber: part.PartNumber, Size: part.Size, ETag: part.ETag}, nil
+}
+
+func (m *Minio) ListParts(ctx context.Context, key, uploadId string) ([]Part, error) {
+	var parts []Part
+	marker := 0
+	for {
+		res, err := m.core().ListObjectParts(ctx, m.bucket, key, uploadId, marker, 1000)
+		if err != nil {
+			return nil, mapErr(err)
+		}
+		for _, p := range res.ObjectParts {
+			parts = append(parts, Part{PartNumber: p.PartNumber, Size: p.Size, ETag: p.ETag})
+		}
+		if !res.IsTruncated {
+			return parts, nil
+		}
+		marker = res.NextPartNumberMarker
+	}
+}
+
+func (m *Minio) CompleteMultipartUpload(ctx context.Context, key, uploadId string, parts []Part) error {
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, p := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	_, err := m.core().CompleteMultipartUpload(ctx, m.bucket, key, uploadId, completeParts, minio.PutObjectOptions{})
+	return mapErr(err)
+}
+
+func (m *Minio) AbortMultipartUpload(ctx context.Context, key, uploadId string) error {
+	return mapErr(m.core().AbortMultipartUpload(ctx, m.bucket, key, uploadId))
+}
+
+func (m *Minio) ListMultipartUploads(ctx context.Context, prefix string) ([]MultipartUpload, error) {
+	var uploads []MultipartUpload
+	for info := range m.client.ListIncompleteUploads(ctx, m.bucket, prefix, true) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		uploads = append(uploads, MultipartUpload{Key: info.Key, UploadID: info.UploadID, Initiated: info.Initiated})
+	}
+	return uploads, nil
+}
+
+func (m *Minio) core() minio.Core {
+	return minio.Core{Client: m.client}
+}
+
+func toObjectInfo(info minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}
+}
+
+// mapErr 将 S3 的错误码转换为本包的错误, 便于调用方与驱动无关地判断
+func mapErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey":
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	case "NoSuchUpload":
+		return fmt.Errorf("%w: %v", ErrNoSuchUpload, err)
+	case "XAmzContentSHA256Mismatch", "BadDigest":
+		return fmt.Errorf("%w: %v", ErrChecksum, err)
+	}
+	return err
+}
+
+var _ Storage = (*Minio)(nil)
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"vibe-music-server/internal/config"
+)
+
+const (
+	DriverMinio  = "minio"
+	DriverLocal  = "local"
+	DriverMemory = "memory"
+)
+
+var (
+	ErrNotFound     = errors.New("storage: object not found")
+	ErrNoSuchUpload = errors.New("storage: no such upload")
+	ErrInvalidKey   = errors.New("storage: invalid object key")
+	ErrInvalidURL   = errors.New("storage: url does not belong to this storage")
+	ErrChecksum     = errors.New("storage: checksum mismatch")
+)
+
+// ObjectInfo 对象的基本信息
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Part 分片上传中已上传的一个分片
+type Part struct {
+	PartNumber int
+	Size       int64
+	ETag       string
+}
+
+// MultipartUpload 未完成的分片上传
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// Object 打开的对象, 支持随机读取, 调用方负责关闭
+type Object interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+}
+
+// Storage 对象存储. 对象名统一使用 "/" 分隔, 如 songs/<uuid>-name.mp3;
+// 删除不存在的对象不报错, 读取不存在的对象返回 ErrNotFound
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (Object, ObjectInfo, error)
+	Copy(ctx context.Context, srcKey, dstKey, contentType string) error
+	Remove(ctx context.Context, key string) error
+	// List 列出 prefix 下的所有对象(递归)
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+
+	// URL 对象的公开访问地址, Key 为其逆运算
+	URL(key string) string
+	Key(fileURL string) (string, error)
+	// PresignedURL 限时有效的下载地址
+	PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+
+	// 分片上传, 分片号从 1 开始; sha256Hex 非空时按其校验分片内容
+	NewMultipartUpload(ctx context.Context, key string) (string, error)
+	PutPart(ctx context.Context, key, uploadId string, partNumber int, r io.Reader, size int64, sha256Hex string) (Part, error)
+	ListParts(ctx context.Context, key, uploadId string) ([]Part, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadId string, parts []Part) error
+	AbortMultipartUpload(ctx context.Context, key, uploadId string) error
+	ListMultipartUploads(ctx context.Context, prefix string) ([]MultipartUpload, error)
+}
+
+// Server 由应用自身对外提供文件访问的存储, 需在 Prefix() 路径下注册 ServeHTTP
+type Server interface {
+	Storage
+	http.Handler
+	Prefix() string
+}
+
+// New 按配置 storage.driver 创建存储, 未配置时使用 MinIO
+func New() (Storage, error) {
+	cfg := config.Get()
+	switch driver := strings.ToLower(cfg.Storage.Driver); driver {
+	case "", DriverMinio:
+		return NewMinio(cfg.Minio)
+	case DriverLocal:
+		secret := cfg.Storage.Local.Secret
+		if secret == "" {
+			secret = cfg.Jwt.Secret
+		}
+		// 音频只通过 /song/stream 返回的签名地址播放, 以便按权益限制音质
+		return NewLocal(cfg.Storage.Local.Root, cfg.Storage.Local.BaseURL, secret, "songs/")
+	case DriverMemory:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
@@ -0,0 +1,127 @@
+package upload
+
+import (
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+	"unicode"
+	"vibe-music-server/internal/pkg/result/consts"
+)
+
+// 嗅探文件类型时读取的头部长度
+const sniffLen = 3072
+
+// RejectError 文件不符合上传策略, Msg 可直接返回给客户端
+type RejectError struct {
+	Msg string
+}
+
+func (e *RejectError) Error() string {
+	return e.Msg
+}
+
+func reject(format string, args ...any) error {
+	return &RejectError{Msg: fmt.Sprintf(format, args...)}
+}
+
+// File 校验通过的文件信息
+type File struct {
+	ContentType string
+	Ext         string
+	Width       int // 非图片为 0
+	Height      int
+}
+
+// 部分类型不使用 mimetype 给出的扩展名
+var extOverrides = map[string]string{
+	"audio/ogg": ".ogg",
+}
+
+// Check 按策略校验文件: 大小、魔数识别的真实类型以及图片尺寸; 返回前会把读取位置复原
+func (p Policy) Check(r io.ReadSeeker, size int64) (File, error) {
+	var f File
+	if size <= 0 {
+		return f, reject(consts.FileEmpty)
+	}
+	if size > p.MaxSize {
+		return f, reject("%s(最大 %dMB)", consts.FileTooLarge, p.MaxSize/mb)
+	}
+
+	head := make([]byte, min(size, sniffLen))
+	if _, err := io.ReadFull(r, head); err != nil {
+		return f, fmt.Errorf("read head: %w", err)
+	}
+	mt := mimetype.Detect(head)
+	allowed := false
+	for _, t := range p.AllowedTypes {
+		if mt.Is(t) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return f, reject("%s(%s)", consts.FileTypeNotAllowed, mt.String())
+	}
+	f.ContentType, _, _ = strings.Cut(mt.String(), ";")
+	f.Ext = mt.Extension()
+	if ext, ok := extOverrides[f.ContentType]; ok {
+		f.Ext = ext
+	}
+
+	if strings.HasPrefix(f.ContentType, "image/") {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return f, fmt.Errorf("seek: %w", err)
+		}
+		cfg, _, err := image.DecodeConfig(r)
+		if err != nil {
+			return f, reject("%s(%s)", consts.FileTypeNotAllowed, f.ContentType)
+		}
+		f.Width, f.Height = cfg.Width, cfg.Height
+		if (p.MaxWidth > 0 && cfg.Width > p.MaxWidth) || (p.MaxHeight > 0 && cfg.Height > p.MaxHeight) {
+			return f, reject("%s(最大 %dx%d)", consts.ImageTooLarge, p.MaxWidth, p.MaxHeight)
+		}
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return f, fmt.Errorf("seek: %w", err)
+	}
+	return f, nil
+}
+
+const maxNameLen = 64
+
+// SanitizeFilename 清理客户端提供的文件名: 去掉路径与原扩展名, 只保留字母、数字与 -_,
+// 其余字符替换为 -, 再接上按真实类型得到的扩展名
+func SanitizeFilename(name, ext string) string {
+	name = name[strings.LastIndexAny(name, `/\`)+1:]
+	if i := strings.LastIndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	var b strings.Builder
+	n := 0
+	dash := false
+	for _, r := range name {
+		if n >= maxNameLen {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		} else {
+			continue
+		}
+		n++
+	}
+	clean := strings.TrimRight(b.String(), "-")
+	if clean == "" {
+		clean = "file"
+	}
+	return clean + ext
+}
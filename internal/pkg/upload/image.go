@@ -0,0 +1,179 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/disintegration/imaging"
+	"github.com/google/uuid"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"vibe-music-server/internal/config"
+)
+
+const (
+	fullQuality      = 90
+	renditionQuality = 82
+)
+
+// Object 待写入对象存储的一个对象
+type Object struct {
+	Key         string
+	Width       int // 缩略图宽度, 原尺寸图为 0
+	Data        []byte
+	ContentType string
+}
+
+type format struct {
+	contentType string
+	ext         string
+}
+
+var (
+	formatWebP = format{"image/webp", ".webp"}
+	formatJPEG = format{"image/jpeg", ".jpg"}
+	formatPNG  = format{"image/png", ".png"}
+)
+
+// ProcessImage 按 EXIF 方向摆正图片后重新编码原尺寸图与各宽度的缩略图, 重新编码会丢弃 EXIF 等元数据.
+// 一组图片放在 dir(folder/<原图 SHA-256>) 下: 原尺寸图为 <文件名>.<ext>, 缩略图为 -<宽度>.<ext>, 第一个对象为原尺寸图
+func ProcessImage(r io.Reader, dir, filename string, widths []int) ([]Object, error) {
+	img, err := imaging.Decode(r, imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	fallback := formatJPEG
+	if o, ok := img.(interface{ Opaque() bool }); ok && !o.Opaque() {
+		fallback = formatPNG
+	}
+	if cwebpPath() != "" {
+		objects, err := encodeImage(img, formatWebP, dir, filename, widths)
+		if err == nil {
+			return objects, nil
+		}
+		log.Printf("upload.ProcessImage webp err: %v\n", err)
+	}
+	return encodeImage(img, fallback, dir, filename, widths)
+}
+
+func encodeImage(img image.Image, f format, dir, filename string, widths []int) ([]Object, error) {
+	full, err := encode(img, f, fullQuality)
+	if err != nil {
+		return nil, err
+	}
+	objects := []Object{{Key: path.Join(dir, SanitizeFilename(filename, f.ext)), Data: full, ContentType: f.contentType}}
+	for _, w := range widths {
+		// 不放大, 原图不够宽时直接复用原尺寸图, 保证每个宽度都有对应对象
+		data := full
+		if w < img.Bounds().Dx() {
+			if data, err = encode(imaging.Resize(img, w, 0, imaging.Lanczos), f, renditionQuality); err != nil {
+				return nil, err
+			}
+		}
+		objects = append(objects, Object{Key: ThumbnailKey(objects[0].Key, w, f.ext), Width: w, Data: data, ContentType: f.contentType})
+	}
+	return objects, nil
+}
+
+func encode(img image.Image, f format, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch f {
+	case formatWebP:
+		return encodeWebP(img, quality)
+	case formatPNG:
+		err = imaging.Encode(&buf, img, imaging.PNG, imaging.PNGCompressionLevel(png.BestCompression))
+	default:
+		err = imaging.Encode(&buf, img, imaging.JPEG, imaging.JPEGQuality(quality))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeWebP 标准库只能解码 WebP, 编码交给 cwebp
+func encodeWebP(img image.Image, quality int) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "vibe-webp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	in, out := filepath.Join(dir, "in.png"), filepath.Join(dir, "out.webp")
+	var buf bytes.Buffer
+	if err = (&png.Encoder{CompressionLevel: png.BestSpeed}).Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(in, buf.Bytes(), 0o600); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, cwebpPath(), "-quiet", "-metadata", "none", "-q", strconv.Itoa(quality), in, "-o", out)
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("cwebp: %w: %s", err, strings.TrimSpace(string(msg)))
+	}
+	return os.ReadFile(out)
+}
+
+var cwebpPath = sync.OnceValue(func() string {
+	name := config.Get().Upload.Cwebp
+	if name == "" {
+		name = "cwebp"
+	}
+	p, err := exec.LookPath(name)
+	if err != nil {
+		log.Printf("cwebp not found, images will be stored as JPEG/PNG: %v\n", err)
+		return ""
+	}
+	return p
+})
+
+// isImageDir 一组图片所在的目录名: 按内容寻址时为 SHA-256, 此前为 uuid
+func isImageDir(name string) bool {
+	if len(name) == sha256.Size*2 {
+		_, err := hex.DecodeString(name)
+		return err == nil
+	}
+	return uuid.Validate(name) == nil
+}
+
+// ThumbnailPrefix ProcessImage 生成的图片的缩略图对象名前缀: 与原尺寸图同目录、以 "-" 开头; 不是这种布局的对象返回 false
+func ThumbnailPrefix(objectKey string) (string, bool) {
+	dir := path.Dir(objectKey)
+	if !isImageDir(path.Base(dir)) {
+		return "", false
+	}
+	return dir + "/-", true
+}
+
+// ThumbnailKey 宽度为 width 的缩略图对象名
+func ThumbnailKey(objectKey string, width int, ext string) string {
+	return path.Join(path.Dir(objectKey), "-"+strconv.Itoa(width)+ext)
+}
+
+// ParseThumbnailKey 从缩略图对象名中解析宽度与扩展名, 不是缩略图时返回 false
+func ParseThumbnailKey(key string) (int, string, bool) {
+	name, ok := strings.CutPrefix(path.Base(key), "-")
+	if !ok {
+		return 0, "", false
+	}
+	ext := path.Ext(name)
+	width, err := strconv.Atoi(strings.TrimSuffix(name, ext))
+	if err != nil || width <= 0 {
+		return 0, "", false
+	}
+	return width, ext, true
+}
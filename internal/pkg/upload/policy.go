@@ -0,0 +1,87 @@
+package upload
+
+import (
+	"strings"
+	"time"
+	"vibe-music-server/internal/config"
+)
+
+const mb = 1 << 20
+
+var (
+	imageTypes  = []string{"image/jpeg", "image/png", "image/webp"}
+	coverWidths = []int{64, 200, 600, 1200}
+)
+
+// Policy 某一类上传文件的限制
+type Policy struct {
+	Name         string
+	MaxSize      int64 // 字节
+	MaxWidth     int   // 0 表示不限
+	MaxHeight    int
+	AllowedTypes []string
+	Renditions   []int // 图片缩略图宽度, 为空则不处理图片
+}
+
+// 内置默认策略, 配置文件中的同名策略会逐项覆盖
+var defaultPolicies = map[string]Policy{
+	"songs": {
+		MaxSize:      200 * mb, // 无损音频较大, 超过几十 MB 的建议走分片上传
+		AllowedTypes: []string{"audio/mpeg", "audio/flac", "audio/ogg", "audio/x-m4a", "audio/mp4", "audio/wav"},
+	},
+	"covers":  {MaxSize: 10 * mb, MaxWidth: 4096, MaxHeight: 4096, AllowedTypes: imageTypes, Renditions: coverWidths},
+	"artists": {MaxSize: 5 * mb, MaxWidth: 4096, MaxHeight: 4096, AllowedTypes: imageTypes, Renditions: coverWidths},
+	"banners": {MaxSize: 10 * mb, MaxWidth: 4096, MaxHeight: 4096, AllowedTypes: imageTypes, Renditions: []int{600, 1200, 1920}},
+	"users":   {MaxSize: 2 * mb, MaxWidth: 2048, MaxHeight: 2048, AllowedTypes: imageTypes, Renditions: []int{64, 200, 600}},
+}
+
+// 存储目录与策略的对应关系, 目录名即策略名的不必列出
+var folderPolicies = map[string]string{
+	"songCovers":     "covers",
+	"playlistCovers": "covers",
+	"albumCovers":    "covers",
+}
+
+// PolicyFor 返回目录对应的上传策略, 未知目录返回 false
+func PolicyFor(folder string) (Policy, bool) {
+	name := folder
+	if n, ok := folderPolicies[folder]; ok {
+		name = n
+	}
+	p, ok := defaultPolicies[name]
+	if !ok {
+		return Policy{}, false
+	}
+	p.Name = name
+	// viper 的 map 键统一为小写
+	if c, ok := config.Get().Upload.Policies[strings.ToLower(name)]; ok {
+		if c.MaxSize > 0 {
+			p.MaxSize = c.MaxSize * mb
+		}
+		if c.MaxWidth > 0 {
+			p.MaxWidth = c.MaxWidth
+		}
+		if c.MaxHeight > 0 {
+			p.MaxHeight = c.MaxHeight
+		}
+		if len(c.AllowedTypes) > 0 {
+			p.AllowedTypes = c.AllowedTypes
+		}
+		if len(c.Renditions) > 0 {
+			p.Renditions = c.Renditions
+		}
+	}
+	return p, true
+}
+
+const (
+	baseTimeout = 10 * time.Second
+	minRate     = 256 << 10 // 按最低 256KB/s 估算传输时间
+	maxTimeout  = 10 * time.Minute
+)
+
+// Timeout 按文件大小估算上传到对象存储的超时时间
+func Timeout(size int64) time.Duration {
+	t := baseTimeout + time.Duration(float64(size)/minRate*float64(time.Second))
+	return min(t, maxTimeout)
+}
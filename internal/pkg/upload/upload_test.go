@@ -0,0 +1,203 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name, ext, want string
+	}{
+		{"cover.jpeg", ".jpg", "cover.jpg"},
+		{"../../etc/passwd", ".png", "passwd.png"},
+		{`C:\Users\me\My Photo (1).PNG`, ".png", "My-Photo-1.png"},
+		{"封面 图.jpeg", ".jpg", "封面-图.jpg"},
+		{"a--b__c.webp", ".webp", "a-b__c.webp"},
+		{"  -lead-and-trail- .jpg", ".jpg", "lead-and-trail.jpg"},
+		{".hidden", ".jpg", "hidden.jpg"},
+		{"???.jpg", ".jpg", "file.jpg"},
+		{"", ".mp3", "file.mp3"},
+		{strings.Repeat("a", 100) + ".flac", ".flac", strings.Repeat("a", maxNameLen) + ".flac"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeFilename(tt.name, tt.ext); got != tt.want {
+			t.Errorf("SanitizeFilename(%q, %q) = %q, want %q", tt.name, tt.ext, got, tt.want)
+		}
+	}
+}
+
+const testSHA = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestThumbnailPrefix(t *testing.T) {
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"covers/" + testSHA + "/cover.webp", "covers/" + testSHA + "/-", true},
+		{"users/0b6a1b8e-2f6c-4f5e-9f3e-3c1f4b1a2d7e/avatar.jpg", "users/0b6a1b8e-2f6c-4f5e-9f3e-3c1f4b1a2d7e/-", true},
+		{"songs/" + testSHA + "/song.mp3", "songs/" + testSHA + "/-", true},
+		{"covers/cover.jpg", "", false},
+		{"covers/not-a-hash/cover.jpg", "", false},
+		{"covers/" + testSHA[:60] + "/cover.jpg", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ThumbnailPrefix(tt.key)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ThumbnailPrefix(%q) = %q, %v, want %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestThumbnailKey(t *testing.T) {
+	key := ThumbnailKey("covers/"+testSHA+"/cover.webp", 200, ".webp")
+	if want := "covers/" + testSHA + "/-200.webp"; key != want {
+		t.Fatalf("ThumbnailKey = %q, want %q", key, want)
+	}
+	prefix, _ := ThumbnailPrefix("covers/" + testSHA + "/cover.webp")
+	if !strings.HasPrefix(key, prefix) {
+		t.Errorf("thumbnail %q is not under prefix %q", key, prefix)
+	}
+
+	tests := []struct {
+		key       string
+		wantWidth int
+		wantExt   string
+		wantOK    bool
+	}{
+		{key, 200, ".webp", true},
+		{"covers/" + testSHA + "/-1200.jpg", 1200, ".jpg", true},
+		{"covers/" + testSHA + "/cover.jpg", 0, "", false},
+		{"covers/" + testSHA + "/-cover.jpg", 0, "", false},
+		{"covers/" + testSHA + "/-0.jpg", 0, "", false},
+		{"covers/" + testSHA + "/--64.jpg", 0, "", false},
+	}
+	for _, tt := range tests {
+		width, ext, ok := ParseThumbnailKey(tt.key)
+		if width != tt.wantWidth || ext != tt.wantExt || ok != tt.wantOK {
+			t.Errorf("ParseThumbnailKey(%q) = %d, %q, %v, want %d, %q, %v",
+				tt.key, width, ext, ok, tt.wantWidth, tt.wantExt, tt.wantOK)
+		}
+	}
+}
+
+func testPNG(t *testing.T, w, h int, opaque bool) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			a := uint8(255)
+			if !opaque && x == 0 {
+				a = 0
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 100, A: a})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessImage(t *testing.T) {
+	dir := "covers/" + testSHA
+	objects, err := ProcessImage(bytes.NewReader(testPNG(t, 300, 150, true)), dir, "My Cover.png", []int{64, 200, 600})
+	if err != nil {
+		t.Fatalf("ProcessImage err: %v", err)
+	}
+	if len(objects) != 4 {
+		t.Fatalf("objects = %d, want 4", len(objects))
+	}
+	full := objects[0]
+	ext := path.Ext(full.Key)
+	if full.Key != dir+"/My-Cover"+ext || full.Width != 0 {
+		t.Errorf("full = %q width %d", full.Key, full.Width)
+	}
+	for i, w := range []int{64, 200, 600} {
+		obj := objects[i+1]
+		width, thumbExt, ok := ParseThumbnailKey(obj.Key)
+		if !ok || width != w || obj.Width != w || thumbExt != ext || obj.ContentType != full.ContentType {
+			t.Errorf("thumbnail %d = %q width %d type %s", w, obj.Key, obj.Width, obj.ContentType)
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(obj.Data))
+		if err != nil {
+			t.Fatalf("decode thumbnail %d: %v", w, err)
+		}
+		// 不放大: 比原图宽的缩略图保持原尺寸
+		if want := min(w, 300); cfg.Width != want {
+			t.Errorf("thumbnail %d width = %d, want %d", w, cfg.Width, want)
+		}
+	}
+}
+
+func TestProcessImageKeepsTransparency(t *testing.T) {
+	if cwebpPath() != "" {
+		t.Skip("cwebp installed, images are stored as WebP")
+	}
+	objects, err := ProcessImage(bytes.NewReader(testPNG(t, 32, 32, false)), "users/"+testSHA, "a.png", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("objects = %d, want 1", len(objects))
+	}
+	if objects[0].ContentType != "image/png" || path.Ext(objects[0].Key) != ".png" {
+		t.Errorf("full = %q %s", objects[0].Key, objects[0].ContentType)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	covers, ok := PolicyFor("albumCovers")
+	if !ok || covers.Name != "covers" {
+		t.Fatalf("PolicyFor(albumCovers) = %+v, %v", covers, ok)
+	}
+	if _, ok := PolicyFor("unknown"); ok {
+		t.Error("PolicyFor(unknown) ok")
+	}
+	img := testPNG(t, 40, 20, true)
+	tests := []struct {
+		name     string
+		policy   Policy
+		data     []byte
+		size     int64
+		wantErr  bool
+		wantType string
+	}{
+		{name: "png", policy: covers, data: img, size: int64(len(img)), wantType: "image/png"},
+		{name: "empty", policy: covers, data: nil, size: 0, wantErr: true},
+		{name: "too large", policy: covers, data: img, size: covers.MaxSize + 1, wantErr: true},
+		{name: "disguised text", policy: covers, data: []byte("<html>not an image</html>"), size: 25, wantErr: true},
+		{name: "image too wide", policy: Policy{MaxSize: mb, MaxWidth: 32, AllowedTypes: imageTypes}, data: img, size: int64(len(img)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(tt.data)
+			f, err := tt.policy.Check(r, tt.size)
+			if tt.wantErr {
+				var rejectErr *RejectError
+				if !errors.As(err, &rejectErr) {
+					t.Fatalf("err = %v, want *RejectError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Check err: %v", err)
+			}
+			if f.ContentType != tt.wantType || f.Width != 40 || f.Height != 20 {
+				t.Errorf("file = %+v", f)
+			}
+			if pos, _ := r.Seek(0, io.SeekCurrent); pos != 0 {
+				t.Errorf("reader not rewound: %d", pos)
+			}
+		})
+	}
+}
@@ -24,13 +24,18 @@ func SetCache[T any](key string, data T) {
 	}
 }
 
-func DeleteCacheByPattern(pattern string) {
+// DeleteCacheByPattern 删除匹配 pattern 的缓存, 返回删除的键数
+func DeleteCacheByPattern(pattern string) int {
 	ctx, cancel := context.WithTimeout(cache.Cache().Context(), 3*time.Second)
 	defer cancel()
+	deleted := 0
 	iter := cache.Cache().Scan(ctx, 0, pattern, 0).Iterator()
 	for iter.Next(cache.Cache().Context()) {
-		_ = cache.Del(iter.Val())
+		if cache.Del(iter.Val()) == nil {
+			deleted++
+		}
 	}
+	return deleted
 }
 
 func GenKeyByPattern(pattern string, args ...any) string {
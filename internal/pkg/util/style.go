@@ -1,7 +1,22 @@
 package util
 
-import "strings"
+import (
+	"slices"
+	"strings"
+)
 
+// ParseStyle 按逗号拆分风格串, 兼容全角逗号与顿号, 去除空白、空项及重复项
 func ParseStyle(str string) []string {
-	return strings.Split(str, ",")
+	fields := strings.FieldsFunc(str, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、'
+	})
+	styles := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(styles, field) {
+			continue
+		}
+		styles = append(styles, field)
+	}
+	return styles
 }
@@ -23,3 +23,7 @@ func (a AdminRepo) SelectByUsername(username string) (*entity.Admin, error) {
 func (a AdminRepo) Insert(admin *entity.Admin) error {
 	return db.Get().Create(admin).Error
 }
+
+func (a AdminRepo) UpdatePassword(adminId uint64, password string) error {
+	return db.Get().Model(&entity.Admin{}).Where("id = ?", adminId).Update("password", password).Error
+}
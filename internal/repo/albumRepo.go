@@ -0,0 +1,169 @@
+package repo
+
+import (
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
+)
+
+type AlbumRepo struct {
+	txConn
+}
+
+func NewAlbumRepo() *AlbumRepo {
+	return &AlbumRepo{}
+}
+
+// WithTx 返回在工作单元事务中执行的仓储
+func (r AlbumRepo) WithTx(uow *db.UnitOfWork) *AlbumRepo {
+	return &AlbumRepo{txConn{uow.Tx()}}
+}
+
+func (r AlbumRepo) GetAllAlbums(data *result.PageResult[vo.AlbumVO], index, size int,
+	title *string, artistId *uint64, artistName *string, albumType *uint8) error {
+	query := db.Get().Table("tb_album al").
+		Select(`al.id           AS album_id,
+		        al.title,
+		        al.artist_id,
+		        a.name          AS artist_name,
+		        al.cover_url,
+		        al.release_date,
+		        al.type`).
+		Joins("LEFT JOIN tb_artist a ON a.id = al.artist_id").
+		Where("a.deleted_at IS NULL")
+
+	// 动态条件
+	if title != nil {
+		query = query.Where("al.title LIKE ?", "%"+*title+"%")
+	}
+	if artistId != nil {
+		query = query.Where("al.artist_id = ?", *artistId)
+	}
+	if artistName != nil {
+		query = query.Where("a.name LIKE ?", "%"+*artistName+"%")
+	}
+	if albumType != nil {
+		query = query.Where("al.type = ?", *albumType)
+	}
+
+	// 总数
+	if err := query.Count(&data.Total).Error; err != nil {
+		return err
+	}
+
+	// 分页数据
+	if err := query.
+		Order("al.release_date DESC, al.id DESC").
+		Limit(size).
+		Offset(index).
+		Scan(&data.Items).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r AlbumRepo) GetAlbumDetail(data *vo.AlbumDetailVO, id uint64) error {
+	if err := db.Get().Table("tb_album al").
+		Select(`al.id           AS album_id,
+		        al.title,
+		        al.artist_id,
+		        a.name          AS artist_name,
+		        al.cover_url,
+		        al.release_date,
+		        al.type,
+		        al.description`).
+		Joins("LEFT JOIN tb_artist a ON a.id = al.artist_id").
+		Where("al.id = ? AND a.deleted_at IS NULL", id).
+		Scan(data).Error; err != nil {
+		return err
+	}
+	// 曲目按碟号、曲目号排序, 未编号的排在最后
+	return db.Get().Table("tb_song s").
+		Select(`s.id            AS song_id,
+		        s.name          AS song_name,
+		        a.name          AS artist_name,
+		        s.disc_number,
+		        s.track_number,
+		        s.duration,
+		        s.cover_url`).
+		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("s.album_id = ? AND s.deleted_at IS NULL", id).
+		Order("s.disc_number ASC, s.track_number = 0 ASC, s.track_number ASC, s.id ASC").
+		Scan(&data.Tracks).Error
+}
+
+func (r AlbumRepo) GetAlbumById(album *entity.Album, id uint64) error {
+	return r.conn().First(album, id).Error
+}
+
+func (r AlbumRepo) GetAlbumByTitle(album *entity.Album, artistId uint64, title string) error {
+	return r.conn().Where("artist_id = ? AND title = ?", artistId, title).First(album).Error
+}
+
+func (r AlbumRepo) ExistAlbum(artistId uint64, title string, excludeId uint64) bool {
+	var count int64
+	db.Get().Model(&entity.Album{}).
+		Where("artist_id = ? AND title = ? AND id <> ?", artistId, title, excludeId).
+		Count(&count)
+	return count > 0
+}
+
+func (r AlbumRepo) CreateAlbum(album *entity.Album) error {
+	return r.conn().Create(album).Error
+}
+
+func (r AlbumRepo) UpdateAlbum(album *entity.Album, updateData any) error {
+	return r.conn().Model(album).Where("id = ?", album.ID).Updates(updateData).Error
+}
+
+// SyncSongAlbumTitle 同步歌曲表中冗余的专辑名
+func (r AlbumRepo) SyncSongAlbumTitle(albumId uint64, title string) error {
+	return r.conn().Model(&entity.Song{}).
+		Where("album_id = ?", albumId).
+		Update("album", title).Error
+}
+
+func (r AlbumRepo) GetCoversByIds(covers *[]string, ids []uint64) error {
+	return db.Get().Model(&entity.Album{}).
+		Where("id IN ?", ids).
+		Pluck("cover_url", covers).Error
+}
+
+// DeleteAlbumsByIds 删除专辑, 歌曲保留但解除关联
+func (r AlbumRepo) DeleteAlbumsByIds(ids []uint64) error {
+	if err := db.Get().Model(&entity.Song{}).
+		Where("album_id IN ?", ids).
+		Updates(map[string]any{"album_id": nil, "album": "", "track_number": 0}).Error; err != nil {
+		return err
+	}
+	return db.Get().Where("id IN ?", ids).Delete(&entity.Album{}).Error
+}
+
+// UpdateTrack 设置歌曲的专辑与曲目号
+func (r AlbumRepo) UpdateTrack(albumId, songId uint64, title string, discNumber, trackNumber uint) error {
+	return r.conn().Model(&entity.Song{}).
+		Where("id = ?", songId).
+		Updates(map[string]any{
+			"album_id":     albumId,
+			"album":        title,
+			"disc_number":  discNumber,
+			"track_number": trackNumber,
+		}).Error
+}
+
+// ClearTracksExcept 将不在 songIds 中的歌曲移出专辑
+func (r AlbumRepo) ClearTracksExcept(albumId uint64, songIds []uint64) error {
+	query := r.conn().Model(&entity.Song{}).Where("album_id = ?", albumId)
+	if len(songIds) > 0 {
+		query = query.Where("id NOT IN ?", songIds)
+	}
+	return query.Updates(map[string]any{"album_id": nil, "album": "", "track_number": 0}).Error
+}
+
+// CountSongsNotOfArtist 统计不属于该歌手的歌曲数量
+func (r AlbumRepo) CountSongsNotOfArtist(count *int64, artistId uint64, songIds []uint64) error {
+	return db.Get().Model(&entity.Song{}).
+		Where("id IN ? AND artist_id <> ?", songIds, artistId).
+		Count(count).Error
+}
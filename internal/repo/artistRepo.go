@@ -7,12 +7,19 @@ import (
 	"vibe-music-server/internal/pkg/result"
 )
 
-type ArtistRepo struct{}
+type ArtistRepo struct {
+	txConn
+}
 
 func NewArtistRepo() *ArtistRepo {
 	return &ArtistRepo{}
 }
 
+// WithTx 返回在工作单元事务中执行的仓储
+func (a ArtistRepo) WithTx(uow *db.UnitOfWork) *ArtistRepo {
+	return &ArtistRepo{txConn{uow.Tx()}}
+}
+
 func (a ArtistRepo) GetPageArtistsVO(data *result.PageResult[vo.ArtistVO], artistName *string,
 	gender *uint8, area *string, startIndex, pageSize int) error {
 	query := db.Get().Model(&entity.Artist{}).
@@ -85,12 +92,22 @@ func (a ArtistRepo) GetArtistDetail(data *vo.ArtistDetailVO, artistId uint64) er
 		Scan(data).Error; err != nil {
 		return err
 	}
+	if err := db.Get().Table("tb_album al").
+		Select(`al.id AS album_id, al.title, al.artist_id, al.cover_url, al.release_date, al.type`).
+		Where("al.artist_id = ?", artistId).
+		Order("al.release_date DESC, al.id DESC").
+		Scan(&data.Albums).Error; err != nil {
+		return err
+	}
 	// LikeStatus 默认0
-	if err := db.Get().Model(&entity.Song{}).
-		Select(`id song_id, name song_name,
-			album, duration, cover_url, audio_url, release_time`).
-		Where("artist_id = ?", artistId).
-		Order("id desc").
+	// 包含以任意身份署名的歌曲
+	if err := db.Get().Table("tb_song s").
+		Select(`s.id song_id, s.name song_name, s.album, s.duration,
+			s.cover_url, s.release_time, a.name artist_name`).
+		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("s.deleted_at IS NULL").
+		Where(creditArtistIdCond, artistId).
+		Order("s.id desc").
 		Scan(&data.Songs).Error; err != nil {
 		return err
 	}
@@ -119,11 +136,11 @@ func (a ArtistRepo) ExistArtistByName(name string) bool {
 }
 
 func (a ArtistRepo) CreateArtist(artist *entity.Artist) error {
-	return db.Get().Create(artist).Error
+	return a.conn().Create(artist).Error
 }
 
 func (a ArtistRepo) SelectByName(artist *entity.Artist, name string) error {
-	return db.Get().Model(&entity.Artist{}).Where("name = ?", name).First(artist).Error
+	return a.conn().Model(&entity.Artist{}).Where("name = ?", name).First(artist).Error
 }
 
 func (a ArtistRepo) SelectById(artist *entity.Artist, artistId uint64) error {
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"time"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
+)
+
+// 审计快照中不记录的列
+var auditOmitColumns = []string{"password"}
+
+type AuditLogRepo struct{}
+
+func NewAuditLogRepo() *AuditLogRepo {
+	return &AuditLogRepo{}
+}
+
+func (r AuditLogRepo) AddAuditLogs(logs []entity.AuditLog) error {
+	return db.Get().CreateInBatches(logs, 100).Error
+}
+
+// GetSnapshots 取出 table 中 ids 行的全部列, 包括回收站中的行; 表名只来自代码中的定义
+func (r AuditLogRepo) GetSnapshots(rows *[]map[string]any, table string, ids []uint64) error {
+	if err := db.Get().Table(table).Where("id IN ?", ids).Find(rows).Error; err != nil {
+		return err
+	}
+	for _, row := range *rows {
+		for _, column := range auditOmitColumns {
+			delete(row, column)
+		}
+	}
+	return nil
+}
+
+func (r AuditLogRepo) GetAuditLogs(data *result.PageResult[entity.AuditLog], auditLogDTO *dto.AuditLogDTO, index, size int) error {
+	query := auditLogQuery(auditLogDTO)
+	if err := query.Count(&data.Total).Error; err != nil {
+		return err
+	}
+	return query.Order("id DESC").Limit(size).Offset(index).Find(&data.Items).Error
+}
+
+// GetAuditLogsForExport 按查询条件取出最近的至多 limit 条记录
+func (r AuditLogRepo) GetAuditLogsForExport(logs *[]entity.AuditLog, auditLogDTO *dto.AuditLogDTO, limit int) error {
+	return auditLogQuery(auditLogDTO).Order("id DESC").Limit(limit).Find(logs).Error
+}
+
+func auditLogQuery(auditLogDTO *dto.AuditLogDTO) *gorm.DB {
+	query := db.Get().Model(&entity.AuditLog{})
+	if auditLogDTO.AdminID != nil {
+		query = query.Where("admin_id = ?", *auditLogDTO.AdminID)
+	}
+	if auditLogDTO.Action != "" {
+		query = query.Where("action = ?", auditLogDTO.Action)
+	}
+	if auditLogDTO.EntityType != "" {
+		query = query.Where("entity_type = ?", auditLogDTO.EntityType)
+	}
+	if auditLogDTO.EntityID != nil {
+		query = query.Where("FIND_IN_SET(?, entity_ids)", *auditLogDTO.EntityID)
+	}
+	if auditLogDTO.Success != nil {
+		query = query.Where("success = ?", *auditLogDTO.Success)
+	}
+	if !auditLogDTO.StartTime.IsZero() {
+		query = query.Where("create_time >= ?", auditLogDTO.StartTime)
+	}
+	if !auditLogDTO.EndTime.IsZero() {
+		query = query.Where("create_time < ?", auditLogDTO.EndTime.Add(24*time.Hour))
+	}
+	return query
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"vibe-music-server/internal/pkg/db"
+)
+
+// BackupRepo 按表整体读写数据, 供备份与恢复使用; 表名由调用方从固定列表中给出
+type BackupRepo struct {
+	txConn
+}
+
+func NewBackupRepo() *BackupRepo {
+	return &BackupRepo{}
+}
+
+// WithTx 返回在工作单元事务中执行的仓储
+func (r BackupRepo) WithTx(uow *db.UnitOfWork) *BackupRepo {
+	return &BackupRepo{txConn{uow.Tx()}}
+}
+
+// EachRow 逐行读取整张表(包括回收站中的行), 每行为列名到值的映射
+func (r BackupRepo) EachRow(table string, fn func(row map[string]any) error) error {
+	rows, err := r.conn().Table(table).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		row := map[string]any{}
+		if err := r.conn().ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r BackupRepo) CountRows(count *int64, table string) error {
+	return r.conn().Table(table).Count(count).Error
+}
+
+// DeleteRows 删除表中所有行, 外键级联照常生效
+func (r BackupRepo) DeleteRows(table string) error {
+	return r.conn().Exec("DELETE FROM " + table).Error
+}
+
+// InsertRows 按原样写入一批行, 保留原主键
+func (r BackupRepo) InsertRows(table string, rows []map[string]any) error {
+	return r.conn().Table(table).Create(rows).Error
+}
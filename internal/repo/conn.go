@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"vibe-music-server/internal/pkg/db"
+)
+
+// txConn 仓储绑定的连接: 通过 WithTx 绑定工作单元后在其事务中执行, 否则使用默认连接
+type txConn struct {
+	tx *gorm.DB
+}
+
+func (c txConn) conn() *gorm.DB {
+	if c.tx != nil {
+		return c.tx
+	}
+	return db.Get()
+}
@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"time"
+	"vibe-music-server/internal/pkg/db"
+)
+
+// DeletionRepo 按删除策略处理引用行的通用操作, 表名与列名只来自代码中的策略定义
+type DeletionRepo struct {
+	txConn
+}
+
+func NewDeletionRepo() *DeletionRepo {
+	return &DeletionRepo{}
+}
+
+// WithTx 返回在工作单元事务中执行的仓储
+func (r DeletionRepo) WithTx(uow *db.UnitOfWork) *DeletionRepo {
+	return &DeletionRepo{txConn{uow.Tx()}}
+}
+
+// CountRefs 统计 table 中 column 引用了 ids 的行数
+func (r DeletionRepo) CountRefs(count *int64, table, column string, ids []uint64) error {
+	return r.conn().Table(table).Where(column+" IN ?", ids).Count(count).Error
+}
+
+// GetRefIds 取出 table 中 column 引用了 ids 的行的主键
+func (r DeletionRepo) GetRefIds(refIds *[]uint64, table, column string, ids []uint64) error {
+	return r.conn().Table(table).Where(column+" IN ?", ids).Pluck("id", refIds).Error
+}
+
+// GetFileRefs 取出 table 中 column 引用了 ids 的行保存的文件地址与对象名
+func (r DeletionRepo) GetFileRefs(urls, keys *[]string, table, column string, ids []uint64) error {
+	for _, c := range storageURLColumns {
+		if c[0] != table {
+			continue
+		}
+		var values []string
+		if err := r.conn().Table(table).Where(column+" IN ? AND "+c[2]+" <> ''", ids).Pluck(c[2], &values).Error; err != nil {
+			return err
+		}
+		*urls = append(*urls, values...)
+	}
+	for _, c := range storageKeyColumns {
+		if c[0] != table {
+			continue
+		}
+		var values []string
+		if err := r.conn().Table(table).Where(column+" IN ? AND "+c[2]+" <> ''", ids).Pluck(c[2], &values).Error; err != nil {
+			return err
+		}
+		*keys = append(*keys, values...)
+	}
+	return nil
+}
+
+func (r DeletionRepo) DeleteRefs(table, column string, ids []uint64) error {
+	return r.conn().Exec("DELETE FROM "+table+" WHERE "+column+" IN ?", ids).Error
+}
+
+// UpdateRefs 更新 table 中 column 引用了 ids 的行, 用于置空引用
+func (r DeletionRepo) UpdateRefs(table, column string, ids []uint64, updates map[string]any) error {
+	return r.conn().Table(table).Where(column+" IN ?", ids).Updates(updates).Error
+}
+
+// CountLiveRefs 与 CountRefs 相同, 但不统计回收站中的行; table 需有 deleted_at 列
+func (r DeletionRepo) CountLiveRefs(count *int64, table, column string, ids []uint64) error {
+	return r.conn().Table(table).Where(column+" IN ? AND deleted_at IS NULL", ids).Count(count).Error
+}
+
+// CountTrashedParents 统计 table 的 ids 行中, 经 column 引用的 parent 行仍在回收站中的行数
+func (r DeletionRepo) CountTrashedParents(count *int64, table, column, parent string, ids []uint64) error {
+	return r.conn().Table(table+" t").
+		Joins("JOIN "+parent+" p ON p.id = t."+column).
+		Where("t.id IN ? AND p.deleted_at IS NOT NULL", ids).
+		Count(count).Error
+}
+
+// SoftDelete 将 ids 行移入回收站, 已在回收站中的行保留原删除时间
+func (r DeletionRepo) SoftDelete(table string, ids []uint64, deletedAt time.Time) error {
+	return r.conn().Table(table).Where("id IN ? AND deleted_at IS NULL", ids).Update("deleted_at", deletedAt).Error
+}
+
+// Restore 将 ids 行移出回收站
+func (r DeletionRepo) Restore(table string, ids []uint64) error {
+	return r.conn().Table(table).Where("id IN ? AND deleted_at IS NOT NULL", ids).Update("deleted_at", nil).Error
+}
@@ -2,7 +2,9 @@ package repo
 
 import (
 	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
 	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
 )
 
 type FavoriteRepo struct{}
@@ -29,22 +31,132 @@ func (f FavoriteRepo) GetFavoritePlaylistIds(data *[]uint64, userId uint64) erro
 	return query.Error
 }
 
-func (f FavoriteRepo) GetFavoritePlaylists(data *[]entity.Favorite, userId uint64) error {
+// favoriteSongOrders 收藏歌曲可用的排序字段
+var favoriteSongOrders = map[string]string{
+	"createTime": "f.create_time",
+	"songName":   "s.name",
+	"artistName": "a.name",
+}
+
+func (f FavoriteRepo) GetFavoriteSongs(data *result.PageResult[vo.SongVO], userId uint64,
+	songName, artistName, album *string, sortBy, order string, index, size int) error {
+	query := db.Get().Table("tb_user_favorite f").
+		Select(`s.id            AS song_id,
+		        s.name          AS song_name,
+		        s.album,
+		        s.duration,
+		        s.cover_url     AS cover_url,
+		        s.release_time  AS release_time,
+		        a.name          AS artist_name,
+		        1               AS like_status`).
+		Joins("JOIN tb_song s ON s.id = f.song_id").
+		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("f.user_id = ? AND f.type = ? AND s.deleted_at IS NULL", userId, entity.FavoriteTypeSong)
+
+	// 动态条件
+	if songName != nil {
+		query = query.Where("s.name LIKE ?", "%"+*songName+"%")
+	}
+	if artistName != nil {
+		query = query.Where(creditArtistNameCond, "%"+*artistName+"%")
+	}
+	if album != nil {
+		query = query.Where("s.album LIKE ?", "%"+*album+"%")
+	}
+
+	// 总数
+	if err := query.Count(&data.Total).Error; err != nil {
+		return err
+	}
+
+	column, ok := favoriteSongOrders[sortBy]
+	if !ok {
+		column = favoriteSongOrders["createTime"]
+	}
+	if order != "asc" {
+		order = "desc"
+	}
+	// 分页数据, 以收藏 id 兜底保证分页稳定
+	return query.
+		Order(column + " " + order).
+		Order("f.id DESC").
+		Limit(size).
+		Offset(index).
+		Scan(&data.Items).Error
+}
+
+func (f FavoriteRepo) GetFavoriteArtists(data *result.PageResult[vo.ArtistVO], userId uint64,
+	artistName *string, index, size int) error {
+	query := db.Get().Table("tb_user_favorite f").
+		Select("a.id artist_id, a.name artist_name, a.avatar").
+		Joins("JOIN tb_artist a ON a.id = f.artist_id").
+		Where("f.user_id = ? AND f.type = ? AND a.deleted_at IS NULL", userId, entity.FavoriteTypeArtist)
+	if artistName != nil {
+		query = query.Where("a.name LIKE ?", "%"+*artistName+"%")
+	}
+	if err := query.Count(&data.Total).Error; err != nil {
+		return err
+	}
+	return query.
+		Order("f.create_time DESC").
+		Order("f.id DESC").
+		Limit(size).
+		Offset(index).
+		Scan(&data.Items).Error
+}
+
+func (f FavoriteRepo) GetFavoriteAlbums(data *result.PageResult[vo.AlbumVO], userId uint64,
+	title *string, index, size int) error {
+	query := db.Get().Table("tb_user_favorite f").
+		Select(`al.id AS album_id, al.title, al.artist_id, a.name AS artist_name,
+			al.cover_url, al.release_date, al.type`).
+		Joins("JOIN tb_album al ON al.id = f.album_id").
+		Joins("LEFT JOIN tb_artist a ON a.id = al.artist_id").
+		Where("f.user_id = ? AND f.type = ? AND a.deleted_at IS NULL", userId, entity.FavoriteTypeAlbum)
+	if title != nil {
+		query = query.Where("al.title LIKE ?", "%"+*title+"%")
+	}
+	if err := query.Count(&data.Total).Error; err != nil {
+		return err
+	}
+	return query.
+		Order("f.create_time DESC").
+		Order("f.id DESC").
+		Limit(size).
+		Offset(index).
+		Scan(&data.Items).Error
+}
+
+func (f FavoriteRepo) GetFavoriteArtistIds(data *[]uint64, userId uint64) error {
 	query := db.Get().Model(&entity.Favorite{}).
-		Where("user_id = ? AND type = ?", userId, entity.FavoriteTypePlaylist).
-		Order("playlist_id").
+		Select("artist_id").
+		Where("user_id = ? AND type = ?", userId, entity.FavoriteTypeArtist).
+		Order("artist_id").
 		Scan(data)
 	return query.Error
 }
 
-func (f FavoriteRepo) GetFavoriteSongs(data *[]entity.Song, userId uint64) error {
+func (f FavoriteRepo) GetFavoriteAlbumIds(data *[]uint64, userId uint64) error {
 	query := db.Get().Model(&entity.Favorite{}).
-		Where("user_id = ? AND type = ?", userId, entity.FavoriteTypeSong).
-		Order("song_id").
+		Select("album_id").
+		Where("user_id = ? AND type = ?", userId, entity.FavoriteTypeAlbum).
+		Order("album_id").
 		Scan(data)
 	return query.Error
 }
 
+// GetFavoriteIdsIn 返回 ids 中已被用户收藏的 id
+func (f FavoriteRepo) GetFavoriteIdsIn(data *[]uint64, userId uint64, favoriteType entity.FavoriteType, ids []uint64) error {
+	column := favoriteColumn(favoriteType)
+	query := db.Get().Model(&entity.Favorite{}).
+		Distinct(column).
+		Where("user_id = ? AND type = ?", userId, favoriteType).
+		Where(column+" IN ?", ids).
+		Order(column).
+		Pluck(column, data)
+	return query.Error
+}
+
 func (f FavoriteRepo) IsFavoritePlaylist(isFavorite *uint8, userId uint64, playlistId uint64) error {
 	query := db.Get().Model(&entity.Favorite{}).
 		Select("COUNT(1)").
@@ -61,6 +173,22 @@ func (f FavoriteRepo) IsFavoriteSong(isFavorite *uint8, userId uint64, songId ui
 	return query.Error
 }
 
+func (f FavoriteRepo) IsFavoriteArtist(isFavorite *uint8, userId uint64, artistId uint64) error {
+	query := db.Get().Model(&entity.Favorite{}).
+		Select("COUNT(1)").
+		Where("user_id = ? AND artist_id = ? AND type = ?", userId, artistId, entity.FavoriteTypeArtist).
+		Scan(isFavorite)
+	return query.Error
+}
+
+func (f FavoriteRepo) IsFavoriteAlbum(isFavorite *uint8, userId uint64, albumId uint64) error {
+	query := db.Get().Model(&entity.Favorite{}).
+		Select("COUNT(1)").
+		Where("user_id = ? AND album_id = ? AND type = ?", userId, albumId, entity.FavoriteTypeAlbum).
+		Scan(isFavorite)
+	return query.Error
+}
+
 func (f FavoriteRepo) AddFavorite(favorite *entity.Favorite) error {
 	query := db.Get().Create(favorite)
 	return query.Error
@@ -77,3 +205,29 @@ func (f FavoriteRepo) DeleteFavoriteSong(userId uint64, songId uint64) error {
 		Delete(&entity.Favorite{})
 	return query.Error
 }
+
+func (f FavoriteRepo) DeleteFavoriteArtist(userId uint64, artistId uint64) error {
+	query := db.Get().Where("user_id = ? AND artist_id = ? AND type = ?", userId, artistId, entity.FavoriteTypeArtist).
+		Delete(&entity.Favorite{})
+	return query.Error
+}
+
+func (f FavoriteRepo) DeleteFavoriteAlbum(userId uint64, albumId uint64) error {
+	query := db.Get().Where("user_id = ? AND album_id = ? AND type = ?", userId, albumId, entity.FavoriteTypeAlbum).
+		Delete(&entity.Favorite{})
+	return query.Error
+}
+
+// favoriteColumn 收藏类型对应的目标 id 列
+func favoriteColumn(favoriteType entity.FavoriteType) string {
+	switch favoriteType {
+	case entity.FavoriteTypePlaylist:
+		return "playlist_id"
+	case entity.FavoriteTypeArtist:
+		return "artist_id"
+	case entity.FavoriteTypeAlbum:
+		return "album_id"
+	default:
+		return "song_id"
+	}
+}
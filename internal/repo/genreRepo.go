@@ -1,24 +1,67 @@
 package repo
 
 import (
+	"gorm.io/gorm"
 	"vibe-music-server/internal/model/entity"
 	"vibe-music-server/internal/pkg/db"
 )
 
-type GenreRepo struct{}
+type GenreRepo struct {
+	txConn
+}
 
 func NewGenreRepo() *GenreRepo {
 	return &GenreRepo{}
 }
 
-func (r GenreRepo) CreateGenre(genre *entity.Genre) error {
-	return db.Get().Create(&genre).Error
+// WithTx 返回在工作单元事务中执行的仓储
+func (r GenreRepo) WithTx(uow *db.UnitOfWork) *GenreRepo {
+	return &GenreRepo{txConn{uow.Tx()}}
+}
+
+func (r GenreRepo) GetStyleIdsBySongIds(ids *[]uint64, songIds []uint64) error {
+	return r.conn().Model(&entity.Genre{}).
+		Distinct("style_id").
+		Where("song_id IN ?", songIds).
+		Pluck("style_id", ids).Error
 }
 
 func (r GenreRepo) DeleteGenresBySongId(id uint64) error {
-	return db.Get().Where("song_id = ?", id).Delete(&entity.Genre{}).Error
+	return r.conn().Where("song_id = ?", id).Delete(&entity.Genre{}).Error
 }
 
 func (r GenreRepo) DeleteGenresBySongIds(ids []uint64) error {
-	return db.Get().Where("song_id IN ?", ids).Delete(&entity.Genre{}).Error
+	return r.conn().Where("song_id IN ?", ids).Delete(&entity.Genre{}).Error
+}
+
+// ReplaceSongGenres 覆盖歌曲的风格关联, 并同步 tb_song.style
+func (r GenreRepo) ReplaceSongGenres(songId uint64, styleIds []uint64) error {
+	return r.conn().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("song_id = ?", songId).Delete(&entity.Genre{}).Error; err != nil {
+			return err
+		}
+		if len(styleIds) > 0 {
+			genres := make([]entity.Genre, 0, len(styleIds))
+			for _, styleId := range styleIds {
+				genres = append(genres, entity.Genre{SongID: songId, StyleID: styleId})
+			}
+			if err := tx.Create(&genres).Error; err != nil {
+				return err
+			}
+		}
+		return rebuildSongStyles(tx, []uint64{songId})
+	})
+}
+
+// rebuildSongStyles 按关联表重新生成 tb_song.style 冗余字段
+func rebuildSongStyles(tx *gorm.DB, songIds []uint64) error {
+	if len(songIds) == 0 {
+		return nil
+	}
+	return tx.Exec(`UPDATE tb_song s
+		SET s.style = COALESCE((
+			SELECT GROUP_CONCAT(st.name ORDER BY st.id SEPARATOR ',')
+			FROM tb_genre g JOIN tb_style st ON st.id = g.style_id
+			WHERE g.song_id = s.id), '')
+		WHERE s.id IN ?`, songIds).Error
 }
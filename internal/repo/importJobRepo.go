@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"time"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+)
+
+type ImportJobRepo struct{}
+
+func NewImportJobRepo() *ImportJobRepo {
+	return &ImportJobRepo{}
+}
+
+func (r ImportJobRepo) AddImportJob(job *entity.ImportJob) error {
+	return db.Get().Create(job).Error
+}
+
+func (r ImportJobRepo) GetImportJob(job *entity.ImportJob, id string) error {
+	return db.Get().Where("id = ?", id).First(job).Error
+}
+
+func (r ImportJobRepo) UpdateImportJob(id string, fields map[string]any) error {
+	return db.Get().Model(&entity.ImportJob{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// FailUnfinishedJobs 将 before 之前创建、未完成的任务标记为失败, 用于服务重启后
+func (r ImportJobRepo) FailUnfinishedJobs(before time.Time, message string) error {
+	return db.Get().Model(&entity.ImportJob{}).
+		Where("status IN ? AND create_time < ?", []string{entity.ImportPending, entity.ImportRunning}, before).
+		Updates(map[string]any{"status": entity.ImportFailed, "message": message, "finish_time": time.Now()}).Error
+}
@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+)
+
+// 迁移记录表由执行迁移的程序自行创建, 不放在迁移脚本中
+const createMigrationTable = "CREATE TABLE IF NOT EXISTS `tb_schema_migration` (" +
+	"`version` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL COMMENT '迁移脚本文件名'," +
+	"`applied_at` datetime NOT NULL COMMENT '执行时间'," +
+	"PRIMARY KEY (`version`) USING BTREE" +
+	") ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC"
+
+type MigrationRepo struct{}
+
+func NewMigrationRepo() *MigrationRepo {
+	return &MigrationRepo{}
+}
+
+func (r MigrationRepo) EnsureMigrationTable() error {
+	return db.Get().Exec(createMigrationTable).Error
+}
+
+func (r MigrationRepo) GetMigrations(migrations *[]entity.SchemaMigration) error {
+	return db.Get().Order("version").Find(migrations).Error
+}
+
+func (r MigrationRepo) AddMigration(migration *entity.SchemaMigration) error {
+	return db.Get().Create(migration).Error
+}
+
+// ExecStatement 执行迁移脚本中的一条语句; MySQL 的 DDL 会隐式提交, 迁移不在事务中执行
+func (r MigrationRepo) ExecStatement(statement string) error {
+	return db.Get().Exec(statement).Error
+}
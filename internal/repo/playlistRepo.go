@@ -75,10 +75,27 @@ func (p PlaylistRepo) GetPlaylistDetail(data *vo.PlaylistDetailVO, id uint64) er
 		Select("id playlist_id, title, cover_url, introduction").
 		Where("id = ?", id).
 		Scan(data)
-	songQuery := db.Get().Model(&entity.PlaylistBinding{}).
-		Where("playlist_id = ?", id).Scan(&data.Songs)
-	commentQuery := db.Get().Model(&entity.Comment{}).
-		Where("type = ? AND playlist_id = ?", entity.CommentTypePlaylist, id).Scan(&data.Comments)
+	songQuery := db.Get().Table("tb_playlist_binding pb").
+		Select(`s.id            AS song_id,
+		        s.name          AS song_name,
+		        s.album,
+		        s.duration,
+		        s.cover_url     AS cover_url,
+		        s.release_time  AS release_time,
+		        a.name          AS artist_name`).
+		Joins("JOIN tb_song s ON s.id = pb.song_id").
+		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("pb.playlist_id = ? AND s.deleted_at IS NULL", id).Scan(&data.Songs)
+	// 已移入回收站的用户的评论不展示
+	commentQuery := db.Get().Table("tb_comment c").
+		Select(`c.id            AS comment_id,
+		        u.username,
+		        u.user_avatar,
+		        c.content,
+		        c.create_time,
+		        c.like_count`).
+		Joins("JOIN tb_user u ON u.id = c.user_id").
+		Where("c.type = ? AND c.playlist_id = ? AND u.deleted_at IS NULL", entity.CommentTypePlaylist, id).Scan(&data.Comments)
 	switch {
 	case query.Error != nil:
 		return query.Error
@@ -155,7 +172,7 @@ func (p PlaylistRepo) GetAllPlaylistsByIds(data *result.PageResult[vo.PlaylistVO
 		Joins("LEFT JOIN tb_user_favorite u ON p.id = u.playlist_id AND u.user_id = ?", userId)
 
 	// WHERE p.id IN (...)
-	query = query.Where("p.id IN ?", ids)
+	query = query.Where("p.id IN ? AND p.deleted_at IS NULL", ids)
 
 	// 模糊搜索 title
 	if title != nil && *title != "" {
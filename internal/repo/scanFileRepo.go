@@ -0,0 +1,33 @@
+package repo
+
+import (
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+)
+
+type ScanFileRepo struct {
+	txConn
+}
+
+func NewScanFileRepo() *ScanFileRepo {
+	return &ScanFileRepo{}
+}
+
+// WithTx 返回在工作单元事务中执行的仓储
+func (r ScanFileRepo) WithTx(uow *db.UnitOfWork) *ScanFileRepo {
+	return &ScanFileRepo{txConn{uow.Tx()}}
+}
+
+func (r ScanFileRepo) GetScanFileByPath(file *entity.ScanFile, path string) error {
+	return r.conn().Where("path = ?", path).First(file).Error
+}
+
+// GetScanFilesBySha256 内容相同的已扫描文件, 用于识别移动或改名的文件
+func (r ScanFileRepo) GetScanFilesBySha256(files *[]entity.ScanFile, sha256 string) error {
+	return r.conn().Where("sha256 = ?", sha256).Order("id").Find(files).Error
+}
+
+// SaveScanFile 新增或按 id 更新记录
+func (r ScanFileRepo) SaveScanFile(file *entity.ScanFile) error {
+	return r.conn().Save(file).Error
+}
@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+)
+
+type SearchRepo struct{}
+
+func NewSearchRepo() *SearchRepo {
+	return &SearchRepo{}
+}
+
+// ids 为空时返回全部
+func byIds(query *gorm.DB, column string, ids []uint64) *gorm.DB {
+	if len(ids) > 0 {
+		query = query.Where(column+" IN ?", ids)
+	}
+	return query
+}
+
+func (r SearchRepo) GetSongDocs(data *[]vo.SearchDocVO, ids []uint64) error {
+	return byIds(r.songDocs(), "s.id", ids).Scan(data).Error
+}
+
+func (r SearchRepo) GetSongDocsByArtists(data *[]vo.SearchDocVO, artistIds []uint64) error {
+	return r.songDocs().Where("s.artist_id IN ?", artistIds).Scan(data).Error
+}
+
+func (r SearchRepo) songDocs() *gorm.DB {
+	return db.Get().Table("tb_song s").
+		Select(`s.id, s.name AS title, a.name AS subtitle, s.cover_url, s.artist_id,
+			(SELECT COUNT(1) FROM tb_user_favorite f WHERE f.type = ? AND f.song_id = s.id) AS popularity`,
+			entity.FavoriteTypeSong).
+		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("s.deleted_at IS NULL")
+}
+
+func (r SearchRepo) GetArtistDocs(data *[]vo.SearchDocVO, ids []uint64) error {
+	query := db.Get().Table("tb_artist a").
+		Select(`a.id, a.name AS title, a.avatar AS cover_url, a.id AS artist_id,
+			(SELECT COUNT(1) FROM tb_user_favorite f WHERE f.type = ? AND f.artist_id = a.id) AS popularity`,
+			entity.FavoriteTypeArtist).
+		Where("a.deleted_at IS NULL")
+	return byIds(query, "a.id", ids).Scan(data).Error
+}
+
+func (r SearchRepo) GetAlbumDocs(data *[]vo.SearchDocVO, ids []uint64) error {
+	return byIds(r.albumDocs(), "al.id", ids).Scan(data).Error
+}
+
+func (r SearchRepo) GetAlbumDocsByArtists(data *[]vo.SearchDocVO, artistIds []uint64) error {
+	return r.albumDocs().Where("al.artist_id IN ?", artistIds).Scan(data).Error
+}
+
+func (r SearchRepo) albumDocs() *gorm.DB {
+	return db.Get().Table("tb_album al").
+		Select(`al.id, al.title, a.name AS subtitle, al.cover_url, al.artist_id,
+			(SELECT COUNT(1) FROM tb_user_favorite f WHERE f.type = ? AND f.album_id = al.id) AS popularity`,
+			entity.FavoriteTypeAlbum).
+		Joins("LEFT JOIN tb_artist a ON a.id = al.artist_id").
+		Where("a.deleted_at IS NULL")
+}
+
+func (r SearchRepo) GetPlaylistDocs(data *[]vo.SearchDocVO, ids []uint64) error {
+	query := db.Get().Table("tb_playlist p").
+		Select(`p.id, p.title, p.style AS subtitle, p.cover_url,
+			(SELECT COUNT(1) FROM tb_user_favorite f WHERE f.type = ? AND f.playlist_id = p.id) AS popularity`,
+			entity.FavoriteTypePlaylist).
+		Where("p.deleted_at IS NULL")
+	return byIds(query, "p.id", ids).Scan(data).Error
+}
+
+// IncrSearchQueries 累加当天的搜索词次数
+func (r SearchRepo) IncrSearchQueries(counts map[string]int, day time.Time) error {
+	rows := make([]entity.SearchQuery, 0, len(counts))
+	for q, n := range counts {
+		rows = append(rows, entity.SearchQuery{Day: day, Query: q, Count: uint64(n)})
+	}
+	return db.Get().Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{"count": gorm.Expr("count + VALUES(count)")}),
+	}).CreateInBatches(rows, 500).Error
+}
+
+func (r SearchRepo) GetTrendingQueries(data *[]vo.TrendingQueryVO, since time.Time, limit int) error {
+	return db.Get().Model(&entity.SearchQuery{}).
+		Select("query, SUM(count) AS count").
+		Where("day >= ?", since).
+		Group("query").
+		Order("count DESC").
+		Limit(limit).
+		Scan(data).Error
+}
+
+func (r SearchRepo) DeleteSearchQueriesBefore(day time.Time) error {
+	return db.Get().Where("day < ?", day).Delete(&entity.SearchQuery{}).Error
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+)
+
+// 按署名筛选歌曲的子查询, 要求主表别名为 s
+const (
+	creditArtistNameCond = "EXISTS (SELECT 1 FROM tb_song_artist sa JOIN tb_artist ca ON ca.id = sa.artist_id WHERE sa.song_id = s.id AND ca.deleted_at IS NULL AND ca.name LIKE ?)"
+	creditArtistIdCond   = "EXISTS (SELECT 1 FROM tb_song_artist sa WHERE sa.song_id = s.id AND sa.artist_id = ?)"
+)
+
+type SongArtistRepo struct {
+	txConn
+}
+
+func NewSongArtistRepo() *SongArtistRepo {
+	return &SongArtistRepo{}
+}
+
+// WithTx 返回在工作单元事务中执行的仓储
+func (r SongArtistRepo) WithTx(uow *db.UnitOfWork) *SongArtistRepo {
+	return &SongArtistRepo{txConn{uow.Tx()}}
+}
+
+func (r SongArtistRepo) GetCreditsBySongIds(data *[]vo.SongArtistVO, songIds []uint64) error {
+	return r.conn().Table("tb_song_artist sa").
+		Select(`sa.song_id,
+		        sa.artist_id,
+		        a.name          AS artist_name,
+		        sa.role,
+		        sa.sort`).
+		Joins("JOIN tb_artist a ON a.id = sa.artist_id").
+		Where("sa.song_id IN ? AND a.deleted_at IS NULL", songIds).
+		Order("sa.song_id, sa.sort, sa.role").
+		Scan(data).Error
+}
+
+// ReplaceCredits 覆盖歌曲的全部署名
+func (r SongArtistRepo) ReplaceCredits(songId uint64, credits []entity.SongArtist) error {
+	if err := r.conn().Where("song_id = ?", songId).Delete(&entity.SongArtist{}).Error; err != nil {
+		return err
+	}
+	if len(credits) == 0 {
+		return nil
+	}
+	return r.conn().Create(&credits).Error
+}
+
+func (r SongArtistRepo) CountExistArtists(count *int64, artistIds []uint64) error {
+	return r.conn().Model(&entity.Artist{}).Where("id IN ?", artistIds).Count(count).Error
+}
+
+func (r SongArtistRepo) DeleteCreditsBySongIds(ids []uint64) error {
+	return r.conn().Where("song_id IN ?", ids).Delete(&entity.SongArtist{}).Error
+}
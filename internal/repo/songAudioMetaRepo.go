@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+)
+
+type SongAudioMetaRepo struct {
+	txConn
+}
+
+func NewSongAudioMetaRepo() *SongAudioMetaRepo {
+	return &SongAudioMetaRepo{}
+}
+
+// WithTx 返回在工作单元事务中执行的仓储
+func (r SongAudioMetaRepo) WithTx(uow *db.UnitOfWork) *SongAudioMetaRepo {
+	return &SongAudioMetaRepo{txConn{uow.Tx()}}
+}
+
+// SaveAudioMeta 每首歌只保留最近一次提取的结果
+func (r SongAudioMetaRepo) SaveAudioMeta(meta *entity.SongAudioMeta) error {
+	return r.conn().Save(meta).Error
+}
+
+func (r SongAudioMetaRepo) GetAudioMeta(meta *entity.SongAudioMeta, songId uint64) error {
+	return r.conn().First(meta, songId).Error
+}
+
+func (r SongAudioMetaRepo) DeleteAudioMeta(songId uint64) error {
+	return r.conn().Delete(&entity.SongAudioMeta{}, songId).Error
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+)
+
+type SongRenditionRepo struct {
+	txConn
+}
+
+func NewSongRenditionRepo() *SongRenditionRepo {
+	return &SongRenditionRepo{}
+}
+
+// WithTx 返回在工作单元事务中执行的仓储
+func (r SongRenditionRepo) WithTx(uow *db.UnitOfWork) *SongRenditionRepo {
+	return &SongRenditionRepo{txConn{uow.Tx()}}
+}
+
+func (r SongRenditionRepo) GetRenditionsBySongId(renditions *[]entity.SongRendition, songId uint64) error {
+	return r.conn().Where("song_id = ?", songId).Order("id").Find(renditions).Error
+}
+
+func (r SongRenditionRepo) GetRenditionById(rendition *entity.SongRendition, id uint64) error {
+	return r.conn().Where("id = ?", id).Find(rendition).Error
+}
+
+func (r SongRenditionRepo) GetRendition(rendition *entity.SongRendition, songId uint64, quality string) error {
+	return r.conn().Where("song_id = ? AND quality = ?", songId, quality).Find(rendition).Error
+}
+
+// SaveRendition ID 为 0 时新增, 否则覆盖
+func (r SongRenditionRepo) SaveRendition(rendition *entity.SongRendition) error {
+	return r.conn().Save(rendition).Error
+}
+
+func (r SongRenditionRepo) DeleteRenditionById(id uint64) error {
+	return r.conn().Where("id = ?", id).Delete(&entity.SongRendition{}).Error
+}
+
+// GetObjectKeysBySongIds 删除歌曲前取出各音质文件, 记录本身随歌曲级联删除
+func (r SongRenditionRepo) GetObjectKeysBySongIds(keys *[]string, songIds []uint64) error {
+	return r.conn().Model(&entity.SongRendition{}).Where("song_id IN ?", songIds).Pluck("object_key", keys).Error
+}
@@ -7,35 +7,51 @@ import (
 	"vibe-music-server/internal/pkg/result"
 )
 
-type SongRepo struct{}
+// 通过风格关联表筛选歌曲的子查询, 要求主表别名为 s
+const (
+	songStyleIdsCond  = "EXISTS (SELECT 1 FROM tb_genre g WHERE g.song_id = s.id AND g.style_id IN ?)"
+	songStyleNameCond = "EXISTS (SELECT 1 FROM tb_genre g JOIN tb_style st ON st.id = g.style_id WHERE g.song_id = s.id AND st.name = ?)"
+)
+
+type SongRepo struct {
+	txConn
+}
 
 func NewSongRepo() *SongRepo {
 	return &SongRepo{}
 }
 
+// WithTx 返回在工作单元事务中执行的仓储
+func (r SongRepo) WithTx(uow *db.UnitOfWork) *SongRepo {
+	return &SongRepo{txConn{uow.Tx()}}
+}
+
 func (r SongRepo) GetAllSongs(data *result.PageResult[vo.SongVO], index, size int,
-	songName, artistName, album *string) error {
-	query := db.Get().Table("tb_song s").
+	songName, artistName, album, style *string) error {
+	query := r.conn().Table("tb_song s").
 		Select(`s.id            AS song_id,
 		        s.name          AS song_name,
 		        s.album,
 		        s.duration,
 		        s.cover_url     AS cover_url,
-		        s.audio_url     AS audio_url,
 		        s.release_time  AS release_time,
 		        a.name          AS artist_name`).
-		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id")
+		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("s.deleted_at IS NULL")
 
 	// 动态条件
 	if songName != nil {
 		query = query.Where("s.name LIKE ?", "%"+*songName+"%")
 	}
 	if artistName != nil {
-		query = query.Where("a.name LIKE ?", "%"+*artistName+"%")
+		query = query.Where(creditArtistNameCond, "%"+*artistName+"%")
 	}
 	if album != nil {
 		query = query.Where("s.album LIKE ?", "%"+*album+"%")
 	}
+	if style != nil {
+		query = query.Where(songStyleNameCond, *style)
+	}
 
 	// 总数
 	if err := query.Count(&data.Total).Error; err != nil {
@@ -55,23 +71,23 @@ func (r SongRepo) GetAllSongs(data *result.PageResult[vo.SongVO], index, size in
 
 func (r SongRepo) GetAllSongsByIds(data *result.PageResult[vo.SongVO], ids []uint64, index, size int,
 	songName, artistName, album *string) error {
-	query := db.Get().Table("tb_song s").
+	query := r.conn().Table("tb_song s").
 		Select(`s.id            AS song_id,
 		        s.name          AS song_name,
 		        s.album,
 		        s.duration,
 		        s.cover_url     AS cover_url,
-		        s.audio_url     AS audio_url,
 		        s.release_time  AS release_time,
 		        a.name          AS artist_name`).
-		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id")
+		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("s.deleted_at IS NULL")
 
 	// 动态条件
 	if songName != nil {
 		query = query.Where("s.name LIKE ?", "%"+*songName+"%")
 	}
 	if artistName != nil {
-		query = query.Where("a.name LIKE ?", "%"+*artistName+"%")
+		query = query.Where(creditArtistNameCond, "%"+*artistName+"%")
 	}
 	if album != nil {
 		query = query.Where("s.album LIKE ?", "%"+*album+"%")
@@ -96,7 +112,7 @@ func (r SongRepo) GetAllSongsByIds(data *result.PageResult[vo.SongVO], ids []uin
 }
 
 func (r SongRepo) GetAllSongsByArtist(data *result.PageResult[vo.SongAdminVO], name *string, album *string, id *uint64, index int, size int) error {
-	query := db.Get().Table("tb_song s").
+	query := r.conn().Table("tb_song s").
 		Select(`s.id            AS song_id,
 		        s.name          AS song_name,
 		        s.album,
@@ -106,16 +122,17 @@ func (r SongRepo) GetAllSongsByArtist(data *result.PageResult[vo.SongAdminVO], n
 		        s.audio_url     AS audio_url,
 		        s.release_time  AS release_time,
 		        a.name          AS artist_name`).
-		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id")
+		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("s.deleted_at IS NULL")
 	// 动态条件
 	if name != nil {
-		query = query.Where("a.name LIKE ?", "%"+*name+"%")
+		query = query.Where(creditArtistNameCond, "%"+*name+"%")
 	}
 	if album != nil {
 		query = query.Where("s.album LIKE ?", "%"+*album+"%")
 	}
 	if id != nil {
-		query = query.Where("a.id = ?", *id)
+		query = query.Where(creditArtistIdCond, *id)
 	}
 	// 总数
 	if err := query.Count(&data.Total).Error; err != nil {
@@ -132,42 +149,34 @@ func (r SongRepo) GetAllSongsByArtist(data *result.PageResult[vo.SongAdminVO], n
 }
 
 func (r SongRepo) GetRandomSongs(data *[]vo.SongVO, limit int) error {
-	query := db.Get().Table("tb_song s").
+	query := r.conn().Table("tb_song s").
 		Select(`s.id            AS song_id,
 		        s.name          AS song_name,
 		        s.album,
 		        s.duration,
 		        s.cover_url     AS cover_url,
-		        s.audio_url     AS audio_url,
 		        s.release_time  AS release_time,
 		        a.name          AS artist_name`).
 		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
+		Where("s.deleted_at IS NULL").
 		Order("RAND()").
 		Limit(limit).
 		Scan(data)
 	return query.Error
 }
 
-func (r SongRepo) GetStylesByIds(data *[]string, ids []uint64) error {
-	query := db.Get().Table("tb_song s").
-		Select("s.style").
-		Where("s.id IN ?", ids).
-		Pluck("s.style", data)
-	return query.Error
-}
-
-func (r SongRepo) GetRecommendedSongsByStyles(data *[]vo.SongVO, styles []string, ids []uint64, limit int) error {
-	query := db.Get().Table("tb_song s").
+func (r SongRepo) GetRecommendedSongsByStyleIds(data *[]vo.SongVO, styleIds []uint64, ids []uint64, limit int) error {
+	query := r.conn().Table("tb_song s").
 		Select(`s.id            AS song_id,
 		        s.name          AS song_name,
 		        s.album,
 		        s.duration,
 		        s.cover_url     AS cover_url,
-		        s.audio_url     AS audio_url,
 		        s.release_time  AS release_time,
 		        a.name          AS artist_name`).
 		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
-		Where("s.style IN ?", styles).
+		Where("s.deleted_at IS NULL").
+		Where(songStyleIdsCond, styleIds).
 		Where("s.id NOT IN ?", ids).
 		Order("RAND()").
 		Limit(limit).
@@ -176,20 +185,29 @@ func (r SongRepo) GetRecommendedSongsByStyles(data *[]vo.SongVO, styles []string
 }
 
 func (r SongRepo) GetSongDetail(data *vo.SongDetailVO, id uint64) error {
-	commentQuery := db.Get().Model(&entity.Comment{}).
-		Where("type = ? AND playlist_id = ?", entity.CommentTypePlaylist, id).Scan(&data.Comments)
-	query := db.Get().Table("tb_song s").
+	// 已移入回收站的用户的评论不展示
+	commentQuery := r.conn().Table("tb_comment c").
+		Select(`c.id            AS comment_id,
+		        u.username,
+		        u.user_avatar,
+		        c.content,
+		        c.create_time,
+		        c.like_count`).
+		Joins("JOIN tb_user u ON u.id = c.user_id").
+		Where("c.type = ? AND c.song_id = ? AND u.deleted_at IS NULL", entity.CommentTypeSong, id).Scan(&data.Comments)
+	query := r.conn().Table("tb_song s").
 		Select(`s.id            AS song_id,
 		        s.name          AS song_name,
 		        s.album,
+		        s.lyric,
+		        s.lyric_translation AS translation,
 		        s.duration,
 				s.style,
 		        s.cover_url     AS cover_url,
-		        s.audio_url     AS audio_url,
 		        s.release_time  AS release_time,
 		        a.name          AS artist_name`).
 		Joins("LEFT JOIN tb_artist a ON a.id = s.artist_id").
-		Where("s.id = ?", id).
+		Where("s.id = ? AND s.deleted_at IS NULL", id).
 		Scan(data)
 	switch {
 	case query.Error != nil:
@@ -200,38 +218,77 @@ func (r SongRepo) GetSongDetail(data *vo.SongDetailVO, id uint64) error {
 	return nil
 }
 
-func (r SongRepo) GetAllSongsCount(count *int64, s *string) error {
-	return db.Get().Model(&entity.Song{}).Where("name LIKE ?", "%"+*s+"%").Count(count).Error
+func (r SongRepo) GetAllSongsCount(count *int64, style *string) error {
+	query := r.conn().Table("tb_song s").Where("s.deleted_at IS NULL")
+	if style != nil {
+		query = query.Where(songStyleNameCond, *style)
+	}
+	return query.Count(count).Error
+}
+
+// ExistSongByName 歌手名下是否已有同名歌曲, 不含回收站中的歌曲
+func (r SongRepo) ExistSongByName(artistId uint64, name string) (bool, error) {
+	var count int64
+	err := r.conn().Model(&entity.Song{}).Where("artist_id = ? AND name = ?", artistId, name).Count(&count).Error
+	return count > 0, err
 }
 
 func (r SongRepo) CreateSong(song *entity.Song) error {
-	return db.Get().Create(song).Error
+	return r.conn().Create(song).Error
 }
 
 func (r SongRepo) GetSongById(song *entity.Song, id uint64) error {
-	return db.Get().First(song, id).Error
+	return r.conn().First(song, id).Error
 }
 
 func (r SongRepo) UpdateSong(song *entity.Song) error {
-	return db.Get().Model(song).Updates(song).Error
+	return r.conn().Model(song).Updates(song).Error
+}
+
+func (r SongRepo) UpdateSongFields(id uint64, fields map[string]any) error {
+	return r.conn().Model(&entity.Song{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r SongRepo) UpdateSongAlbum(id uint64, albumId *uint64, album string, discNumber, trackNumber uint) error {
+	return r.conn().Model(&entity.Song{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"album_id":     albumId,
+			"album":        album,
+			"disc_number":  discNumber,
+			"track_number": trackNumber,
+		}).Error
+}
+
+func (r SongRepo) GetSongLyric(song *entity.Song, id uint64) error {
+	return r.conn().Select("id", "lyric", "lyric_translation").First(song, id).Error
+}
+
+func (r SongRepo) UpdateSongLyric(id uint64, lyric, translation string) error {
+	return r.conn().Model(&entity.Song{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"lyric":             lyric,
+			"lyric_translation": translation,
+		}).Error
 }
 
 func (r SongRepo) DeleteSongById(id uint64) error {
-	return db.Get().Delete(&entity.Song{}, id).Error
+	return r.conn().Delete(&entity.Song{}, id).Error
 }
 
 func (r SongRepo) DeleteSongByIds(ids []uint64) error {
-	return db.Get().Where("id IN ?", ids).Delete(&entity.Song{}).Error
+	return r.conn().Where("id IN ?", ids).Delete(&entity.Song{}).Error
 }
 
 func (r SongRepo) GetCoversByIds(covers *[]string, ids []uint64) error {
-	return db.Get().Model(&entity.Song{}).
+	return r.conn().Model(&entity.Song{}).
 		Where("id IN ?", ids).
 		Pluck("cover_url", covers).Error
 }
 
 func (r SongRepo) GetAudiosByIds(urls *[]string, ids []uint64) error {
-	return db.Get().Model(&entity.Song{}).
+	return r.conn().Model(&entity.Song{}).
 		Where("id IN ?", ids).
 		Pluck("audio_url", urls).Error
 }
@@ -0,0 +1,111 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+)
+
+// statPeriods 各粒度下统计日期所属周期的第一天; 周从周一开始
+var statPeriods = map[string]string{
+	"day":   "stat_date",
+	"week":  "DATE_SUB(stat_date, INTERVAL WEEKDAY(stat_date) DAY)",
+	"month": "DATE_SUB(stat_date, INTERVAL DAYOFMONTH(stat_date) - 1 DAY)",
+}
+
+// statSources 各指标按天聚合的来源, 统计 [start, end) 内的行; 用户包括回收站中的用户
+var statSources = map[string]string{
+	entity.MetricNewUsers: `SELECT DATE(create_time), ?, 0, COUNT(1) FROM tb_user
+		WHERE create_time >= ? AND create_time < ? GROUP BY DATE(create_time)`,
+	entity.MetricPlays: `SELECT DATE(create_time), ?, 0, COUNT(1) FROM tb_play_log
+		WHERE create_time >= ? AND create_time < ? GROUP BY DATE(create_time)`,
+	entity.MetricFavorites: `SELECT DATE(create_time), ?, 0, COUNT(1) FROM tb_user_favorite
+		WHERE create_time >= ? AND create_time < ? GROUP BY DATE(create_time)`,
+	entity.MetricStylePlays: `SELECT DATE(p.create_time), ?, g.style_id, COUNT(1) FROM tb_play_log p
+		JOIN tb_genre g ON g.song_id = p.song_id
+		WHERE p.create_time >= ? AND p.create_time < ? GROUP BY DATE(p.create_time), g.style_id`,
+}
+
+// StatPoint 按周期汇总的统计值
+type StatPoint struct {
+	Period time.Time
+	Value  int64
+}
+
+type StatRepo struct {
+	txConn
+}
+
+func NewStatRepo() *StatRepo {
+	return &StatRepo{}
+}
+
+// WithTx 返回在工作单元事务中执行的仓储
+func (r StatRepo) WithTx(uow *db.UnitOfWork) *StatRepo {
+	return &StatRepo{txConn{uow.Tx()}}
+}
+
+func (r StatRepo) AddPlayLogs(logs []entity.PlayLog) error {
+	return r.conn().CreateInBatches(logs, 100).Error
+}
+
+// RebuildDailyStats 由源表重算 [start, end) 内各天的统计, 应在事务中调用
+func (r StatRepo) RebuildDailyStats(start, end time.Time) error {
+	if err := r.conn().Where("stat_date >= ? AND stat_date < ?", start, end).Delete(&entity.DailyStat{}).Error; err != nil {
+		return err
+	}
+	for metric, source := range statSources {
+		if err := r.conn().Exec("INSERT INTO tb_daily_stat (stat_date, metric, dim_id, value) "+source, metric, start, end).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLatestStatDate 已统计的最后一天, 尚无统计时为空
+func (r StatRepo) GetLatestStatDate(date *sql.NullTime) error {
+	return r.conn().Raw("SELECT MAX(stat_date) FROM tb_daily_stat").Scan(date).Error
+}
+
+// GetFirstActivityTime 各统计来源中最早一行的时间, 没有任何数据时为空
+func (r StatRepo) GetFirstActivityTime(t *sql.NullTime) error {
+	return r.conn().Raw(`SELECT MIN(t) FROM (
+		SELECT MIN(create_time) AS t FROM tb_user
+		UNION ALL SELECT MIN(create_time) FROM tb_user_favorite
+		UNION ALL SELECT MIN(create_time) FROM tb_play_log) s`).Scan(t).Error
+}
+
+// GetStatSeries 按粒度汇总 [start, end] 内某指标的值, 没有数据的周期不返回
+func (r StatRepo) GetStatSeries(data *[]StatPoint, metric, granularity string, start, end time.Time) error {
+	period := statPeriods[granularity]
+	return r.conn().Raw("SELECT "+period+" AS period, SUM(value) AS value FROM tb_daily_stat "+
+		"WHERE metric = ? AND dim_id = 0 AND stat_date BETWEEN ? AND ? GROUP BY period ORDER BY period",
+		metric, start, end).Scan(data).Error
+}
+
+// GetTopStyles [start, end] 内播放最多的 limit 个风格
+func (r StatRepo) GetTopStyles(data *[]vo.DashboardStyleVO, start, end time.Time, limit int) error {
+	return r.conn().Raw(`SELECT st.id AS style_id, st.name, SUM(d.value) AS plays
+		FROM tb_daily_stat d JOIN tb_style st ON st.id = d.dim_id
+		WHERE d.metric = ? AND d.stat_date BETWEEN ? AND ?
+		GROUP BY st.id, st.name ORDER BY plays DESC, st.id LIMIT ?`,
+		entity.MetricStylePlays, start, end, limit).Scan(data).Error
+}
+
+// GetTotals 各类实体的当前总数; 专辑随歌手进入回收站
+func (r StatRepo) GetTotals(data *vo.DashboardTotalsVO) error {
+	return r.conn().Raw(`SELECT
+		(SELECT COUNT(1) FROM tb_user WHERE deleted_at IS NULL) AS users,
+		(SELECT COUNT(1) FROM tb_artist WHERE deleted_at IS NULL) AS artists,
+		(SELECT COUNT(1) FROM tb_song WHERE deleted_at IS NULL) AS songs,
+		(SELECT COUNT(1) FROM tb_album al JOIN tb_artist a ON a.id = al.artist_id WHERE a.deleted_at IS NULL) AS albums,
+		(SELECT COUNT(1) FROM tb_playlist WHERE deleted_at IS NULL) AS playlists`).Scan(data).Error
+}
+
+// GetStorageUsage 按上传策略汇总存储对象的数量与大小
+func (r StatRepo) GetStorageUsage(data *[]vo.DashboardStorageVO) error {
+	return r.conn().Raw(`SELECT folder, COUNT(1) AS objects, SUM(size) AS bytes, SUM(ref_count) AS refs
+		FROM tb_storage_object GROUP BY folder ORDER BY bytes DESC`).Scan(data).Error
+}
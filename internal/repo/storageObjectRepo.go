@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+)
+
+type StorageObjectRepo struct{}
+
+func NewStorageObjectRepo() *StorageObjectRepo {
+	return &StorageObjectRepo{}
+}
+
+// AcquireStorageObject 按内容查找已存储的对象, 找到则引用计数加一; 未找到时 obj.ObjectKey 为空
+func (r StorageObjectRepo) AcquireStorageObject(obj *entity.StorageObject, folder, sha256 string) error {
+	return db.Get().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("folder = ? AND sha256 = ?", folder, sha256).Find(obj).Error; err != nil {
+			return err
+		}
+		if obj.ObjectKey == "" {
+			return nil
+		}
+		obj.RefCount++
+		return tx.Model(obj).Updates(map[string]any{
+			"ref_count":   gorm.Expr("ref_count + 1"),
+			"update_time": time.Now(),
+		}).Error
+	})
+}
+
+// AddStorageObject 登记新存储的对象; 并发上传相同内容时已被先登记的一方占用则返回 false
+func (r StorageObjectRepo) AddStorageObject(obj *entity.StorageObject) (bool, error) {
+	res := db.Get().Clauses(clause.OnConflict{DoNothing: true}).Create(obj)
+	return res.RowsAffected > 0, res.Error
+}
+
+// ReleaseStorageObject 引用计数减一, 归零时删除记录并在同一事务内调用 remove 删除对象, remove 失败则回滚;
+// 没有记录(按内容寻址之前上传)时返回 false
+func (r StorageObjectRepo) ReleaseStorageObject(objectKey string, remove func() error) (bool, error) {
+	found := false
+	err := db.Get().Transaction(func(tx *gorm.DB) error {
+		var obj entity.StorageObject
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("object_key = ?", objectKey).Find(&obj).Error; err != nil {
+			return err
+		}
+		if obj.ObjectKey == "" {
+			return nil
+		}
+		found = true
+		if obj.RefCount > 1 {
+			return tx.Model(&obj).Updates(map[string]any{
+				"ref_count":   gorm.Expr("ref_count - 1"),
+				"update_time": time.Now(),
+			}).Error
+		}
+		if err := tx.Delete(&obj).Error; err != nil {
+			return err
+		}
+		return remove()
+	})
+	return found, err
+}
+
+func (r StorageObjectRepo) GetStorageObjects(objs *[]entity.StorageObject) error {
+	return db.Get().Find(objs).Error
+}
+
+// RaiseRefCount 引用计数只增不减地修正到 count, 计数偏小会导致对象被提前删除
+func (r StorageObjectRepo) RaiseRefCount(objectKey string, count int) error {
+	return db.Get().Model(&entity.StorageObject{}).
+		Where("object_key = ? AND ref_count < ?", objectKey, count).
+		Updates(map[string]any{"ref_count": count, "update_time": time.Now()}).Error
+}
+
+func (r StorageObjectRepo) DeleteStorageObject(objectKey string) error {
+	return db.Get().Where("object_key = ?", objectKey).Delete(&entity.StorageObject{}).Error
+}
+
+// CountLiveRefs 统计数据库中仍引用对象的行数(包括回收站中的行), 用于没有引用计数记录的对象.
+// 地址列按以对象名结尾匹配(对象名中的 _ 也作通配), 宁可多算也不误删
+func (r StorageObjectRepo) CountLiveRefs(count *int64, objectKey string) error {
+	pattern := "%/" + objectKey
+	selects := make([]string, 0, len(storageURLColumns)+len(storageKeyColumns))
+	args := make([]any, 0, cap(selects))
+	for _, c := range storageURLColumns {
+		selects = append(selects, "SELECT COUNT(*) AS n FROM "+c[0]+" WHERE "+c[2]+" LIKE ?")
+		args = append(args, pattern)
+	}
+	for _, c := range storageKeyColumns {
+		selects = append(selects, "SELECT COUNT(*) AS n FROM "+c[0]+" WHERE "+c[2]+" = ?")
+		args = append(args, objectKey)
+	}
+	return db.Get().Raw("SELECT COALESCE(SUM(n), 0) FROM ("+strings.Join(selects, " UNION ALL ")+") t", args...).
+		Scan(count).Error
+}
+
+func (r StorageObjectRepo) GetStorageObjectsByKeys(objs *[]entity.StorageObject, objectKeys []string) error {
+	return db.Get().Where("object_key IN ?", objectKeys).Find(objs).Error
+}
+
+// UpdateRenditions 补记对象的缩略图
+func (r StorageObjectRepo) UpdateRenditions(objectKey, renditions, ext string) error {
+	return db.Get().Model(&entity.StorageObject{}).
+		Where("object_key = ?", objectKey).
+		Updates(map[string]any{"renditions": renditions, "rendition_ext": ext}).Error
+}
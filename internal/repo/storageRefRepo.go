@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"strings"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+)
+
+// 保存文件访问地址的列: 表, 主键列, 地址列. tb_song_audio_meta.audio_url 只记录提取来源, 不单独占用引用
+var storageURLColumns = [][3]string{
+	{"tb_song", "id", "cover_url"},
+	{"tb_song", "id", "audio_url"},
+	{"tb_artist", "id", "avatar"},
+	{"tb_album", "id", "cover_url"},
+	{"tb_playlist", "id", "cover_url"},
+	{"tb_user", "id", "user_avatar"},
+	{"tb_banner", "id", "banner_url"},
+	{"tb_song_audio_meta", "song_id", "cover_url"},
+}
+
+// 直接保存对象名的列: 表, 主键列, 对象名列
+var storageKeyColumns = [][3]string{
+	{"tb_song_rendition", "id", "object_key"},
+}
+
+// StorageURLColumns 表中保存文件访问地址的列
+func StorageURLColumns(table string) []string {
+	var columns []string
+	for _, c := range storageURLColumns {
+		if c[0] == table {
+			columns = append(columns, c[2])
+		}
+	}
+	return columns
+}
+
+type StorageRefRepo struct{}
+
+func NewStorageRefRepo() *StorageRefRepo {
+	return &StorageRefRepo{}
+}
+
+// GetURLRefs 取出各表中非空的文件地址
+func (r StorageRefRepo) GetURLRefs(refs *[]vo.StorageRefVO) error {
+	selects := make([]string, 0, len(storageURLColumns))
+	for _, c := range storageURLColumns {
+		selects = append(selects, "SELECT '"+c[0]+"' AS `table`, '"+c[2]+"' AS `column`, "+
+			c[1]+" AS id, "+c[2]+" AS url FROM "+c[0]+" WHERE "+c[2]+" <> ''")
+	}
+	return db.Get().Raw(strings.Join(selects, " UNION ALL ")).Scan(refs).Error
+}
+
+// GetRenditionRefs 音质版本直接保存对象名
+func (r StorageRefRepo) GetRenditionRefs(refs *[]vo.StorageRefVO) error {
+	return db.Get().Raw("SELECT 'tb_song_rendition' AS `table`, 'object_key' AS `column`, id, object_key AS `key` FROM tb_song_rendition").
+		Scan(refs).Error
+}
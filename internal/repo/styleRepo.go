@@ -1,7 +1,9 @@
 package repo
 
 import (
+	"gorm.io/gorm"
 	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
 	"vibe-music-server/internal/pkg/db"
 )
 
@@ -18,3 +20,100 @@ func (s StyleRepo) GetStyleIdsByNames(ids *[]uint64, names []string) error {
 		Pluck("id", ids)
 	return query.Error
 }
+
+func (s StyleRepo) GetStyleList(data *[]vo.StyleVO) error {
+	return db.Get().Table("tb_style st").
+		Select("st.id AS style_id, st.name, COUNT(g.song_id) AS song_count").
+		Joins("LEFT JOIN tb_genre g ON g.style_id = st.id AND g.song_id IN (SELECT id FROM tb_song WHERE deleted_at IS NULL)").
+		Group("st.id, st.name").
+		Order("song_count DESC, st.id ASC").
+		Scan(data).Error
+}
+
+func (s StyleRepo) GetStyleById(style *entity.Style, id uint64) error {
+	return db.Get().First(style, id).Error
+}
+
+func (s StyleRepo) GetStylesByIds(styles *[]entity.Style, ids []uint64) error {
+	return db.Get().Where("id IN ?", ids).Find(styles).Error
+}
+
+func (s StyleRepo) ExistStyleByName(name string, excludeId uint64) bool {
+	var count int64
+	db.Get().Model(&entity.Style{}).Where("name = ? AND id <> ?", name, excludeId).Count(&count)
+	return count > 0
+}
+
+func (s StyleRepo) CreateStyle(style *entity.Style) error {
+	return db.Get().Create(style).Error
+}
+
+// RenameStyle 重命名风格, 同步歌曲与歌单中的冗余风格名
+func (s StyleRepo) RenameStyle(style *entity.Style, name string) error {
+	return db.Get().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(style).Update("name", name).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Playlist{}).Where("style = ?", style.Name).Update("style", name).Error; err != nil {
+			return err
+		}
+		var songIds []uint64
+		if err := tx.Model(&entity.Genre{}).Where("style_id = ?", style.ID).Pluck("song_id", &songIds).Error; err != nil {
+			return err
+		}
+		return rebuildSongStyles(tx, songIds)
+	})
+}
+
+// MergeStyles 将 sources 的歌曲关联并入 target 后删除 sources
+func (s StyleRepo) MergeStyles(sources []entity.Style, target *entity.Style) error {
+	sourceIds := make([]uint64, 0, len(sources))
+	sourceNames := make([]string, 0, len(sources))
+	for _, source := range sources {
+		sourceIds = append(sourceIds, uint64(source.ID))
+		sourceNames = append(sourceNames, source.Name)
+	}
+	return db.Get().Transaction(func(tx *gorm.DB) error {
+		var songIds []uint64
+		if err := tx.Model(&entity.Genre{}).Distinct("song_id").Where("style_id IN ?", sourceIds).Pluck("song_id", &songIds).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT IGNORE INTO tb_genre (song_id, style_id)
+			SELECT DISTINCT song_id, ? FROM tb_genre WHERE style_id IN ?`, target.ID, sourceIds).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("style_id IN ?", sourceIds).Delete(&entity.Genre{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", sourceIds).Delete(&entity.Style{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Playlist{}).Where("style IN ?", sourceNames).Update("style", target.Name).Error; err != nil {
+			return err
+		}
+		return rebuildSongStyles(tx, songIds)
+	})
+}
+
+// DeleteStyle 删除风格, reassignTo 非空时将其歌曲与歌单改挂到该风格
+func (s StyleRepo) DeleteStyle(style *entity.Style, reassignTo *entity.Style) error {
+	if reassignTo != nil {
+		return s.MergeStyles([]entity.Style{*style}, reassignTo)
+	}
+	return db.Get().Transaction(func(tx *gorm.DB) error {
+		var songIds []uint64
+		if err := tx.Model(&entity.Genre{}).Where("style_id = ?", style.ID).Pluck("song_id", &songIds).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("style_id = ?", style.ID).Delete(&entity.Genre{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(style).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Playlist{}).Where("style = ?", style.Name).Update("style", "").Error; err != nil {
+			return err
+		}
+		return rebuildSongStyles(tx, songIds)
+	})
+}
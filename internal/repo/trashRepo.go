@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"strings"
+	"time"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
+)
+
+// TrashSource 回收站中一种类型的来源表, 表名与列名只来自代码中的定义
+type TrashSource struct {
+	Type  string
+	Table string
+	Title string // 作为标题显示的列, 为空时标题为空
+	Cover string // 作为封面显示的列
+}
+
+type TrashRepo struct{}
+
+func NewTrashRepo() *TrashRepo {
+	return &TrashRepo{}
+}
+
+// GetTrashItems 分页列出各来源表中已移入回收站的行, 最近删除的在前
+func (r TrashRepo) GetTrashItems(data *result.PageResult[vo.TrashItemVO], sources []TrashSource, index, size int) error {
+	selects := make([]string, 0, len(sources))
+	for _, src := range sources {
+		title := "''"
+		if src.Title != "" {
+			title = src.Title
+		}
+		selects = append(selects, "SELECT '"+src.Type+"' AS type, id, "+title+" AS title, "+
+			src.Cover+" AS cover_url, deleted_at FROM "+src.Table+" WHERE deleted_at IS NOT NULL")
+	}
+	union := strings.Join(selects, " UNION ALL ")
+	if err := db.Get().Raw("SELECT COUNT(1) FROM (" + union + ") t").Scan(&data.Total).Error; err != nil {
+		return err
+	}
+	return db.Get().Raw("SELECT * FROM ("+union+") t ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?", size, index).
+		Scan(&data.Items).Error
+}
+
+// GetTrashedIds 取出 ids 中已在回收站的行
+func (r TrashRepo) GetTrashedIds(trashedIds *[]uint64, table string, ids []uint64) error {
+	return db.Get().Table(table).Where("id IN ? AND deleted_at IS NOT NULL", ids).Pluck("id", trashedIds).Error
+}
+
+// GetExpiredIds 取出在 before 之前移入回收站的行
+func (r TrashRepo) GetExpiredIds(ids *[]uint64, table string, before time.Time) error {
+	return db.Get().Table(table).Where("deleted_at < ?", before).Order("id").Pluck("id", ids).Error
+}
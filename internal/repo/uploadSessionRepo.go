@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"time"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+)
+
+type UploadSessionRepo struct{}
+
+func NewUploadSessionRepo() *UploadSessionRepo {
+	return &UploadSessionRepo{}
+}
+
+func (r UploadSessionRepo) AddUploadSession(session *entity.UploadSession) error {
+	return db.Get().Create(session).Error
+}
+
+func (r UploadSessionRepo) GetUploadSession(session *entity.UploadSession, id string) error {
+	return db.Get().Where("id = ?", id).First(session).Error
+}
+
+func (r UploadSessionRepo) TouchUploadSession(id string) error {
+	return db.Get().Model(&entity.UploadSession{}).Where("id = ?", id).Update("update_time", time.Now()).Error
+}
+
+func (r UploadSessionRepo) DeleteUploadSession(id string) error {
+	return db.Get().Where("id = ?", id).Delete(&entity.UploadSession{}).Error
+}
+
+// GetStaleUploadSessions 查询在 before 之后再无分片上传的会话
+func (r UploadSessionRepo) GetStaleUploadSessions(sessions *[]entity.UploadSession, before time.Time) error {
+	return db.Get().Where("update_time < ?", before).Find(sessions).Error
+}
+
+func (r UploadSessionRepo) UploadSessionExists(id string) (bool, error) {
+	var count int64
+	err := db.Get().Model(&entity.UploadSession{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
@@ -7,50 +7,64 @@ import (
 	"vibe-music-server/internal/pkg/result"
 )
 
-type UserRepo struct{}
+type UserRepo struct {
+	txConn
+}
 
 func NewUserRepo() *UserRepo {
 	return &UserRepo{}
 }
 
+// WithTx 返回在工作单元事务中执行的仓储
+func (u UserRepo) WithTx(uow *db.UnitOfWork) *UserRepo {
+	return &UserRepo{txConn{uow.Tx()}}
+}
+
+// GetUserByName 包括回收站中的用户, 用户名唯一索引同样覆盖这些行
 func (u UserRepo) GetUserByName(user *entity.User, name string) error {
-	return db.Get().Where("username = ?", name).First(user).Error
+	return u.conn().Unscoped().Where("username = ?", name).First(user).Error
 }
 
 func (u UserRepo) GetUserByEmail(user *entity.User, email string) error {
-	return db.Get().Where("email = ?", email).First(user).Error
+	return u.conn().Where("email = ?", email).First(user).Error
+}
+
+// GetUserByEmailWithTrashed 包括回收站中的用户, 用于唯一性检查, 避免恢复后出现重复邮箱
+func (u UserRepo) GetUserByEmailWithTrashed(user *entity.User, email string) error {
+	return u.conn().Unscoped().Where("email = ?", email).First(user).Error
 }
 
 func (u UserRepo) CreateUser(user *entity.User) error {
-	return db.Get().Create(user).Error
+	return u.conn().Create(user).Error
 }
 
 func (u UserRepo) GetUserById(user *entity.User, id uint64) error {
-	return db.Get().Where("id = ?", id).First(user).Error
+	return u.conn().Where("id = ?", id).First(user).Error
 }
 
 func (u UserRepo) UpdateUser(user *entity.User) error {
-	return db.Get().Model(user).Updates(user).Error
+	return u.conn().Model(user).Updates(user).Error
 }
 
 func (u UserRepo) DeleteUser(id uint64) error {
-	return db.Get().Where("id = ?", id).Delete(&entity.User{}).Error
+	return u.conn().Where("id = ?", id).Delete(&entity.User{}).Error
 }
 
 func (u UserRepo) DeleteUsers(ids []uint64) error {
-	return db.Get().Where("id IN ?", ids).Delete(&entity.User{}).Error
+	return u.conn().Where("id IN ?", ids).Delete(&entity.User{}).Error
 }
 
 func (u UserRepo) GetAllUsersCount(count *int64) error {
-	return db.Get().Model(&entity.User{}).Count(count).Error
+	return u.conn().Model(&entity.User{}).Count(count).Error
 }
 
+// GetUserByPhone 包括回收站中的用户, 只用于唯一性检查
 func (u UserRepo) GetUserByPhone(user *entity.User, phone string) error {
-	return db.Get().Where("phone = ?", phone).First(user).Error
+	return u.conn().Unscoped().Where("phone = ?", phone).First(user).Error
 }
 
 func (u UserRepo) GetAllUsers(data *result.PageResult[vo.UserManagementVO], username string, phone string, status uint8, index int, size int) error {
-	query := db.Get().Model(&entity.User{}).
+	query := u.conn().Model(&entity.User{}).
 		Select("user_id, username, phone, email, status user_status, created_at, updated_at, user_avatar, introduction")
 	if username != "" {
 		query = query.Where("username LIKE ?", "%"+username+"%")
@@ -69,5 +83,5 @@ func (u UserRepo) GetAllUsers(data *result.PageResult[vo.UserManagementVO], user
 }
 
 func (u UserRepo) GetAvatarByIds(avatar *[]string, id []uint64) error {
-	return db.Get().Model(&entity.User{}).Where("user_id IN ?", id).Pluck("user_avatar", avatar).Error
+	return u.conn().Model(&entity.User{}).Where("user_id IN ?", id).Pluck("user_avatar", avatar).Error
 }
@@ -15,7 +15,11 @@ func registerAdminRouter(r *gin.Engine, ctrl *controller.AdminCtrl) {
 		g.POST("/login", ctrl.Login)
 		g.POST("/logout", ctrl.Logout)
 	}
-	g.Use(middleware.AdminAuthMiddleware())
+	g.Use(middleware.AdminAuthMiddleware(), middleware.AuditMiddleware(auditService))
+	// dashboard
+	{
+		g.GET("/dashboard", ctrl.GetDashboard)
+	}
 	// user management
 	{
 		g.GET("/getAllUsersCount", ctrl.GetAllUsersCount)
@@ -44,10 +48,46 @@ func registerAdminRouter(r *gin.Engine, ctrl *controller.AdminCtrl) {
 		g.POST("/addSong", ctrl.AddSong)
 		g.PUT("/updateSong", ctrl.UpdateSong)
 		g.PATCH("/updateSongCover/:id", ctrl.UpdateSongCover)
+		g.PUT("/updateSongLyric", ctrl.UpdateSongLyric)
+		g.PATCH("/uploadSongLyric/:id", ctrl.UploadSongLyric)
 		g.PATCH("/updateSongAudio/:id", ctrl.UpdateSongAudio)
+		g.POST("/initSongAudioUpload", ctrl.InitSongAudioUpload)
+		g.PUT("/uploadSongAudioPart/:uploadId/:partNumber", ctrl.UploadSongAudioPart)
+		g.GET("/getSongAudioUpload/:uploadId", ctrl.GetSongAudioUpload)
+		g.POST("/completeSongAudioUpload/:uploadId", ctrl.CompleteSongAudioUpload)
+		g.DELETE("/abortSongAudioUpload/:uploadId", ctrl.AbortSongAudioUpload)
+		g.GET("/getSongRenditions/:id", ctrl.GetSongRenditions)
+		g.POST("/uploadSongRendition/:id", ctrl.UploadSongRendition)
+		g.DELETE("/deleteSongRendition/:id", ctrl.DeleteSongRendition)
+		g.GET("/getSongAudioMeta/:id", ctrl.GetSongAudioMeta)
+		g.POST("/applySongAudioMeta", ctrl.ApplySongAudioMeta)
+		g.DELETE("/discardSongAudioMeta/:id", ctrl.DiscardSongAudioMeta)
 		g.DELETE("/deleteSong/:id", ctrl.DeleteSong)
 		g.DELETE("/deleteSongs", ctrl.DeleteSongs)
 	}
+	// import
+	{
+		g.POST("/import", ctrl.ImportSongs)
+		g.GET("/import/:id", ctrl.GetImportJob)
+	}
+	// album management
+	{
+		g.POST("/getAllAlbums", ctrl.GetAllAlbums)
+		g.POST("/addAlbum", ctrl.AddAlbum)
+		g.PUT("/updateAlbum", ctrl.UpdateAlbum)
+		g.PATCH("/updateAlbumCover/:id", ctrl.UpdateAlbumCover)
+		g.PUT("/updateAlbumTracks", ctrl.UpdateAlbumTracks)
+		g.DELETE("/deleteAlbum/:id", ctrl.DeleteAlbum)
+		g.DELETE("/deleteAlbums", ctrl.DeleteAlbums)
+	}
+	// style management
+	{
+		g.GET("/getAllStyles", ctrl.GetAllStyles)
+		g.POST("/addStyle", ctrl.AddStyle)
+		g.PUT("/updateStyle", ctrl.UpdateStyle)
+		g.POST("/mergeStyles", ctrl.MergeStyles)
+		g.DELETE("/deleteStyle/:id", ctrl.DeleteStyle)
+	}
 	// playlist management
 	{
 		g.GET("/getAllPlaylistsCount", ctrl.GetAllPlaylistsCount)
@@ -58,4 +98,30 @@ func registerAdminRouter(r *gin.Engine, ctrl *controller.AdminCtrl) {
 		g.DELETE("/deletePlaylist/:id", ctrl.DeletePlaylist)
 		g.DELETE("/deletePlaylists", ctrl.DeletePlaylists)
 	}
+	// storage
+	{
+		g.GET("/getStorageReport", ctrl.GetStorageReport)
+		g.POST("/cleanStorageOrphans", ctrl.CleanStorageOrphans)
+	}
+	// deletion
+	{
+		g.POST("/previewDelete", ctrl.PreviewDelete)
+	}
+	// trash
+	{
+		g.GET("/trash", ctrl.GetTrash)
+		g.POST("/trash/restore", ctrl.RestoreTrash)
+		g.DELETE("/trash", ctrl.PurgeTrash)
+	}
+	// audit
+	{
+		g.GET("/auditLog", ctrl.GetAuditLogs)
+		g.GET("/auditLog/export", ctrl.ExportAuditLogs)
+	}
+	// backup
+	{
+		g.POST("/backup", ctrl.StartBackup)
+		g.GET("/backup", ctrl.GetBackups)
+		g.GET("/backup/:name", ctrl.DownloadBackup)
+	}
 }
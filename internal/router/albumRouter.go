@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"vibe-music-server/internal/controller"
+)
+
+func registerAlbumRouter(r *gin.Engine, ctrl *controller.AlbumCtrl) {
+	g := r.Group("/album")
+	{
+		g.POST("/getAllAlbums", ctrl.GetAllAlbums)
+		g.GET("/:id", ctrl.GetAlbumDetail)
+	}
+}
@@ -10,7 +10,7 @@ func registerBannerRouter(r *gin.Engine, ctrl *controller.BannerCtrl) {
 	g := r.Group("/admin")
 	p := r.Group("/banner")
 	// 组级中间件
-	g.Use(middleware.AdminAuthMiddleware())
+	g.Use(middleware.AdminAuthMiddleware(), middleware.AuditMiddleware(auditService))
 	{
 		g.POST("/getAllBanners", ctrl.GetAllBanners)
 		g.POST("/addBanner", ctrl.AddBanner)
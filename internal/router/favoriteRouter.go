@@ -11,7 +11,7 @@ func registerFavoriteRouter(r *gin.Engine, ctrl *controller.FavoriteCtrl) {
 	g.Use(middleware.AuthMiddleware())
 	// song
 	{
-		g.POST("/getFavoriteSongs", ctrl.GetFavoritePlaylists)
+		g.POST("/getFavoriteSongs", ctrl.GetFavoriteSongs)
 		g.POST("/collectSong", ctrl.CollectSong)
 		g.DELETE("/cancelCollectSong", ctrl.CancelCollectSong)
 	}
@@ -21,4 +21,19 @@ func registerFavoriteRouter(r *gin.Engine, ctrl *controller.FavoriteCtrl) {
 		g.POST("/collectPlaylist", ctrl.CollectPlaylist)
 		g.DELETE("/cancelCollectPlaylist", ctrl.CancelCollectPlaylist)
 	}
+	// artist
+	{
+		g.POST("/getFavoriteArtists", ctrl.GetFavoriteArtists)
+		g.POST("/collectArtist", ctrl.CollectArtist)
+		g.DELETE("/cancelCollectArtist", ctrl.CancelCollectArtist)
+	}
+	// album
+	{
+		g.POST("/getFavoriteAlbums", ctrl.GetFavoriteAlbums)
+		g.POST("/collectAlbum", ctrl.CollectAlbum)
+		g.DELETE("/cancelCollectAlbum", ctrl.CancelCollectAlbum)
+	}
+	{
+		g.POST("/isFavorited", ctrl.IsFavorited)
+	}
 }
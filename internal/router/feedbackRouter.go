@@ -9,6 +9,7 @@ import (
 func registerFeedbackRouter(r *gin.Engine, ctrl *controller.FeedbackCtrl) {
 	g := r.Group("/admin")
 	p := r.Group("/feedback")
+	g.Use(middleware.AdminAuthMiddleware(), middleware.AuditMiddleware(auditService))
 	{
 		g.POST("/getAllFeedbacks", ctrl.GetAllFeedbacks)
 		g.DELETE("/deleteFeedback/:id", ctrl.DeleteFeedback)
@@ -7,88 +7,153 @@ import (
 	"vibe-music-server/internal/config"
 	"vibe-music-server/internal/controller"
 	"vibe-music-server/internal/middleware"
+	"vibe-music-server/internal/pkg/storage"
 	"vibe-music-server/internal/repo"
 	"vibe-music-server/internal/service"
 )
 
 var (
-	adminRepo    *repo.AdminRepo
-	artistRepo   *repo.ArtistRepo
-	bannerRepo   *repo.BannerRepo
-	commentRepo  *repo.CommentRepo
-	favoriteRepo *repo.FavoriteRepo
-	feedbackRepo *repo.FeedbackRepo
-	genreRepo    *repo.GenreRepo
-	playlistRepo *repo.PlaylistRepo
-	songRepo     *repo.SongRepo
-	styleRepo    *repo.StyleRepo
-	userRepo     *repo.UserRepo
+	adminRepo         *repo.AdminRepo
+	albumRepo         *repo.AlbumRepo
+	artistRepo        *repo.ArtistRepo
+	auditLogRepo      *repo.AuditLogRepo
+	backupRepo        *repo.BackupRepo
+	bannerRepo        *repo.BannerRepo
+	commentRepo       *repo.CommentRepo
+	deletionRepo      *repo.DeletionRepo
+	favoriteRepo      *repo.FavoriteRepo
+	feedbackRepo      *repo.FeedbackRepo
+	genreRepo         *repo.GenreRepo
+	importJobRepo     *repo.ImportJobRepo
+	playlistRepo      *repo.PlaylistRepo
+	searchRepo        *repo.SearchRepo
+	songRepo          *repo.SongRepo
+	songArtistRepo    *repo.SongArtistRepo
+	songAudioMetaRepo *repo.SongAudioMetaRepo
+	songRenditionRepo *repo.SongRenditionRepo
+	statRepo          *repo.StatRepo
+	storageObjectRepo *repo.StorageObjectRepo
+	storageRefRepo    *repo.StorageRefRepo
+	styleRepo         *repo.StyleRepo
+	trashRepo         *repo.TrashRepo
+	uploadSessionRepo *repo.UploadSessionRepo
+	userRepo          *repo.UserRepo
 )
 
+var store storage.Storage
+
 var (
-	adminService    *service.AdminService
-	artistService   *service.ArtistService
-	bannerService   *service.BannerService
-	commentService  *service.CommentService
-	emailService    *service.EmailService
-	favoriteService *service.FavoriteService
-	feedbackService *service.FeedbackService
-	minioService    *service.MinioService
-	playlistService *service.PlaylistService
-	songService     *service.SongService
-	userService     *service.UserService
+	adminService     *service.AdminService
+	albumService     *service.AlbumService
+	artistService    *service.ArtistService
+	auditService     *service.AuditService
+	backupService    *service.BackupService
+	bannerService    *service.BannerService
+	commentService   *service.CommentService
+	deletionService  *service.DeletionService
+	emailService     *service.EmailService
+	favoriteService  *service.FavoriteService
+	feedbackService  *service.FeedbackService
+	importService    *service.ImportService
+	playlistService  *service.PlaylistService
+	reconcileService *service.ReconcileService
+	renditionService *service.RenditionService
+	searchService    *service.SearchService
+	songService      *service.SongService
+	statService      *service.StatService
+	storageService   *service.StorageService
+	styleService     *service.StyleService
+	trashService     *service.TrashService
+	uploadService    *service.UploadService
+	userService      *service.UserService
 )
 
 var (
 	adminCtrl    *controller.AdminCtrl
+	albumCtrl    *controller.AlbumCtrl
 	artistCtrl   *controller.ArtistCtrl
 	bannerCtrl   *controller.BannerCtrl
 	commentCtrl  *controller.CommentCtrl
 	favoriteCtrl *controller.FavoriteCtrl
 	feedbackCtrl *controller.FeedbackCtrl
 	playlistCtrl *controller.PlaylistCtrl
+	searchCtrl   *controller.SearchCtrl
 	songCtrl     *controller.SongCtrl
+	styleCtrl    *controller.StyleCtrl
 	userCtrl     *controller.UserCtrl
 )
 
 func init() {
 	adminRepo = repo.NewAdminRepo()
+	albumRepo = repo.NewAlbumRepo()
 	artistRepo = repo.NewArtistRepo()
+	auditLogRepo = repo.NewAuditLogRepo()
+	backupRepo = repo.NewBackupRepo()
 	bannerRepo = repo.NewBannerRepo()
 	commentRepo = repo.NewCommentRepo()
+	deletionRepo = repo.NewDeletionRepo()
 	favoriteRepo = repo.NewFavoriteRepo()
 	feedbackRepo = repo.NewFeedbackRepo()
 	genreRepo = repo.NewGenreRepo()
+	importJobRepo = repo.NewImportJobRepo()
 	playlistRepo = repo.NewPlaylistRepo()
+	searchRepo = repo.NewSearchRepo()
 	songRepo = repo.NewSongRepo()
+	songArtistRepo = repo.NewSongArtistRepo()
+	songAudioMetaRepo = repo.NewSongAudioMetaRepo()
+	songRenditionRepo = repo.NewSongRenditionRepo()
+	statRepo = repo.NewStatRepo()
+	storageObjectRepo = repo.NewStorageObjectRepo()
+	storageRefRepo = repo.NewStorageRefRepo()
 	styleRepo = repo.NewStyleRepo()
+	trashRepo = repo.NewTrashRepo()
+	uploadSessionRepo = repo.NewUploadSessionRepo()
 	userRepo = repo.NewUserRepo()
 }
 
 func init() {
+	var err error
+	if store, err = storage.New(); err != nil {
+		panic("failed to init storage: " + err.Error())
+	}
+	storageService = service.NewStorageService(store, storageObjectRepo)
+	searchService = service.NewSearchService(searchRepo, storageService)
+	deletionService = service.NewDeletionService(deletionRepo, storageService, searchService)
 	adminService = service.NewAdminService(adminRepo)
-	artistService = service.NewArtistService(artistRepo, favoriteRepo, minioService)
-	bannerService = service.NewBannerService(bannerRepo, minioService)
+	albumService = service.NewAlbumService(albumRepo, artistRepo, favoriteRepo, storageService, searchService, deletionService)
+	artistService = service.NewArtistService(artistRepo, songArtistRepo, favoriteRepo, storageService, searchService, deletionService)
+	auditService = service.NewAuditService(auditLogRepo)
+	backupService = service.NewBackupService(backupRepo, storageService)
+	bannerService = service.NewBannerService(bannerRepo, storageService, deletionService)
 	commentService = service.NewCommentService(commentRepo)
 	emailService = service.NewEmailService()
-	favoriteService = service.NewFavoriteService(favoriteRepo, songRepo, playlistRepo)
+	favoriteService = service.NewFavoriteService(favoriteRepo, songRepo, songArtistRepo, playlistRepo, storageService)
 	feedbackService = service.NewFeedbackService(feedbackRepo)
-	minioService = service.NewMinioService()
-	playlistService = service.NewPlaylistService(playlistRepo, favoriteRepo, styleRepo, minioService)
-	songService = service.NewSongService(songRepo, favoriteRepo, styleRepo, genreRepo, minioService)
-	userService = service.NewUserService(userRepo, emailService, minioService)
+	playlistService = service.NewPlaylistService(playlistRepo, songArtistRepo, favoriteRepo, styleRepo, storageService, searchService, deletionService)
+	songService = service.NewSongService(songRepo, albumRepo, songArtistRepo, favoriteRepo, styleRepo, genreRepo, songAudioMetaRepo, songRenditionRepo, storageService, searchService, deletionService)
+	statService = service.NewStatService(statRepo)
+	renditionService = service.NewRenditionService(songRepo, songRenditionRepo, storageService, statService)
+	reconcileService = service.NewReconcileService(storageRefRepo, storageObjectRepo, storageService)
+	styleService = service.NewStyleService(styleRepo)
+	trashService = service.NewTrashService(trashRepo, deletionService)
+	uploadService = service.NewUploadService(uploadSessionRepo, songRepo, storageService, songService)
+	importService = service.NewImportService(importJobRepo, songRepo, artistRepo, albumRepo, styleRepo, storageService, searchService, songService)
+	userService = service.NewUserService(userRepo, emailService, storageService, deletionService)
 }
 
 func init() {
-	adminCtrl = controller.NewAdminCtrl(adminService, userService, artistService, songService, playlistService, minioService)
+	albumCtrl = controller.NewAlbumCtrl(albumService)
+	adminCtrl = controller.NewAdminCtrl(adminService, userService, artistService, songService, playlistService, albumService, styleService, storageService, uploadService, renditionService, reconcileService, deletionService, trashService, auditService, statService, importService, backupService)
 	artistCtrl = controller.NewArtistCtrl(artistService)
-	bannerCtrl = controller.NewBannerCtrl(bannerService, minioService)
+	bannerCtrl = controller.NewBannerCtrl(bannerService, storageService)
 	commentCtrl = controller.NewCommentCtrl(commentService)
 	favoriteCtrl = controller.NewFavoriteCtrl(favoriteService)
 	feedbackCtrl = controller.NewFeedbackCtrl(feedbackService)
 	playlistCtrl = controller.NewPlaylistCtrl(playlistService)
-	songCtrl = controller.NewSongCtrl(songService)
-	userCtrl = controller.NewUserCtrl(userService, minioService)
+	searchCtrl = controller.NewSearchCtrl(searchService)
+	songCtrl = controller.NewSongCtrl(songService, renditionService)
+	styleCtrl = controller.NewStyleCtrl(styleService)
+	userCtrl = controller.NewUserCtrl(userService, storageService)
 }
 
 func setupCORS(corsCfg config.CORS) gin.HandlerFunc {
@@ -147,13 +212,29 @@ func NewEngine() *gin.Engine {
 	r.Use(setupCORS(config.Get().App.CORS))
 	// 业务分组
 	registerAdminRouter(r, adminCtrl)
+	registerAlbumRouter(r, albumCtrl)
 	registerArtistRouter(r, artistCtrl)
 	registerBannerRouter(r, bannerCtrl)
 	registerCommentRouter(r, commentCtrl)
 	registerFavoriteRouter(r, favoriteCtrl)
 	registerFeedbackRouter(r, feedbackCtrl)
 	registerPlaylistRouter(r, playlistCtrl)
+	registerSearchRouter(r, searchCtrl)
 	registerSongRouter(r, songCtrl)
+	registerStyleRouter(r, styleCtrl)
 	registerUserRouter(r, userCtrl)
+	// 本地存储的文件由应用自身提供访问
+	if srv, ok := store.(storage.Server); ok {
+		r.GET(srv.Prefix()+"/*key", gin.WrapH(srv))
+		r.HEAD(srv.Prefix()+"/*key", gin.WrapH(srv))
+	}
+	// 搜索索引依赖数据库, 需在数据库初始化后构建
+	go searchService.Run(10 * time.Minute)
+	go uploadService.Run(time.Hour)
+	go reconcileService.Run()
+	go trashService.Run(time.Hour)
+	go auditService.Run()
+	go statService.Run(10 * time.Minute)
+	go importService.Run()
 	return r
 }
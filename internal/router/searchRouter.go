@@ -0,0 +1,15 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"vibe-music-server/internal/controller"
+)
+
+func registerSearchRouter(r *gin.Engine, ctrl *controller.SearchCtrl) {
+	g := r.Group("/search")
+	{
+		g.GET("", ctrl.Search)
+		g.GET("/suggest", ctrl.Suggest)
+		g.GET("/trending", ctrl.GetTrending)
+	}
+}
@@ -11,5 +11,7 @@ func registerSongRouter(r *gin.Engine, ctrl *controller.SongCtrl) {
 		g.POST("/getAllSongs", ctrl.GetAllSongs)
 		g.GET("/getRecommendedSongs", ctrl.GetRecommendedSongs)
 		g.GET("/getSongDetail/:id", ctrl.GetSongDetail)
+		g.GET("/lyrics/:id", ctrl.GetSongLyrics)
+		g.GET("/stream/:id", ctrl.GetSongStream)
 	}
 }
@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"vibe-music-server/internal/controller"
+)
+
+func registerStyleRouter(r *gin.Engine, ctrl *controller.StyleCtrl) {
+	g := r.Group("/style")
+	{
+		g.GET("/list", ctrl.GetStyleList)
+	}
+}
@@ -87,3 +87,19 @@ func (a AdminService) Logout(token string) result.Result[result.Nil] {
 	}
 	return result.Success[result.Nil](consts.Logout + consts.Success)
 }
+
+// ResetAdmin 创建管理员, 已存在时重置其密码, 供 cmd/vibectl 使用; 返回是否新建
+func (a AdminService) ResetAdmin(username, password string) (bool, error) {
+	encryptedPassword, err := util.EncryptPassword(password)
+	if err != nil {
+		return false, err
+	}
+	admin, err := a.adminRepo.SelectByUsername(username)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if admin != nil {
+		return false, a.adminRepo.UpdatePassword(admin.AdminId, encryptedPassword)
+	}
+	return true, a.adminRepo.Insert(&entity.Admin{Username: username, Password: encryptedPassword})
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/repo"
+)
+
+type AlbumService struct {
+	albumRepo       *repo.AlbumRepo
+	artistRepo      *repo.ArtistRepo
+	favoriteRepo    *repo.FavoriteRepo
+	storageService  *StorageService
+	searchService   *SearchService
+	deletionService *DeletionService
+}
+
+func NewAlbumService(albumRepo *repo.AlbumRepo, artistRepo *repo.ArtistRepo, favoriteRepo *repo.FavoriteRepo, storageService *StorageService, searchService *SearchService, deletionService *DeletionService) *AlbumService {
+	return &AlbumService{
+		albumRepo:       albumRepo,
+		artistRepo:      artistRepo,
+		favoriteRepo:    favoriteRepo,
+		storageService:  storageService,
+		searchService:   searchService,
+		deletionService: deletionService,
+	}
+}
+
+func (a AlbumService) GetAllAlbums(albumDTO *dto.AlbumDTO) result.Result[result.PageResult[vo.AlbumVO]] {
+	retErr := result.Error[result.PageResult[vo.AlbumVO]]
+	retSuc := result.SuccessWithData[result.PageResult[vo.AlbumVO]]
+	pageNum := albumDTO.PageNum
+	pageSize := albumDTO.PageSize
+	startIndex := (pageNum - 1) * pageSize
+	var data result.PageResult[vo.AlbumVO]
+	templateKey := util.GenKeyByPattern("album:getAllAlbums", startIndex, pageSize,
+		albumDTO.Title, albumDTO.ArtistID, albumDTO.ArtistName, albumDTO.Type)
+	if util.GetCache(templateKey, &data) {
+		return retSuc(consts.Success, data)
+	}
+	if err := a.albumRepo.GetAllAlbums(&data, startIndex, pageSize,
+		albumDTO.Title, albumDTO.ArtistID, albumDTO.ArtistName, albumDTO.Type); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if data.Total == 0 {
+		return retErr(consts.DataNotFound)
+	}
+	a.storageService.FillSrcsets(&data)
+	util.SetCache(templateKey, data)
+	return retSuc(consts.Success, data)
+}
+
+// GetAlbumDetail claims 可为nil
+func (a AlbumService) GetAlbumDetail(albumId uint64, claims *util.Claims) result.Result[vo.AlbumDetailVO] {
+	retErr := result.Error[vo.AlbumDetailVO]
+	retSuc := result.SuccessWithData[vo.AlbumDetailVO]
+	var data vo.AlbumDetailVO
+	templateKey := util.GenKeyByPattern("album:getAlbumDetail", albumId)
+	if !util.GetCache(templateKey, &data) {
+		if err := a.albumRepo.GetAlbumDetail(&data, albumId); err != nil {
+			return retErr(consts.InternalError)
+		}
+		if data.AlbumID == 0 {
+			return retErr(consts.DataNotFound)
+		}
+		if data.Tracks == nil {
+			data.Tracks = []vo.AlbumTrackVO{}
+		}
+		a.storageService.FillSrcsets(&data)
+		util.SetCache(templateKey, data)
+	}
+	if claims == nil || claims.Role != consts.UserRole {
+		return retSuc(consts.Success, data)
+	}
+	// 填充专辑及曲目的 LikeStatus
+	userId := claims.UserId
+	var isFavorite uint8
+	if err := a.favoriteRepo.IsFavoriteAlbum(&isFavorite, userId, albumId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if isFavorite > 0 {
+		data.LikeStatus = 1
+	}
+	var favoriteSongIds []uint64
+	if err := a.favoriteRepo.GetFavoriteSongIds(&favoriteSongIds, userId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	for i := range data.Tracks {
+		if util.BinarySearch(favoriteSongIds, data.Tracks[i].SongID) != -1 {
+			data.Tracks[i].LikeStatus = 1
+		}
+	}
+	return retSuc(consts.Success, data)
+}
+
+func (a AlbumService) AddAlbum(albumAddDTO *dto.AlbumAddDTO) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	var artist entity.Artist
+	if err := a.artistRepo.SelectById(&artist, albumAddDTO.ArtistID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.Artist + consts.NotExist)
+		}
+		return retErr(consts.InternalError)
+	}
+	if a.albumRepo.ExistAlbum(albumAddDTO.ArtistID, albumAddDTO.Title, 0) {
+		return retErr(consts.Album + consts.AlreadyExists)
+	}
+	album := entity.Album{
+		ArtistID:    albumAddDTO.ArtistID,
+		Title:       albumAddDTO.Title,
+		ReleaseDate: albumAddDTO.ReleaseDate,
+		Type:        entity.AlbumType(albumAddDTO.Type),
+		Description: albumAddDTO.Description,
+	}
+	if err := a.albumRepo.CreateAlbum(&album); err != nil {
+		return retErr(consts.Add + consts.Failed)
+	}
+	a.searchService.RefreshAlbums(album.ID)
+	util.DeleteCacheByPattern("album:*")
+	return retSuc(consts.Add + consts.Success)
+}
+
+func (a AlbumService) UpdateAlbum(albumUpdateDTO *dto.AlbumUpdateDTO) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	var album entity.Album
+	if err := a.albumRepo.GetAlbumById(&album, albumUpdateDTO.AlbumID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
+	}
+	if album.ArtistID != albumUpdateDTO.ArtistID {
+		var artist entity.Artist
+		if err := a.artistRepo.SelectById(&artist, albumUpdateDTO.ArtistID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return retErr(consts.Artist + consts.NotExist)
+			}
+			return retErr(consts.InternalError)
+		}
+	}
+	if a.albumRepo.ExistAlbum(albumUpdateDTO.ArtistID, albumUpdateDTO.Title, album.ID) {
+		return retErr(consts.Album + consts.AlreadyExists)
+	}
+	// Updates 会把新值写回 album, 需先记下原专辑名
+	renamed := album.Title != albumUpdateDTO.Title
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		albumRepo := a.albumRepo.WithTx(uow)
+		if err := albumRepo.UpdateAlbum(&album, map[string]any{
+			"artist_id":    albumUpdateDTO.ArtistID,
+			"title":        albumUpdateDTO.Title,
+			"release_date": albumUpdateDTO.ReleaseDate,
+			"type":         albumUpdateDTO.Type,
+			"description":  albumUpdateDTO.Description,
+		}); err != nil {
+			return err
+		}
+		if renamed {
+			return albumRepo.SyncSongAlbumTitle(album.ID, albumUpdateDTO.Title)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("AlbumService.UpdateAlbum err: %v\n", err)
+		return retErr(consts.Update + consts.Failed)
+	}
+	if renamed {
+		util.DeleteCacheByPattern("song:*")
+	}
+	a.searchService.RefreshAlbums(album.ID)
+	util.DeleteCacheByPattern("album:*")
+	return retSuc(consts.Update + consts.Success)
+}
+
+func (a AlbumService) UpdateAlbumCover(albumId uint64, coverUrl string) result.Result[result.Nil] {
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		// 专辑不存在或写库失败时释放刚上传的封面
+		a.storageService.DeleteFileOnRollback(uow, coverUrl)
+		albumRepo := a.albumRepo.WithTx(uow)
+		var album entity.Album
+		if err := albumRepo.GetAlbumById(&album, albumId); err != nil {
+			return err
+		}
+		oldCover := album.CoverURL
+		if err := albumRepo.UpdateAlbum(&album, map[string]any{"cover_url": coverUrl}); err != nil {
+			return err
+		}
+		// 旧封面可能与歌曲共用, 由 StorageService 按引用决定是否删除
+		a.storageService.DeleteFileAfterCommit(uow, oldCover)
+		uow.AfterCommit(func() {
+			a.searchService.RefreshAlbums(albumId)
+			util.DeleteCacheByPattern("album:*")
+		})
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return result.Error[result.Nil](consts.DataNotFound)
+		}
+		log.Printf("AlbumService.UpdateAlbumCover err: %v\n", err)
+		return result.Error[result.Nil](consts.Update + consts.Failed)
+	}
+	return result.Success[result.Nil](consts.Update + consts.Success)
+}
+
+// UpdateAlbumTracks 以给定列表重排专辑曲目, 列表外的原曲目移出专辑
+func (a AlbumService) UpdateAlbumTracks(albumTracksDTO *dto.AlbumTracksDTO) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	var album entity.Album
+	if err := a.albumRepo.GetAlbumById(&album, albumTracksDTO.AlbumID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
+	}
+	songIds := make([]uint64, 0, len(albumTracksDTO.Tracks))
+	positions := make(map[[2]uint]bool, len(albumTracksDTO.Tracks))
+	for _, track := range albumTracksDTO.Tracks {
+		pos := [2]uint{track.DiscNumber, track.TrackNumber}
+		if positions[pos] {
+			return retErr(consts.InvalidParams)
+		}
+		positions[pos] = true
+		songIds = append(songIds, track.SongID)
+	}
+	if len(songIds) > 0 {
+		// 只允许收录该专辑歌手的歌曲
+		var count int64
+		if err := a.albumRepo.CountSongsNotOfArtist(&count, album.ArtistID, songIds); err != nil {
+			return retErr(consts.InternalError)
+		}
+		if count > 0 {
+			return retErr(consts.InvalidParams)
+		}
+	}
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		albumRepo := a.albumRepo.WithTx(uow)
+		if err := albumRepo.ClearTracksExcept(album.ID, songIds); err != nil {
+			return err
+		}
+		for _, track := range albumTracksDTO.Tracks {
+			if err := albumRepo.UpdateTrack(album.ID, track.SongID, album.Title, track.DiscNumber, track.TrackNumber); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("AlbumService.UpdateAlbumTracks err: %v\n", err)
+		return retErr(consts.Update + consts.Failed)
+	}
+	util.DeleteCacheByPattern("album:*")
+	util.DeleteCacheByPattern("song:*")
+	return retSuc(consts.Update + consts.Success)
+}
+
+func (a AlbumService) DeleteAlbum(albumId uint64) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	var album entity.Album
+	if err := a.albumRepo.GetAlbumById(&album, albumId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
+	}
+	return a.DeleteAlbums([]uint64{albumId})
+}
+
+// DeleteAlbums 歌曲保留, 仅解除专辑关联; 收藏随之删除, 封面在提交后释放
+func (a AlbumService) DeleteAlbums(albumIds []uint64) result.Result[result.Nil] {
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		return a.deletionService.Delete(uow, "tb_album", albumIds)
+	})
+	if err != nil {
+		log.Printf("AlbumService.DeleteAlbums err: %v\n", err)
+		return result.Error[result.Nil](deleteFailedMessage(err))
+	}
+	return result.Success[result.Nil](consts.Delete + consts.Success)
+}
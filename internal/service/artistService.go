@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
+	"log"
 	"vibe-music-server/internal/model/dto"
 	"vibe-music-server/internal/model/entity"
 	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
 	"vibe-music-server/internal/pkg/result"
 	"vibe-music-server/internal/pkg/result/consts"
 	"vibe-music-server/internal/pkg/util"
@@ -14,16 +16,22 @@ import (
 )
 
 type ArtistService struct {
-	artistRepo   *repo.ArtistRepo
-	favoriteRepo *repo.FavoriteRepo
-	minioService *MinioService
+	artistRepo      *repo.ArtistRepo
+	songArtistRepo  *repo.SongArtistRepo
+	favoriteRepo    *repo.FavoriteRepo
+	storageService  *StorageService
+	searchService   *SearchService
+	deletionService *DeletionService
 }
 
-func NewArtistService(artistRepo *repo.ArtistRepo, favoriteRepo *repo.FavoriteRepo, minioService *MinioService) *ArtistService {
+func NewArtistService(artistRepo *repo.ArtistRepo, songArtistRepo *repo.SongArtistRepo, favoriteRepo *repo.FavoriteRepo, storageService *StorageService, searchService *SearchService, deletionService *DeletionService) *ArtistService {
 	return &ArtistService{
-		artistRepo:   artistRepo,
-		favoriteRepo: favoriteRepo,
-		minioService: minioService,
+		artistRepo:      artistRepo,
+		songArtistRepo:  songArtistRepo,
+		favoriteRepo:    favoriteRepo,
+		storageService:  storageService,
+		searchService:   searchService,
+		deletionService: deletionService,
 	}
 }
 
@@ -47,6 +55,7 @@ func (a ArtistService) GetAllArtists(artistDTO *dto.ArtistDTO) result.Result[res
 		return retErr(consts.DataNotFound)
 	}
 	// 将结果存入缓存
+	a.storageService.FillSrcsets(&pageRet)
 	util.SetCache(templateKey, pageRet)
 	return retSuc(consts.Success, pageRet)
 }
@@ -105,6 +114,7 @@ func (a ArtistService) GetRandomArtists() result.Result[[]vo.ArtistVO] {
 	if len(data) == 0 {
 		return retErr(consts.DataNotFound)
 	}
+	a.storageService.FillSrcsets(&data)
 	return retSuc(consts.Success, data)
 }
 
@@ -118,6 +128,14 @@ func (a ArtistService) GetArtistDetail(artistId uint64, claims *util.Claims) res
 		if err != nil {
 			return retErr(consts.InternalError)
 		}
+		// 不存在或已移入回收站
+		if data.ArtistID == 0 {
+			return retErr(consts.DataNotFound)
+		}
+		if err := fillSongArtists(a.songArtistRepo, data.Songs); err != nil {
+			return retErr(consts.InternalError)
+		}
+		a.storageService.FillSrcsets(&data)
 	}
 	if claims == nil {
 		util.SetCache(templateKey, data)
@@ -170,6 +188,7 @@ func (a ArtistService) AddArtist(artistAddDTO *dto.ArtistAddDTO) result.Result[r
 		return retErr(consts.Add + consts.Failed)
 	}
 	// 清除相关缓存
+	a.searchService.RefreshArtists(uint64(artist.ID))
 	util.DeleteCacheByPattern("artist:*")
 	return retSuc(consts.Add + consts.Success)
 }
@@ -190,6 +209,7 @@ func (a ArtistService) UpdateArtist(artistUpdateDTO *dto.ArtistUpdateDTO) result
 	if err := a.artistRepo.UpdateArtist(&artist, artistUpdateDTO); err != nil {
 		return retErr(consts.Update + consts.Failed)
 	}
+	a.searchService.RefreshArtists(artistUpdateDTO.ArtistID)
 	util.DeleteCacheByPattern("artist:*")
 	return retSuc(consts.Update + consts.Success)
 }
@@ -204,60 +224,40 @@ func (a ArtistService) UpdateArtistAvatar(artistId uint64, avatar string) result
 		}
 		return retErr(consts.InternalError)
 	}
+	oldAvatar := artist.Avatar
 	artist.Avatar = avatar
 	if err := a.artistRepo.UpdateArtist(&artist, map[string]any{"avatar": avatar}); err != nil {
 		return retErr(consts.Update + consts.Failed)
 	}
+	if oldAvatar != "" {
+		if err := a.storageService.DeleteFile(oldAvatar); err != nil {
+			log.Printf("ArtistService.UpdateArtistAvatar err: %v\n", err)
+		}
+	}
+	a.searchService.RefreshArtists(artistId)
 	util.DeleteCacheByPattern("artist:*")
 	return retSuc(consts.Update + consts.Success)
 }
 
 func (a ArtistService) DeleteArtist(artistId uint64) result.Result[result.Nil] {
-	// 1. 查询歌手信息，获取头像 url
-	var retErr = result.Error[result.Nil]
-	var retSuc = result.Success[result.Nil]
 	var artist entity.Artist
 	if err := a.artistRepo.SelectById(&artist, artistId); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return retErr(consts.DataNotFound)
+			return result.Error[result.Nil](consts.DataNotFound)
 		}
-		return retErr(consts.InternalError)
-	}
-	avatarURL := artist.Avatar
-	// 2. 先删除 Minio 的文件
-	if avatarURL != "" {
-		a.minioService.DeleteFile(avatarURL)
+		return result.Error[result.Nil](consts.InternalError)
 	}
-	// 3. 删除数据库记录
-	if err := a.artistRepo.DeleteArtistById(artistId); err != nil {
-		return retErr(consts.Delete + consts.Failed)
-	}
-	// 4. 清除相关缓存
-	util.DeleteCacheByPattern("artist:*")
-	return retSuc(consts.Delete + consts.Success)
+	return a.DeleteArtists([]uint64{artistId})
 }
 
+// DeleteArtists 将歌手移入回收站, 名下仍有未删除歌曲的歌手不允许删除; 专辑随歌手一并隐藏
 func (a ArtistService) DeleteArtists(artistIds []uint64) result.Result[result.Nil] {
-	// 1. 查询歌手信息，获取头像 url
-	var retErr = result.Error[result.Nil]
-	var retSuc = result.Success[result.Nil]
-	var avatars []string
-	if err := a.artistRepo.GetAvatarsByIds(&avatars, artistIds); err != nil {
-		return retErr(consts.InternalError)
-	}
-	// 2. 先删除 Minio 的文件
-	if len(avatars) > 0 {
-		for _, avatarURL := range avatars {
-			if avatarURL != "" {
-				a.minioService.DeleteFile(avatarURL)
-			}
-		}
-	}
-	// 3. 删除数据库记录
-	if err := a.artistRepo.DeleteArtistsByIds(artistIds); err != nil {
-		return retErr(consts.Delete + consts.Failed)
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		return a.deletionService.SoftDelete(uow, "tb_artist", artistIds)
+	})
+	if err != nil {
+		log.Printf("ArtistService.DeleteArtists err: %v\n", err)
+		return result.Error[result.Nil](deleteFailedMessage(err))
 	}
-	// 4. 清除相关缓存
-	util.DeleteCacheByPattern("artist:*")
-	return retSuc(consts.Delete + consts.Success)
+	return result.Success[result.Nil](consts.Delete + consts.Success)
 }
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/repo"
+)
+
+const (
+	auditQueueSize   = 1024
+	auditBatchSize   = 100
+	auditExportLimit = 10000
+)
+
+// AuditRequest 中间件收集的一次管理端写请求
+type AuditRequest struct {
+	AdminID   uint64
+	AdminName string
+	Action    string // 处理请求的方法名, 如 UpdateSong
+	Method    string
+	Path      string
+	Params    map[string]string // 路径参数
+	Body      []byte            // JSON 请求体, 其他类型的请求体不记录
+	IP        string
+	UserAgent string
+}
+
+type auditTarget struct {
+	entity    string // 实体类型
+	table     string // 取修改前后快照的表, 为空时只记录 id
+	field     string // 请求体中实体 id 所在的字段; 为空时取路径参数 id, 或请求体本身即为 id 数组
+	typeField string // 请求体中实体类型所在的字段, 用于回收站这类跨类型的操作
+}
+
+// auditTargets 各写操作修改的实体, 键为处理请求的方法名; 未列出的写操作只记录请求本身
+var auditTargets = map[string]auditTarget{
+	"AddUser":          {entity: "user", table: "tb_user"},
+	"UpdateUser":       {entity: "user", table: "tb_user", field: "userId"},
+	"UpdateUserStatus": {entity: "user", table: "tb_user"},
+	"DeleteUser":       {entity: "user", table: "tb_user"},
+	"DeleteUsers":      {entity: "user", table: "tb_user"},
+
+	"AddArtist":          {entity: "artist", table: "tb_artist"},
+	"UpdateArtist":       {entity: "artist", table: "tb_artist", field: "artistId"},
+	"UpdateArtistAvatar": {entity: "artist", table: "tb_artist"},
+	"DeleteArtist":       {entity: "artist", table: "tb_artist"},
+	"DeleteArtists":      {entity: "artist", table: "tb_artist"},
+
+	"AddSong":                 {entity: "song", table: "tb_song"},
+	"UpdateSong":              {entity: "song", table: "tb_song", field: "songId"},
+	"UpdateSongCover":         {entity: "song", table: "tb_song"},
+	"UpdateSongLyric":         {entity: "song", table: "tb_song", field: "songId"},
+	"UploadSongLyric":         {entity: "song", table: "tb_song"},
+	"UpdateSongAudio":         {entity: "song", table: "tb_song"},
+	"InitSongAudioUpload":     {entity: "song", field: "songId"},
+	"UploadSongAudioPart":     {entity: "upload"},
+	"CompleteSongAudioUpload": {entity: "upload"},
+	"AbortSongAudioUpload":    {entity: "upload"},
+	"UploadSongRendition":     {entity: "song"},
+	"DeleteSongRendition":     {entity: "rendition", table: "tb_song_rendition"},
+	"ApplySongAudioMeta":      {entity: "song", table: "tb_song", field: "songId"},
+	"DiscardSongAudioMeta":    {entity: "song"},
+	"DeleteSong":              {entity: "song", table: "tb_song"},
+	"DeleteSongs":             {entity: "song", table: "tb_song"},
+
+	"AddAlbum":          {entity: "album", table: "tb_album"},
+	"UpdateAlbum":       {entity: "album", table: "tb_album", field: "albumId"},
+	"UpdateAlbumCover":  {entity: "album", table: "tb_album"},
+	"UpdateAlbumTracks": {entity: "album", table: "tb_album", field: "albumId"},
+	"DeleteAlbum":       {entity: "album", table: "tb_album"},
+	"DeleteAlbums":      {entity: "album", table: "tb_album"},
+
+	"AddStyle":    {entity: "style", table: "tb_style"},
+	"UpdateStyle": {entity: "style", table: "tb_style", field: "styleId"},
+	"MergeStyles": {entity: "style", table: "tb_style", field: "sourceIds"},
+	"DeleteStyle": {entity: "style", table: "tb_style"},
+
+	"AddPlaylist":         {entity: "playlist", table: "tb_playlist"},
+	"UpdatePlaylist":      {entity: "playlist", table: "tb_playlist", field: "playlistId"},
+	"UpdatePlaylistCover": {entity: "playlist", table: "tb_playlist"},
+	"DeletePlaylist":      {entity: "playlist", table: "tb_playlist"},
+	"DeletePlaylists":     {entity: "playlist", table: "tb_playlist"},
+
+	"AddBanner":          {entity: "banner", table: "tb_banner"},
+	"UpdateBanner":       {entity: "banner", table: "tb_banner"},
+	"UpdateBannerStatus": {entity: "banner", table: "tb_banner"},
+	"DeleteBanner":       {entity: "banner", table: "tb_banner"},
+	"DeleteBanners":      {entity: "banner", table: "tb_banner"},
+
+	"DeleteFeedback":  {entity: "feedback", table: "tb_feedback"},
+	"DeleteFeedbacks": {entity: "feedback", table: "tb_feedback"},
+
+	"CleanStorageOrphans": {entity: "storage"},
+	"RestoreTrash":        {field: "ids", typeField: "type"},
+	"PurgeTrash":          {field: "ids", typeField: "type"},
+}
+
+// 用 POST 提交查询条件、不修改数据的操作
+var auditReadOnly = map[string]bool{
+	"PreviewDelete": true,
+}
+
+// 请求体中不记录的字段
+var auditOmitFields = []string{"password", "oldPassword", "newPassword", "repeatPassword", "verificationCode"}
+
+// AuditService 记录管理端写请求: 操作人、操作、实体及其修改前后的差异, 由后台协程批量写入
+type AuditService struct {
+	auditLogRepo *repo.AuditLogRepo
+	logs         chan entity.AuditLog
+}
+
+func NewAuditService(auditLogRepo *repo.AuditLogRepo) *AuditService {
+	return &AuditService{
+		auditLogRepo: auditLogRepo,
+		logs:         make(chan entity.AuditLog, auditQueueSize),
+	}
+}
+
+// Audited 是否需要记录该请求; GET 与以 Get 开头的查询操作不记录
+func (a AuditService) Audited(method, action string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return false
+	}
+	return action != "" && !strings.HasPrefix(action, "Get") && !auditReadOnly[action]
+}
+
+// Begin 在处理请求前取出被修改实体的快照; 返回的函数在处理完成后调用, 对比快照并异步写入审计记录
+func (a AuditService) Begin(req AuditRequest) func(status int, response []byte) {
+	target := auditTargets[req.Action]
+	entityType, table := target.entity, target.table
+	body := parseAuditBody(req.Body)
+	if target.typeField != "" {
+		if fields, ok := body.(map[string]any); ok {
+			entityType, _ = fields[target.typeField].(string)
+			if src, ok := trashSource(entityType); ok {
+				table = src.Table
+			}
+		}
+	}
+	ids := auditIds(target, req.Params, body)
+	var before map[uint64]map[string]any
+	if table != "" && len(ids) > 0 {
+		before = a.snapshot(table, ids)
+	}
+	createTime := time.Now()
+
+	return func(status int, response []byte) {
+		auditLog := entity.AuditLog{
+			AdminID:    req.AdminID,
+			AdminName:  req.AdminName,
+			Action:     req.Action,
+			Method:     req.Method,
+			Path:       truncateBytes(req.Path, 255),
+			EntityType: entityType,
+			EntityIDs:  joinAuditIds(ids, 1000),
+			Status:     status,
+			IP:         truncateBytes(req.IP, 64),
+			UserAgent:  truncateBytes(req.UserAgent, 255),
+			CreateTime: createTime,
+		}
+		if code, message, ok := parseResultHead(response); ok {
+			auditLog.Success = status < 400 && code == 0
+			auditLog.Message = truncateBytes(message, 255)
+		}
+		var changes []vo.AuditChangeVO
+		switch {
+		case table != "" && len(ids) > 0:
+			// 失败的请求不会修改数据, 不再取一次快照
+			if auditLog.Success {
+				changes = diffSnapshots(ids, before, a.snapshot(table, ids))
+			}
+		case len(ids) == 0:
+			// 新增时还没有 id, 记录提交的内容
+			if fields, ok := body.(map[string]any); ok && auditLog.Success {
+				changes = []vo.AuditChangeVO{{After: fields}}
+			}
+		}
+		if len(changes) > 0 {
+			if diff, err := json.Marshal(changes); err == nil {
+				auditLog.Diff = string(diff)
+			}
+		}
+		a.enqueue(auditLog)
+	}
+}
+
+// Run 批量写入审计记录, 攒满一批或每秒写入一次
+func (a AuditService) Run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	batch := make([]entity.AuditLog, 0, auditBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := a.auditLogRepo.AddAuditLogs(batch); err != nil {
+			log.Printf("AuditService.Run err: %v\n", err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case auditLog := <-a.logs:
+			batch = append(batch, auditLog)
+			if len(batch) >= auditBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (a AuditService) enqueue(auditLog entity.AuditLog) {
+	select {
+	case a.logs <- auditLog:
+	default:
+		// 队列已满时在请求中同步写入, 审计记录不能丢
+		if err := a.auditLogRepo.AddAuditLogs([]entity.AuditLog{auditLog}); err != nil {
+			log.Printf("AuditService.enqueue err: %v\n", err)
+		}
+	}
+}
+
+// snapshot 取出 ids 行的快照, 出错时返回 nil, 不影响请求本身
+func (a AuditService) snapshot(table string, ids []uint64) map[uint64]map[string]any {
+	var rows []map[string]any
+	if err := a.auditLogRepo.GetSnapshots(&rows, table, ids); err != nil {
+		log.Printf("AuditService.snapshot err: %v\n", err)
+		return nil
+	}
+	snapshots := make(map[uint64]map[string]any, len(rows))
+	for _, row := range rows {
+		if id, err := strconv.ParseUint(fmt.Sprint(row["id"]), 10, 64); err == nil {
+			snapshots[id] = row
+		}
+	}
+	return snapshots
+}
+
+func (a AuditService) GetAuditLogs(auditLogDTO *dto.AuditLogDTO) result.Result[result.PageResult[vo.AuditLogVO]] {
+	pageNum, pageSize := auditLogDTO.PageNum, auditLogDTO.PageSize
+	if pageNum == 0 {
+		pageNum = 1
+	}
+	if pageSize == 0 {
+		pageSize = 20
+	}
+	var logs result.PageResult[entity.AuditLog]
+	if err := a.auditLogRepo.GetAuditLogs(&logs, auditLogDTO, (pageNum-1)*pageSize, pageSize); err != nil {
+		log.Printf("AuditService.GetAuditLogs err: %v\n", err)
+		return result.Error[result.PageResult[vo.AuditLogVO]](consts.InternalError)
+	}
+	data := result.PageResult[vo.AuditLogVO]{Total: logs.Total, Items: make([]vo.AuditLogVO, 0, len(logs.Items))}
+	for _, auditLog := range logs.Items {
+		data.Items = append(data.Items, toAuditLogVO(auditLog))
+	}
+	return result.SuccessWithData(consts.Success, data)
+}
+
+// ExportAuditLogs 按查询条件导出最近的至多 auditExportLimit 条记录
+func (a AuditService) ExportAuditLogs(auditLogDTO *dto.AuditLogDTO) result.Result[[]vo.AuditLogVO] {
+	var logs []entity.AuditLog
+	if err := a.auditLogRepo.GetAuditLogsForExport(&logs, auditLogDTO, auditExportLimit); err != nil {
+		log.Printf("AuditService.ExportAuditLogs err: %v\n", err)
+		return result.Error[[]vo.AuditLogVO](consts.InternalError)
+	}
+	data := make([]vo.AuditLogVO, 0, len(logs))
+	for _, auditLog := range logs {
+		data = append(data, toAuditLogVO(auditLog))
+	}
+	return result.SuccessWithData(consts.Success, data)
+}
+
+// WriteAuditLogsCSV 以 CSV 写出审计记录, 带 BOM 以便表格软件识别 UTF-8
+func WriteAuditLogsCSV(w io.Writer, logs []vo.AuditLogVO) error {
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "createTime", "adminId", "adminName", "action", "method", "path",
+		"entityType", "entityIds", "success", "status", "message", "ip", "userAgent", "diff"})
+	for _, l := range logs {
+		_ = cw.Write([]string{
+			strconv.FormatUint(l.ID, 10),
+			l.CreateTime.Format(time.DateTime),
+			strconv.FormatUint(l.AdminID, 10),
+			l.AdminName,
+			l.Action,
+			l.Method,
+			l.Path,
+			l.EntityType,
+			joinAuditIds(l.EntityIDs, 0),
+			strconv.FormatBool(l.Success),
+			strconv.Itoa(l.Status),
+			l.Message,
+			l.IP,
+			l.UserAgent,
+			string(l.Diff),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func toAuditLogVO(auditLog entity.AuditLog) vo.AuditLogVO {
+	data := vo.AuditLogVO{
+		ID:         auditLog.ID,
+		AdminID:    auditLog.AdminID,
+		AdminName:  auditLog.AdminName,
+		Action:     auditLog.Action,
+		Method:     auditLog.Method,
+		Path:       auditLog.Path,
+		EntityType: auditLog.EntityType,
+		EntityIDs:  []uint64{},
+		Diff:       json.RawMessage("[]"),
+		Status:     auditLog.Status,
+		Success:    auditLog.Success,
+		Message:    auditLog.Message,
+		IP:         auditLog.IP,
+		UserAgent:  auditLog.UserAgent,
+		CreateTime: auditLog.CreateTime,
+	}
+	for _, s := range strings.Split(auditLog.EntityIDs, ",") {
+		if id, err := strconv.ParseUint(s, 10, 64); err == nil {
+			data.EntityIDs = append(data.EntityIDs, id)
+		}
+	}
+	if auditLog.Diff != "" {
+		data.Diff = json.RawMessage(auditLog.Diff)
+	}
+	return data
+}
+
+// parseAuditBody 解析 JSON 请求体并去掉密码等字段, 无法解析时返回 nil
+func parseAuditBody(body []byte) any {
+	if len(body) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil
+	}
+	if fields, ok := v.(map[string]any); ok {
+		for _, field := range auditOmitFields {
+			delete(fields, field)
+		}
+	}
+	return v
+}
+
+// auditIds 从请求体字段、路径参数 id 或 id 数组请求体中取出实体 id
+func auditIds(target auditTarget, params map[string]string, body any) []uint64 {
+	var v any
+	switch {
+	case target.field != "":
+		if fields, ok := body.(map[string]any); ok {
+			v = fields[target.field]
+		}
+	case params["id"] != "":
+		v = json.Number(params["id"])
+	default:
+		v = body
+	}
+	var ids []uint64
+	appendId := func(v any) {
+		if n, ok := v.(json.Number); ok {
+			if id, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if values, ok := v.([]any); ok {
+		for _, value := range values {
+			appendId(value)
+		}
+	} else {
+		appendId(v)
+	}
+	return ids
+}
+
+// diffSnapshots 对比请求前后的快照, 只保留变化的字段; 行被删除时记录删除前的全部字段, 新出现的行记录全部字段
+func diffSnapshots(ids []uint64, before, after map[uint64]map[string]any) []vo.AuditChangeVO {
+	var changes []vo.AuditChangeVO
+	for _, id := range ids {
+		b, a := before[id], after[id]
+		switch {
+		case b == nil && a == nil:
+			continue
+		case a == nil:
+			changes = append(changes, vo.AuditChangeVO{ID: id, Before: b})
+		case b == nil:
+			changes = append(changes, vo.AuditChangeVO{ID: id, After: a})
+		default:
+			change := vo.AuditChangeVO{ID: id, Before: map[string]any{}, After: map[string]any{}}
+			for column, value := range a {
+				if old, ok := b[column]; !ok || fmt.Sprint(old) != fmt.Sprint(value) {
+					change.Before[column] = b[column]
+					change.After[column] = value
+				}
+			}
+			if len(change.After) > 0 {
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes
+}
+
+// parseResultHead 从响应开头读出统一返回结果的 code 与 message, 响应可能被截断
+func parseResultHead(response []byte) (code int, message string, ok bool) {
+	decoder := json.NewDecoder(bytes.NewReader(response))
+	if t, err := decoder.Token(); err != nil || t != json.Delim('{') {
+		return 0, "", false
+	}
+	var hasCode, hasMessage bool
+	for decoder.More() && !(hasCode && hasMessage) {
+		t, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch t {
+		case "code":
+			hasCode = decoder.Decode(&code) == nil
+		case "message":
+			hasMessage = decoder.Decode(&message) == nil
+		default:
+			var skip json.RawMessage
+			if decoder.Decode(&skip) != nil {
+				return code, message, hasCode
+			}
+		}
+	}
+	return code, message, hasCode
+}
+
+// joinAuditIds 以逗号连接 ids, limit 大于 0 时截断到不超过 limit 个字符
+func joinAuditIds(ids []uint64, limit int) string {
+	var sb strings.Builder
+	for i, id := range ids {
+		s := strconv.FormatUint(id, 10)
+		if i > 0 {
+			s = "," + s
+		}
+		if limit > 0 && sb.Len()+len(s) > limit {
+			break
+		}
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// 不截断多字节字符
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,548 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+	"vibe-music-server/internal/config"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/storage"
+	"vibe-music-server/internal/repo"
+)
+
+const (
+	backupFormat       = "vibe-music-backup"
+	backupVersion      = 1
+	backupManifestName = "manifest.json"
+	backupDataDir      = "data/"
+	backupMediaDir     = "media/"
+	backupSuffix       = ".tar.gz"
+	backupPartial      = ".partial"
+	backupObjectPrefix = "vibe-object:" // 指向本存储的文件地址导出为对象名, 恢复时按目标存储重新生成地址
+	backupTimeLayout   = "2006-01-02 15:04:05.000"
+	backupDateLayout   = "2006-01-02"
+	backupInsertBatch  = 200
+	backupContentType  = "VIBEMUSIC.content-type" // 媒体条目中记录对象类型的 PAX 扩展头
+	backupObjectTable  = "tb_storage_object"      // 放在最后, 只导出被备份数据引用的对象记录
+)
+
+var (
+	// 按外键依赖排序: 恢复时依次写入, 清空时逆序删除
+	backupCatalogTables = []string{"tb_style", "tb_artist", "tb_album", "tb_song", "tb_song_artist", "tb_genre",
+		"tb_song_audio_meta", "tb_song_rendition", "tb_playlist", "tb_playlist_binding", "tb_banner"}
+	backupUserTables = []string{"tb_user", "tb_user_favorite", "tb_comment"}
+)
+
+// backupManifest 备份的第一个条目, 格式或版本不符时拒绝恢复
+type backupManifest struct {
+	Format       string        `json:"format"`
+	Version      int           `json:"version"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	IncludeUsers bool          `json:"includeUsers"`
+	Tables       []backupTable `json:"tables"`
+}
+
+type backupTable struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// BackupService 导出目录与用户数据及其引用的媒体文件为 tar.gz 备份, 并可由 cmd/restore 恢复到空的数据库与存储.
+// 备份依次包含 manifest.json、每张表一个 data/<表名>.jsonl(每行一个 JSON 对象)、media/<对象名>
+type BackupService struct {
+	backupRepo     *repo.BackupRepo
+	storageService *StorageService
+	running        *atomic.Bool
+}
+
+func NewBackupService(backupRepo *repo.BackupRepo, storageService *StorageService) *BackupService {
+	return &BackupService{
+		backupRepo:     backupRepo,
+		storageService: storageService,
+		running:        &atomic.Bool{},
+	}
+}
+
+// StartBackup 在后台导出备份到配置 backup.dir, 同一时间只运行一个导出
+func (s BackupService) StartBackup(backupDTO *dto.BackupDTO) result.Result[vo.BackupVO] {
+	if !s.running.CompareAndSwap(false, true) {
+		return result.Error[vo.BackupVO](consts.BackupRunning)
+	}
+	dir := backupDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		s.running.Store(false)
+		log.Printf("BackupService.StartBackup err: %v\n", err)
+		return result.Error[vo.BackupVO](consts.InternalError)
+	}
+	now := time.Now()
+	name := "backup-" + now.Format("20060102-150405") + backupSuffix
+	go func() {
+		defer s.running.Store(false)
+		if err := s.export(filepath.Join(dir, name), backupDTO.IncludeUsers); err != nil {
+			log.Printf("BackupService.StartBackup err: %v\n", err)
+			return
+		}
+		log.Printf("backup: %s done\n", name)
+	}()
+	return result.SuccessWithData(consts.Success, vo.BackupVO{Name: name, Status: "running", CreateTime: now})
+}
+
+// GetBackups 列出备份目录中的备份, 最新的在前
+func (s BackupService) GetBackups() result.Result[[]vo.BackupVO] {
+	entries, err := os.ReadDir(backupDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("BackupService.GetBackups err: %v\n", err)
+		return result.Error[[]vo.BackupVO](consts.InternalError)
+	}
+	data := []vo.BackupVO{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		backup := vo.BackupVO{Name: entry.Name(), Size: info.Size(), Status: "done", CreateTime: info.ModTime()}
+		if name, ok := strings.CutSuffix(entry.Name(), backupPartial); ok {
+			// 未在导出却留有未完成的文件, 说明导出被服务重启打断
+			backup.Name, backup.Status = name, "failed"
+			if s.running.Load() {
+				backup.Status = "running"
+			}
+		}
+		if !strings.HasSuffix(backup.Name, backupSuffix) {
+			continue
+		}
+		data = append(data, backup)
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i].CreateTime.After(data[j].CreateTime) })
+	return result.SuccessWithData(consts.Success, data)
+}
+
+// BackupPath 已完成的备份文件的路径, 名称不合法或不存在时返回 false
+func (s BackupService) BackupPath(name string) (string, bool) {
+	if name != filepath.Base(name) || !strings.HasSuffix(name, backupSuffix) {
+		return "", false
+	}
+	file := filepath.Join(backupDir(), name)
+	if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return file, true
+}
+
+// export 先将各表写入临时文件以得到条目大小, 再依次写入清单、数据与媒体; 完成后才从 .partial 改为正式文件名
+func (s BackupService) export(file string, includeUsers bool) error {
+	tmpDir, err := os.MkdirTemp("", "vibe-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// 1. 在同一快照中导出数据, 各表之间的引用保持一致; 收集引用到的对象
+	manifest := backupManifest{Format: backupFormat, Version: backupVersion, CreatedAt: time.Now(), IncludeUsers: includeUsers}
+	objects := map[string]bool{}
+	err = db.Snapshot(func(uow *db.UnitOfWork) error {
+		backupRepo := s.backupRepo.WithTx(uow)
+		for _, table := range backupTables(includeUsers) {
+			convert := s.exportRefs(table, objects)
+			if table == backupObjectTable {
+				convert = func(row map[string]any) bool {
+					key, _ := row["object_key"].(string)
+					return objects[key]
+				}
+			}
+			rows, err := exportTable(backupRepo, filepath.Join(tmpDir, table+".jsonl"), table, convert)
+			if err != nil {
+				return fmt.Errorf("export %s: %w", table, err)
+			}
+			manifest.Tables = append(manifest.Tables, backupTable{Name: table, Rows: rows})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 2. 写入归档
+	partial := file + backupPartial
+	out, err := os.Create(partial)
+	if err != nil {
+		return err
+	}
+	if err := s.writeArchive(out, tmpDir, manifest, objects); err != nil {
+		out.Close()
+		os.Remove(partial)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(partial)
+		return err
+	}
+	return os.Rename(partial, file)
+}
+
+// exportRefs 将行中指向本存储的文件地址改写为对象名, 并记录被引用的对象
+func (s BackupService) exportRefs(table string, objects map[string]bool) func(row map[string]any) bool {
+	columns := repo.StorageURLColumns(table)
+	if table == "tb_song_audio_meta" {
+		// 提取来源不单独占用引用, 但同样需要随存储改写地址
+		columns = append(columns, "audio_url")
+	}
+	return func(row map[string]any) bool {
+		for _, column := range columns {
+			fileURL, _ := row[column].(string)
+			if fileURL == "" {
+				continue
+			}
+			key, err := s.storageService.ObjectName(fileURL)
+			if err != nil {
+				// 外部地址按原样保留
+				continue
+			}
+			row[column] = backupObjectPrefix + key
+			objects[key] = true
+		}
+		if table == "tb_song_rendition" {
+			if key, _ := row["object_key"].(string); key != "" {
+				objects[key] = true
+			}
+		}
+		return true
+	}
+}
+
+// exportTable 将表中每行经 convert 处理后写为一行 JSON, convert 返回 false 时跳过该行
+func exportTable(backupRepo *repo.BackupRepo, file, table string, convert func(row map[string]any) bool) (int64, error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	var rows int64
+	err = backupRepo.EachRow(table, func(row map[string]any) error {
+		if !convert(row) {
+			return nil
+		}
+		for column, v := range row {
+			row[column] = backupValue(v)
+		}
+		rows++
+		return enc.Encode(row)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rows, f.Close()
+}
+
+func (s BackupService) writeArchive(out io.Writer, tmpDir string, manifest backupManifest, objects map[string]bool) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: backupManifestName, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.CreatedAt}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, table := range manifest.Tables {
+		if err := addTarFile(tw, filepath.Join(tmpDir, table.Name+".jsonl"), backupDataDir+table.Name+".jsonl"); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		objectKeys, err := s.storageService.objectKeys(key)
+		if err != nil {
+			return fmt.Errorf("export object %s: %w", key, err)
+		}
+		for _, objectKey := range objectKeys {
+			if err := s.addTarObject(tw, objectKey); err != nil {
+				if !errors.Is(err, storage.ErrNotFound) {
+					return fmt.Errorf("export object %s: %w", objectKey, err)
+				}
+				// 原对象缺失是悬空引用, 记录后继续
+				log.Printf("backup: object %s not found, skipped\n", objectKey)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func addTarFile(tw *tar.Writer, file, name string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func (s BackupService) addTarObject(tw *tar.Writer, key string) error {
+	obj, info, err := s.storageService.GetObject(key)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	header := &tar.Header{
+		Name:       backupMediaDir + key,
+		Mode:       0o644,
+		Size:       info.Size,
+		ModTime:    info.LastModified,
+		Format:     tar.FormatPAX,
+		PAXRecords: map[string]string{backupContentType: info.ContentType},
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, obj)
+	return err
+}
+
+// Restore 将备份恢复到当前配置的数据库与存储, 供 cmd/restore 使用.
+// 备份涉及的表须为空; truncate 为 true 时先在同一事务中清空这些表(外键级联照常生效). 先写入媒体, 数据写入失败时整体回滚,
+// 已写入的媒体成为孤儿对象, 由存储对账清理
+func (s BackupService) Restore(file string, truncate bool) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	// 1. 校验清单, 确认目标表可写入
+	header, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+	if header.Name != backupManifestName {
+		return fmt.Errorf("not a backup: first entry is %s", header.Name)
+	}
+	var manifest backupManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+	if manifest.Format != backupFormat || manifest.Version != backupVersion {
+		return fmt.Errorf("unsupported backup format %q version %d", manifest.Format, manifest.Version)
+	}
+	known := backupTables(true)
+	tables := make([]string, 0, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		if !slices.Contains(known, table.Name) {
+			return fmt.Errorf("unknown table %s in backup", table.Name)
+		}
+		tables = append(tables, table.Name)
+	}
+	if !truncate {
+		for _, table := range tables {
+			var count int64
+			if err := s.backupRepo.CountRows(&count, table); err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("table %s is not empty (%d rows), restore into an empty database or truncate", table, count)
+			}
+		}
+	}
+
+	// 2. 数据解出到临时目录, 媒体直接写入存储
+	tmpDir, err := os.MkdirTemp("", "vibe-restore-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	objects := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read backup: %w", err)
+		}
+		if name, ok := strings.CutPrefix(header.Name, backupDataDir); ok {
+			table := strings.TrimSuffix(name, ".jsonl")
+			if !slices.Contains(tables, table) {
+				return fmt.Errorf("unexpected entry %s in backup", header.Name)
+			}
+			if err := saveTarEntry(tr, filepath.Join(tmpDir, table+".jsonl")); err != nil {
+				return err
+			}
+		} else if key, ok := strings.CutPrefix(header.Name, backupMediaDir); ok {
+			if err := s.storageService.PutObject(key, tr, header.Size, header.PAXRecords[backupContentType]); err != nil {
+				return fmt.Errorf("restore object %s: %w", key, err)
+			}
+			objects++
+		}
+	}
+	log.Printf("restore: %d objects written\n", objects)
+
+	// 3. 按依赖顺序写入数据
+	return db.Transaction(func(uow *db.UnitOfWork) error {
+		backupRepo := s.backupRepo.WithTx(uow)
+		if truncate {
+			for i := len(tables) - 1; i >= 0; i-- {
+				if err := backupRepo.DeleteRows(tables[i]); err != nil {
+					return fmt.Errorf("truncate %s: %w", tables[i], err)
+				}
+			}
+		}
+		for _, table := range tables {
+			rows, err := s.restoreTable(backupRepo, filepath.Join(tmpDir, table+".jsonl"), table)
+			if err != nil {
+				return fmt.Errorf("restore %s: %w", table, err)
+			}
+			log.Printf("restore: %s %d rows\n", table, rows)
+		}
+		return nil
+	})
+}
+
+func (s BackupService) restoreTable(backupRepo *repo.BackupRepo, file, table string) (int, error) {
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	total := 0
+	batch := make([]map[string]any, 0, backupInsertBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := backupRepo.InsertRows(table, batch); err != nil {
+			return err
+		}
+		total += len(batch)
+		batch = make([]map[string]any, 0, backupInsertBatch)
+		return nil
+	}
+	for {
+		var row map[string]any
+		if err := dec.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		for column, v := range row {
+			row[column] = s.restoreValue(v)
+		}
+		if batch = append(batch, row); len(batch) >= backupInsertBatch {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// restoreValue 还原 backupValue 的导出形式: 整数按整数写入, 对象名按目标存储生成地址, 其余原样交给数据库转换
+func (s BackupService) restoreValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		return v.String()
+	case string:
+		if key, ok := strings.CutPrefix(v, backupObjectPrefix); ok {
+			return s.storageService.ObjectURL(key)
+		}
+	}
+	return v
+}
+
+// backupValue 导出时统一值的形式: 时间按数据库可直接写回的格式, 零点的时间只保留日期
+func backupValue(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return "0000-00-00 00:00:00"
+		}
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format(backupDateLayout)
+		}
+		return v.Format(backupTimeLayout)
+	case []byte:
+		return string(v)
+	}
+	return v
+}
+
+func saveTarEntry(r io.Reader, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// backupTables 按恢复顺序列出备份的表
+func backupTables(includeUsers bool) []string {
+	tables := slices.Clone(backupCatalogTables)
+	if includeUsers {
+		tables = append(tables, backupUserTables...)
+	}
+	return append(tables, backupObjectTable)
+}
+
+func backupDir() string {
+	if dir := config.Get().Backup.Dir; dir != "" {
+		return dir
+	}
+	return "backups"
+}
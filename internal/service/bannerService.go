@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
+	"log"
 	"vibe-music-server/internal/model/dto"
 	"vibe-music-server/internal/model/entity"
 	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
 	"vibe-music-server/internal/pkg/result"
 	"vibe-music-server/internal/pkg/result/consts"
 	"vibe-music-server/internal/pkg/util"
@@ -14,14 +16,16 @@ import (
 )
 
 type BannerService struct {
-	bannerRepo   *repo.BannerRepo
-	minioService *MinioService
+	bannerRepo      *repo.BannerRepo
+	storageService  *StorageService
+	deletionService *DeletionService
 }
 
-func NewBannerService(bannerRepo *repo.BannerRepo, minioService *MinioService) *BannerService {
+func NewBannerService(bannerRepo *repo.BannerRepo, storageService *StorageService, deletionService *DeletionService) *BannerService {
 	return &BannerService{
-		bannerRepo:   bannerRepo,
-		minioService: minioService,
+		bannerRepo:      bannerRepo,
+		storageService:  storageService,
+		deletionService: deletionService,
 	}
 }
 
@@ -73,7 +77,7 @@ func (b BannerService) UpdateBanner(bannerId uint64, bannerUrl string) result.Re
 		return retErr(consts.InternalError)
 	}
 	// 删除旧图
-	b.minioService.DeleteFile(banner.BannerURL)
+	b.storageService.DeleteFile(banner.BannerURL)
 	banner.BannerURL = bannerUrl
 	if err := b.bannerRepo.UpdateBanner(&banner); err != nil {
 		return retErr(consts.Update + consts.Failed)
@@ -102,7 +106,6 @@ func (b BannerService) UpdateBannerStatus(bannerId uint64, bannerStatus entity.B
 
 func (b BannerService) DeleteBanner(bannerId uint64) result.Result[result.Nil] {
 	var retErr = result.Error[result.Nil]
-	var retSuc = result.Success[result.Nil]
 	var banner entity.Banner
 	if err := b.bannerRepo.GetBannerById(&banner, bannerId); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -110,34 +113,19 @@ func (b BannerService) DeleteBanner(bannerId uint64) result.Result[result.Nil] {
 		}
 		return retErr(consts.InternalError)
 	}
-	// 删除旧图
-	b.minioService.DeleteFile(banner.BannerURL)
-	if err := b.bannerRepo.DeleteBannerById(bannerId); err != nil {
-		return retErr(consts.Delete + consts.Failed)
-	}
-	util.DeleteCacheByPattern("banner:*")
-	return retSuc(consts.Delete + consts.Success)
+	return b.DeleteBanners([]uint64{bannerId})
 }
 
+// DeleteBanners 将轮播图移入回收站, 图片在回收站中清除时才删除
 func (b BannerService) DeleteBanners(bannerIds []uint64) result.Result[result.Nil] {
-	var retErr = result.Error[result.Nil]
-	var retSuc = result.Success[result.Nil]
-	var banners []entity.Banner
-	if err := b.bannerRepo.GetBannerByIds(&banners, bannerIds); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return retErr(consts.DataNotFound)
-		}
-		return retErr(consts.InternalError)
-	}
-	// 删除旧图
-	for _, banner := range banners {
-		b.minioService.DeleteFile(banner.BannerURL)
-	}
-	if err := b.bannerRepo.DeleteBannerByIds(bannerIds); err != nil {
-		return retErr(consts.Delete + consts.Failed)
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		return b.deletionService.SoftDelete(uow, "tb_banner", bannerIds)
+	})
+	if err != nil {
+		log.Printf("BannerService.DeleteBanners err: %v\n", err)
+		return result.Error[result.Nil](deleteFailedMessage(err))
 	}
-	util.DeleteCacheByPattern("banner:*")
-	return retSuc(consts.Delete + consts.Success)
+	return result.Success[result.Nil](consts.Delete + consts.Success)
 }
 
 func (b BannerService) GetBannerList() result.Result[[]vo.BannerVO] {
@@ -155,5 +143,6 @@ func (b BannerService) GetBannerList() result.Result[[]vo.BannerVO] {
 		}
 		return retErr(consts.InternalError)
 	}
+	b.storageService.FillSrcsets(&banners)
 	return retSuc(consts.Success, banners)
 }
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/repo"
+)
+
+// 删除时对引用行的处理方式
+const (
+	deleteCascade = "cascade" // 引用行随之删除
+	deleteNullify = "nullify" // 引用列置空, 保留引用行
+	deleteBlock   = "block"   // 存在引用行时拒绝删除
+)
+
+var (
+	errDeleteBlocked  = errors.New("delete blocked by references")
+	errRestoreBlocked = errors.New("restore blocked by trashed parent")
+)
+
+type deleteRelation struct {
+	table   string
+	column  string
+	action  string
+	updates map[string]any // nullify 时更新的列, 为空时只置空引用列
+}
+
+// deletePolicies 每张表被删除时对各引用方的处理, 与 scripts/migrations/011_delete_policy.sql 中的外键一致.
+// 有自身策略的表被级联删除时按其策略继续处理
+var deletePolicies = map[string][]deleteRelation{
+	"tb_song": {
+		{table: "tb_user_favorite", column: "song_id", action: deleteCascade},
+		{table: "tb_playlist_binding", column: "song_id", action: deleteCascade},
+		{table: "tb_comment", column: "song_id", action: deleteCascade},
+		{table: "tb_genre", column: "song_id", action: deleteCascade},
+		{table: "tb_song_artist", column: "song_id", action: deleteCascade},
+		{table: "tb_song_audio_meta", column: "song_id", action: deleteCascade},
+		{table: "tb_song_rendition", column: "song_id", action: deleteCascade},
+		{table: "tb_upload_session", column: "song_id", action: deleteCascade},
+		{table: "tb_scan_file", column: "song_id", action: deleteNullify},
+	},
+	"tb_playlist": {
+		{table: "tb_playlist_binding", column: "playlist_id", action: deleteCascade},
+		{table: "tb_user_favorite", column: "playlist_id", action: deleteCascade},
+		{table: "tb_comment", column: "playlist_id", action: deleteCascade},
+	},
+	// 歌手名下仍有歌曲时不允许删除, 需先删除或改挂歌曲
+	"tb_artist": {
+		{table: "tb_song", column: "artist_id", action: deleteBlock},
+		{table: "tb_album", column: "artist_id", action: deleteCascade},
+		{table: "tb_song_artist", column: "artist_id", action: deleteCascade},
+		{table: "tb_user_favorite", column: "artist_id", action: deleteCascade},
+	},
+	// 专辑删除后歌曲保留, 仅解除专辑关联
+	"tb_album": {
+		{table: "tb_song", column: "album_id", action: deleteNullify,
+			updates: map[string]any{"album_id": nil, "album": "", "track_number": 0}},
+		{table: "tb_user_favorite", column: "album_id", action: deleteCascade},
+	},
+	// 用户的反馈保留为匿名反馈
+	"tb_user": {
+		{table: "tb_comment", column: "user_id", action: deleteCascade},
+		{table: "tb_user_favorite", column: "user_id", action: deleteCascade},
+		{table: "tb_feedback", column: "user_id", action: deleteNullify},
+	},
+}
+
+// 预演接口的删除对象与表的对应
+var deleteTargets = map[string]string{
+	"song":     "tb_song",
+	"playlist": "tb_playlist",
+	"artist":   "tb_artist",
+	"album":    "tb_album",
+	"user":     "tb_user",
+	"banner":   "tb_banner",
+}
+
+// 表中的行被删除或修改后需要清除的缓存
+var deleteCachePatterns = map[string][]string{
+	"tb_song":             {"song:*", "album:*"},
+	"tb_playlist":         {"playlist:*"},
+	"tb_playlist_binding": {"playlist:*"},
+	"tb_artist":           {"artist:*"},
+	"tb_album":            {"album:*", "song:*"},
+	"tb_user":             {"user:*"},
+	"tb_user_favorite":    {"favorite:*"},
+	"tb_comment":          {"song:*", "playlist:*"},
+	"tb_feedback":         {"feedback:*"},
+	"tb_banner":           {"banner:*"},
+}
+
+// softDeleteTables 管理端删除时先移入回收站的表, 从回收站清除时才按 deletePolicies 删除
+var softDeleteTables = map[string]bool{
+	"tb_song":     true,
+	"tb_artist":   true,
+	"tb_playlist": true,
+	"tb_user":     true,
+	"tb_banner":   true,
+}
+
+// 行移入或移出回收站后需要清除的缓存, 回收站中的行在收藏、歌单等关联查询中同样不可见
+var trashCachePatterns = map[string][]string{
+	"tb_song":     {"song:*", "album:*", "artist:*", "playlist:*", "favorite:*", "style:*"},
+	"tb_artist":   {"artist:*", "album:*", "song:*", "favorite:*"},
+	"tb_playlist": {"playlist:*", "favorite:*"},
+	"tb_user":     {"user:*"},
+	"tb_banner":   {"banner:*"},
+}
+
+// DeletionService 按 deletePolicies 删除行并处理各处引用, 文件、搜索索引与缓存在提交后处理
+type DeletionService struct {
+	deletionRepo   *repo.DeletionRepo
+	storageService *StorageService
+	searchService  *SearchService
+}
+
+func NewDeletionService(deletionRepo *repo.DeletionRepo, storageService *StorageService, searchService *SearchService) *DeletionService {
+	return &DeletionService{
+		deletionRepo:   deletionRepo,
+		storageService: storageService,
+		searchService:  searchService,
+	}
+}
+
+// deletion 一次删除的执行状态; dryRun 时只统计不修改
+type deletion struct {
+	repo    *repo.DeletionRepo
+	dryRun  bool
+	blocked bool
+	effects []vo.DeleteEffectVO
+	urls    []string
+	keys    []string
+	deleted map[string][]uint64 // 被删除的有搜索索引的行
+	updated map[string][]uint64 // 被置空引用的有搜索索引的行
+	touched map[string]bool
+}
+
+// Preview 预演彻底删除 target 类型的 ids, 列出各处关联的处理方式与影响行数
+func (d DeletionService) Preview(target string, ids []uint64) result.Result[vo.DeletePreviewVO] {
+	table, ok := deleteTargets[target]
+	if !ok {
+		return result.Error[vo.DeletePreviewVO](consts.InvalidParams)
+	}
+	data := vo.DeletePreviewVO{Target: target}
+	if err := d.deletionRepo.CountRefs(&data.Count, table, "id", ids); err != nil {
+		log.Printf("DeletionService.Preview err: %v\n", err)
+		return result.Error[vo.DeletePreviewVO](consts.InternalError)
+	}
+	del := d.newDeletion(d.deletionRepo, true)
+	if err := del.apply(table, ids); err != nil {
+		log.Printf("DeletionService.Preview err: %v\n", err)
+		return result.Error[vo.DeletePreviewVO](consts.InternalError)
+	}
+	data.Blocked = del.blocked
+	data.Effects = del.effects
+	if data.Effects == nil {
+		data.Effects = []vo.DeleteEffectVO{}
+	}
+	data.Files = len(del.urls) + len(del.keys)
+	return result.SuccessWithData(consts.Success, data)
+}
+
+// Delete 在工作单元中删除 table 中的 ids 行并按策略处理引用; 存在 block 引用时返回 errDeleteBlocked, 由调用方回滚.
+// 行中保存的文件在提交后释放, 同时更新搜索索引并清除缓存
+func (d DeletionService) Delete(uow *db.UnitOfWork, table string, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	del := d.newDeletion(d.deletionRepo.WithTx(uow), false)
+	if err := del.apply(table, ids); err != nil {
+		return err
+	}
+	for _, url := range del.urls {
+		d.storageService.DeleteFileAfterCommit(uow, url)
+	}
+	for _, key := range del.keys {
+		d.storageService.ReleaseObjectAfterCommit(uow, key)
+	}
+	uow.AfterCommit(func() {
+		d.searchService.RemoveSongs(del.deleted["tb_song"]...)
+		d.searchService.RemoveArtists(del.deleted["tb_artist"]...)
+		d.searchService.RemoveAlbums(del.deleted["tb_album"]...)
+		d.searchService.RemovePlaylists(del.deleted["tb_playlist"]...)
+		if songIds := del.updated["tb_song"]; len(songIds) > 0 {
+			d.searchService.RefreshSongs(songIds...)
+		}
+		patterns := make(map[string]bool)
+		for t := range del.touched {
+			for _, pattern := range deleteCachePatterns[t] {
+				patterns[pattern] = true
+			}
+		}
+		for pattern := range patterns {
+			util.DeleteCacheByPattern(pattern)
+		}
+	})
+	return nil
+}
+
+// SoftDelete 将 table 中的 ids 行移入回收站, 引用行与文件原样保留以便恢复.
+// 仍有未删除的 block 引用时返回 errDeleteBlocked; 提交后从搜索索引移除并清除缓存
+func (d DeletionService) SoftDelete(uow *db.UnitOfWork, table string, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	deletionRepo := d.deletionRepo.WithTx(uow)
+	for _, rel := range deletePolicies[table] {
+		if rel.action != deleteBlock {
+			continue
+		}
+		var count int64
+		var err error
+		if softDeleteTables[rel.table] {
+			err = deletionRepo.CountLiveRefs(&count, rel.table, rel.column, ids)
+		} else {
+			err = deletionRepo.CountRefs(&count, rel.table, rel.column, ids)
+		}
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: %s.%s references %s", errDeleteBlocked, rel.table, rel.column, table)
+		}
+	}
+	if err := deletionRepo.SoftDelete(table, ids, time.Now()); err != nil {
+		return err
+	}
+	uow.AfterCommit(func() {
+		switch table {
+		case "tb_song":
+			d.searchService.RemoveSongs(ids...)
+		case "tb_artist":
+			d.searchService.RemoveArtists(ids...)
+		case "tb_playlist":
+			d.searchService.RemovePlaylists(ids...)
+		}
+		clearTrashCaches(table)
+	})
+	return nil
+}
+
+// Restore 将 table 中的 ids 行移出回收站; 行所依赖的 block 引用方仍在回收站中时返回 errRestoreBlocked,
+// 如歌手恢复前不能恢复其歌曲
+func (d DeletionService) Restore(uow *db.UnitOfWork, table string, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	deletionRepo := d.deletionRepo.WithTx(uow)
+	for parent, rels := range deletePolicies {
+		if !softDeleteTables[parent] {
+			continue
+		}
+		for _, rel := range rels {
+			if rel.table != table || rel.action != deleteBlock {
+				continue
+			}
+			var count int64
+			if err := deletionRepo.CountTrashedParents(&count, table, rel.column, parent, ids); err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("%w: %s.%s references trashed %s", errRestoreBlocked, table, rel.column, parent)
+			}
+		}
+	}
+	if err := deletionRepo.Restore(table, ids); err != nil {
+		return err
+	}
+	uow.AfterCommit(func() {
+		switch table {
+		case "tb_song":
+			d.searchService.RefreshSongs(ids...)
+		case "tb_artist":
+			d.searchService.RefreshArtists(ids...)
+		case "tb_playlist":
+			d.searchService.RefreshPlaylists(ids...)
+		}
+		clearTrashCaches(table)
+	})
+	return nil
+}
+
+func clearTrashCaches(table string) {
+	for _, pattern := range trashCachePatterns[table] {
+		util.DeleteCacheByPattern(pattern)
+	}
+}
+
+func (d DeletionService) newDeletion(deletionRepo *repo.DeletionRepo, dryRun bool) *deletion {
+	return &deletion{
+		repo:    deletionRepo,
+		dryRun:  dryRun,
+		deleted: make(map[string][]uint64),
+		updated: make(map[string][]uint64),
+		touched: make(map[string]bool),
+	}
+}
+
+// deleteFailedMessage 删除失败时返回给调用方的提示
+func deleteFailedMessage(err error) string {
+	if errors.Is(err, errDeleteBlocked) {
+		return consts.DeleteBlocked
+	}
+	return consts.Delete + consts.Failed
+}
+
+// indexed 有搜索索引、增删后需同步的表
+func indexed(table string) bool {
+	switch table {
+	case "tb_song", "tb_artist", "tb_album", "tb_playlist":
+		return true
+	}
+	return false
+}
+
+// apply 先处理引用行再删除 table 中的 ids 行
+func (del *deletion) apply(table string, ids []uint64) error {
+	for _, rel := range deletePolicies[table] {
+		effect := vo.DeleteEffectVO{Parent: table, Table: rel.table, Column: rel.column, Action: rel.action}
+		// 被级联删除或置空后需同步索引的行要先取出主键
+		var refIds []uint64
+		_, hasPolicy := deletePolicies[rel.table]
+		if (rel.action == deleteCascade && hasPolicy) || (rel.action != deleteBlock && indexed(rel.table)) {
+			if err := del.repo.GetRefIds(&refIds, rel.table, rel.column, ids); err != nil {
+				return err
+			}
+			effect.Count = int64(len(refIds))
+		} else if err := del.repo.CountRefs(&effect.Count, rel.table, rel.column, ids); err != nil {
+			return err
+		}
+		if effect.Count == 0 {
+			continue
+		}
+		del.effects = append(del.effects, effect)
+
+		switch rel.action {
+		case deleteBlock:
+			del.blocked = true
+			if !del.dryRun {
+				return fmt.Errorf("%w: %s.%s references %s", errDeleteBlocked, rel.table, rel.column, table)
+			}
+		case deleteNullify:
+			del.updated[rel.table] = append(del.updated[rel.table], refIds...)
+			del.touched[rel.table] = true
+			if del.dryRun {
+				continue
+			}
+			updates := rel.updates
+			if updates == nil {
+				updates = map[string]any{rel.column: nil}
+			}
+			if err := del.repo.UpdateRefs(rel.table, rel.column, ids, updates); err != nil {
+				return err
+			}
+		case deleteCascade:
+			if refIds != nil {
+				if err := del.apply(rel.table, refIds); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := del.remove(rel.table, rel.column, ids); err != nil {
+				return err
+			}
+		}
+	}
+	if indexed(table) {
+		del.deleted[table] = append(del.deleted[table], ids...)
+	}
+	return del.remove(table, "id", ids)
+}
+
+// remove 删除 table 中 column 引用了 ids 的行, 并记下行中保存的文件
+func (del *deletion) remove(table, column string, ids []uint64) error {
+	if err := del.repo.GetFileRefs(&del.urls, &del.keys, table, column, ids); err != nil {
+		return err
+	}
+	del.touched[table] = true
+	if del.dryRun {
+		return nil
+	}
+	return del.repo.DeleteRefs(table, column, ids)
+}
@@ -1,7 +1,6 @@
 package service
 
 import (
-	"fmt"
 	"time"
 	"vibe-music-server/internal/model/dto"
 	"vibe-music-server/internal/model/entity"
@@ -13,47 +12,56 @@ import (
 )
 
 type FavoriteService struct {
-	favoriteRepo *repo.FavoriteRepo
-	songRepo     *repo.SongRepo
-	playlistRepo *repo.PlaylistRepo
+	favoriteRepo   *repo.FavoriteRepo
+	songRepo       *repo.SongRepo
+	songArtistRepo *repo.SongArtistRepo
+	playlistRepo   *repo.PlaylistRepo
+	storageService *StorageService
 }
 
-func NewFavoriteService(favoriteRepo *repo.FavoriteRepo, songRepo *repo.SongRepo, playlistRepo *repo.PlaylistRepo) *FavoriteService {
+func NewFavoriteService(favoriteRepo *repo.FavoriteRepo, songRepo *repo.SongRepo, songArtistRepo *repo.SongArtistRepo, playlistRepo *repo.PlaylistRepo, storageService *StorageService) *FavoriteService {
 	return &FavoriteService{
-		favoriteRepo: favoriteRepo,
-		songRepo:     songRepo,
-		playlistRepo: playlistRepo,
+		favoriteRepo:   favoriteRepo,
+		songRepo:       songRepo,
+		songArtistRepo: songArtistRepo,
+		playlistRepo:   playlistRepo,
+		storageService: storageService,
 	}
 }
 
-func (f FavoriteService) GetUserFavoriteSongs(songDTO *dto.SongDTO, claims *util.Claims) result.Result[result.PageResult[vo.SongVO]] {
+func (f FavoriteService) GetUserFavoriteSongs(favoriteSongDTO *dto.FavoriteSongDTO, claims *util.Claims) result.Result[result.PageResult[vo.SongVO]] {
 	retErr := result.Error[result.PageResult[vo.SongVO]]
 	retSuc := result.SuccessWithData[result.PageResult[vo.SongVO]]
 	userId := claims.UserId
-	pageNum := songDTO.PageNum
-	pageSize := songDTO.PageSize
+	pageNum := favoriteSongDTO.PageNum
+	pageSize := favoriteSongDTO.PageSize
 	start := (pageNum - 1) * pageSize
 	var data result.PageResult[vo.SongVO]
-	templateKey := fmt.Sprintf("favorite:getUserFavoriteSongs-%v-%v-%v-%v-%v", start, pageSize, songDTO.SongName, songDTO.ArtistName, songDTO.Album)
+	templateKey := util.GenKeyByPattern("favorite:getUserFavoriteSongs", userId, start, pageSize,
+		favoriteSongDTO.SongName, favoriteSongDTO.ArtistName, favoriteSongDTO.Album, favoriteSongDTO.SortBy, favoriteSongDTO.Order)
 	if util.GetCache(templateKey, &data) {
 		return retSuc(consts.Success, data)
 	}
-	var songIds []uint64
-	if err := f.favoriteRepo.GetFavoriteSongIds(&songIds, userId); err != nil {
+	order := favoriteSongDTO.Order
+	if order == "" {
+		// 按收藏时间默认最新在前, 按歌名/歌手默认字母序
+		if favoriteSongDTO.SortBy == "" || favoriteSongDTO.SortBy == "createTime" {
+			order = "desc"
+		} else {
+			order = "asc"
+		}
+	}
+	if err := f.favoriteRepo.GetFavoriteSongs(&data, userId, favoriteSongDTO.SongName, favoriteSongDTO.ArtistName,
+		favoriteSongDTO.Album, favoriteSongDTO.SortBy, order, start, pageSize); err != nil {
 		return retErr(consts.InternalError)
 	}
-	if len(songIds) == 0 {
-		return retSuc(consts.Success, result.PageResult[vo.SongVO]{
-			Total: 0,
-			Items: []vo.SongVO{},
-		})
+	if data.Items == nil {
+		data.Items = []vo.SongVO{}
 	}
-	if err := f.songRepo.GetAllSongsByIds(&data, songIds, start, pageSize, songDTO.SongName, songDTO.ArtistName, songDTO.Album); err != nil {
+	if err := fillSongArtists(f.songArtistRepo, data.Items); err != nil {
 		return retErr(consts.InternalError)
 	}
-	for _, song := range data.Items {
-		song.LikeStatus = 1
-	}
+	f.storageService.FillSrcsets(&data)
 	util.SetCache(templateKey, data)
 	return retSuc(consts.Success, data)
 }
@@ -110,7 +118,7 @@ func (f FavoriteService) GetUserFavoritePlaylists(playlistDTO *dto.PlaylistDTO,
 	pageSize := playlistDTO.PageSize
 	start := (pageNum - 1) * pageSize
 	var data result.PageResult[vo.PlaylistVO]
-	templateKey := fmt.Sprintf("favorite:getUserFavoritePlaylists-%v-%v-%v-%v", start, pageSize, playlistDTO.Title, playlistDTO.Style)
+	templateKey := util.GenKeyByPattern("favorite:getUserFavoritePlaylists", userId, start, pageSize, playlistDTO.Title, playlistDTO.Style)
 	if util.GetCache(templateKey, &data) {
 		return retSuc(consts.Success, data)
 	}
@@ -127,6 +135,7 @@ func (f FavoriteService) GetUserFavoritePlaylists(playlistDTO *dto.PlaylistDTO,
 	if err := f.playlistRepo.GetAllPlaylistsByIds(&data, userId, playlistIds, start, pageSize, playlistDTO.Title, playlistDTO.Style); err != nil {
 		return retErr(consts.InternalError)
 	}
+	f.storageService.FillSrcsets(&data)
 	util.SetCache(templateKey, data)
 	return retSuc(consts.Success, data)
 }
@@ -174,3 +183,158 @@ func (f FavoriteService) CancelCollectPlaylist(playlistId uint64, claims *util.C
 	util.DeleteCacheByPattern("playlist:*")
 	return retSuc(consts.Success)
 }
+
+func (f FavoriteService) GetUserFavoriteArtists(artistDTO *dto.ArtistDTO, claims *util.Claims) result.Result[result.PageResult[vo.ArtistVO]] {
+	retErr := result.Error[result.PageResult[vo.ArtistVO]]
+	retSuc := result.SuccessWithData[result.PageResult[vo.ArtistVO]]
+	userId := claims.UserId
+	pageNum := artistDTO.PageNum
+	pageSize := artistDTO.PageSize
+	start := (pageNum - 1) * pageSize
+	var data result.PageResult[vo.ArtistVO]
+	templateKey := util.GenKeyByPattern("favorite:getUserFavoriteArtists", userId, start, pageSize, artistDTO.ArtistName)
+	if util.GetCache(templateKey, &data) {
+		return retSuc(consts.Success, data)
+	}
+	if err := f.favoriteRepo.GetFavoriteArtists(&data, userId, artistDTO.ArtistName, start, pageSize); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if data.Items == nil {
+		data.Items = []vo.ArtistVO{}
+	}
+	f.storageService.FillSrcsets(&data)
+	util.SetCache(templateKey, data)
+	return retSuc(consts.Success, data)
+}
+
+func (f FavoriteService) CollectArtist(artistId uint64, claims *util.Claims) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	userId := claims.UserId
+	var isFavorite uint8
+	if err := f.favoriteRepo.IsFavoriteArtist(&isFavorite, userId, artistId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if isFavorite > 0 {
+		return retErr(consts.Add + consts.Failed)
+	}
+	favorite := entity.Favorite{
+		UserID:     userId,
+		ArtistID:   &artistId,
+		Type:       entity.FavoriteTypeArtist,
+		CreateTime: time.Now(),
+	}
+	if err := f.favoriteRepo.AddFavorite(&favorite); err != nil {
+		return retErr(consts.InternalError)
+	}
+	util.DeleteCacheByPattern("favorite:*")
+	util.DeleteCacheByPattern("artist:*")
+	return retSuc(consts.Success)
+}
+
+func (f FavoriteService) CancelCollectArtist(artistId uint64, claims *util.Claims) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	userId := claims.UserId
+	var isFavorite uint8
+	if err := f.favoriteRepo.IsFavoriteArtist(&isFavorite, userId, artistId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if isFavorite == 0 {
+		return retErr(consts.Delete + consts.Failed)
+	}
+	if err := f.favoriteRepo.DeleteFavoriteArtist(userId, artistId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	util.DeleteCacheByPattern("favorite:*")
+	util.DeleteCacheByPattern("artist:*")
+	return retSuc(consts.Success)
+}
+
+func (f FavoriteService) GetUserFavoriteAlbums(albumDTO *dto.AlbumDTO, claims *util.Claims) result.Result[result.PageResult[vo.AlbumVO]] {
+	retErr := result.Error[result.PageResult[vo.AlbumVO]]
+	retSuc := result.SuccessWithData[result.PageResult[vo.AlbumVO]]
+	userId := claims.UserId
+	pageNum := albumDTO.PageNum
+	pageSize := albumDTO.PageSize
+	start := (pageNum - 1) * pageSize
+	var data result.PageResult[vo.AlbumVO]
+	templateKey := util.GenKeyByPattern("favorite:getUserFavoriteAlbums", userId, start, pageSize, albumDTO.Title)
+	if util.GetCache(templateKey, &data) {
+		return retSuc(consts.Success, data)
+	}
+	if err := f.favoriteRepo.GetFavoriteAlbums(&data, userId, albumDTO.Title, start, pageSize); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if data.Items == nil {
+		data.Items = []vo.AlbumVO{}
+	}
+	f.storageService.FillSrcsets(&data)
+	util.SetCache(templateKey, data)
+	return retSuc(consts.Success, data)
+}
+
+func (f FavoriteService) CollectAlbum(albumId uint64, claims *util.Claims) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	userId := claims.UserId
+	var isFavorite uint8
+	if err := f.favoriteRepo.IsFavoriteAlbum(&isFavorite, userId, albumId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if isFavorite > 0 {
+		return retErr(consts.Add + consts.Failed)
+	}
+	favorite := entity.Favorite{
+		UserID:     userId,
+		AlbumID:    &albumId,
+		Type:       entity.FavoriteTypeAlbum,
+		CreateTime: time.Now(),
+	}
+	if err := f.favoriteRepo.AddFavorite(&favorite); err != nil {
+		return retErr(consts.InternalError)
+	}
+	util.DeleteCacheByPattern("favorite:*")
+	return retSuc(consts.Success)
+}
+
+func (f FavoriteService) CancelCollectAlbum(albumId uint64, claims *util.Claims) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	userId := claims.UserId
+	var isFavorite uint8
+	if err := f.favoriteRepo.IsFavoriteAlbum(&isFavorite, userId, albumId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if isFavorite == 0 {
+		return retErr(consts.Delete + consts.Failed)
+	}
+	if err := f.favoriteRepo.DeleteFavoriteAlbum(userId, albumId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	util.DeleteCacheByPattern("favorite:*")
+	return retSuc(consts.Success)
+}
+
+// CheckFavorites 批量查询收藏状态, 结果与请求 ids 顺序一致
+func (f FavoriteService) CheckFavorites(favoriteCheckDTO *dto.FavoriteCheckDTO, claims *util.Claims) result.Result[[]vo.FavoriteStatusVO] {
+	retErr := result.Error[[]vo.FavoriteStatusVO]
+	retSuc := result.SuccessWithData[[]vo.FavoriteStatusVO]
+	data := make([]vo.FavoriteStatusVO, 0, len(favoriteCheckDTO.IDs))
+	if len(favoriteCheckDTO.IDs) == 0 {
+		return retSuc(consts.Success, data)
+	}
+	var favoriteIds []uint64
+	if err := f.favoriteRepo.GetFavoriteIdsIn(&favoriteIds, claims.UserId,
+		entity.FavoriteType(favoriteCheckDTO.Type), favoriteCheckDTO.IDs); err != nil {
+		return retErr(consts.InternalError)
+	}
+	for _, id := range favoriteCheckDTO.IDs {
+		status := vo.FavoriteStatusVO{ID: id}
+		if util.BinarySearch(favoriteIds, id) != -1 {
+			status.LikeStatus = 1
+		}
+		data = append(data, status)
+	}
+	return retSuc(consts.Success, data)
+}
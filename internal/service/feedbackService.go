@@ -73,7 +73,7 @@ func (f FeedbackService) DeleteFeedbacks(feedbackIds []uint64) result.Result[res
 func (f FeedbackService) AddFeedback(content string, claims *util.Claims) result.Result[result.Nil] {
 	userId := claims.UserId
 	feedback := entity.Feedback{
-		UserID:     userId,
+		UserID:     &userId,
 		Feedback:   content,
 		CreateTime: time.Now(),
 	}
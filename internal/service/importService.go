@@ -0,0 +1,709 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/audiometa"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/lrc"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/upload"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/repo"
+)
+
+const (
+	importQueueSize     = 16
+	importMaxRows       = 5000
+	importManifestLimit = 10 << 20
+	importMediaLimit    = 4 << 30
+)
+
+// ImportService 按 CSV/JSON 清单与媒体压缩包批量导入歌曲; 任务在后台逐个执行, 每行单独成功或失败
+type ImportService struct {
+	importJobRepo  *repo.ImportJobRepo
+	songRepo       *repo.SongRepo
+	artistRepo     *repo.ArtistRepo
+	albumRepo      *repo.AlbumRepo
+	styleRepo      *repo.StyleRepo
+	storageService *StorageService
+	searchService  *SearchService
+	songService    *SongService
+	tasks          chan importTask
+	startTime      time.Time
+}
+
+// importTask 排队中的任务: 清单已解析, 压缩包保存在临时目录中
+type importTask struct {
+	job   entity.ImportJob
+	rows  []importRow
+	dir   string // 临时目录, 任务结束后删除
+	media string // 压缩包路径, 未上传时为空
+}
+
+type importRow struct {
+	dto.SongImportRowDTO
+	line int
+	errs []string // 解析清单时发现的错误
+}
+
+func NewImportService(importJobRepo *repo.ImportJobRepo, songRepo *repo.SongRepo, artistRepo *repo.ArtistRepo, albumRepo *repo.AlbumRepo, styleRepo *repo.StyleRepo, storageService *StorageService, searchService *SearchService, songService *SongService) *ImportService {
+	return &ImportService{
+		importJobRepo:  importJobRepo,
+		songRepo:       songRepo,
+		artistRepo:     artistRepo,
+		albumRepo:      albumRepo,
+		styleRepo:      styleRepo,
+		storageService: storageService,
+		searchService:  searchService,
+		songService:    songService,
+		tasks:          make(chan importTask, importQueueSize),
+		startTime:      time.Now(),
+	}
+}
+
+// ImportSongs 解析清单并保存压缩包, 创建任务后立即返回; dryRun 时只校验不写入
+func (i ImportService) ImportSongs(manifest, media *multipart.FileHeader, dryRun bool) result.Result[vo.ImportJobVO] {
+	retErr := result.Error[vo.ImportJobVO]
+	if manifest.Size > importManifestLimit || (media != nil && media.Size > importMediaLimit) {
+		return retErr(consts.FileTooLarge)
+	}
+	rows, err := readImportManifest(manifest)
+	if err != nil {
+		return retErr(consts.ManifestFormatError + ": " + err.Error())
+	}
+	if len(rows) == 0 {
+		return retErr(consts.ManifestFormatError + ": " + consts.FileEmpty)
+	}
+	if len(rows) > importMaxRows {
+		return retErr(fmt.Sprintf("%s(最多 %d 行)", consts.ManifestTooManyRows, importMaxRows))
+	}
+
+	dir, err := os.MkdirTemp("", "vibe-import-")
+	if err != nil {
+		log.Printf("ImportService.ImportSongs err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	task := importTask{rows: rows, dir: dir}
+	if media != nil {
+		task.media = filepath.Join(dir, "media.zip")
+		if msg := saveImportMedia(media, task.media); msg != "" {
+			_ = os.RemoveAll(dir)
+			return retErr(msg)
+		}
+	}
+	task.job = entity.ImportJob{
+		ID:         uuid.NewString(),
+		Status:     entity.ImportPending,
+		DryRun:     dryRun,
+		Manifest:   truncateRunes(manifest.Filename, 255),
+		Total:      len(rows),
+		CreateTime: time.Now(),
+	}
+	if err := i.importJobRepo.AddImportJob(&task.job); err != nil {
+		_ = os.RemoveAll(dir)
+		return retErr(consts.InternalError)
+	}
+	select {
+	case i.tasks <- task:
+	default:
+		_ = os.RemoveAll(dir)
+		i.updateJob(task.job.ID, map[string]any{"status": entity.ImportFailed, "message": consts.ImportQueueFull})
+		return retErr(consts.ImportQueueFull)
+	}
+	return result.SuccessWithData(consts.Success, toImportJobVO(task.job))
+}
+
+// GetImportJob 查询任务进度, 完成后附带逐行结果
+func (i ImportService) GetImportJob(id string) result.Result[vo.ImportJobVO] {
+	retErr := result.Error[vo.ImportJobVO]
+	var job entity.ImportJob
+	if err := i.importJobRepo.GetImportJob(&job, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
+	}
+	data := toImportJobVO(job)
+	if job.Report != "" {
+		if err := json.Unmarshal([]byte(job.Report), &data.Rows); err != nil {
+			log.Printf("ImportService.GetImportJob err: %v\n", err)
+		}
+	}
+	return result.SuccessWithData(consts.Success, data)
+}
+
+// Run 逐个执行排队的任务; 启动时将上次运行中断的任务标记为失败
+func (i ImportService) Run() {
+	if err := i.importJobRepo.FailUnfinishedJobs(i.startTime, consts.ImportInterrupted); err != nil {
+		log.Printf("ImportService.Run err: %v\n", err)
+	}
+	for task := range i.tasks {
+		i.process(task)
+	}
+}
+
+func (i ImportService) process(task importTask) {
+	defer os.RemoveAll(task.dir)
+	job := task.job
+	i.updateJob(job.ID, map[string]any{"status": entity.ImportRunning})
+
+	files := make(map[string]*zip.File)
+	if task.media != "" {
+		zr, err := zip.OpenReader(task.media)
+		if err != nil {
+			i.updateJob(job.ID, map[string]any{"status": entity.ImportFailed, "message": consts.MediaFormatError})
+			return
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			files[cleanMediaPath(f.Name)] = f
+		}
+	}
+	var styleList []vo.StyleVO
+	if err := i.styleRepo.GetStyleList(&styleList); err != nil {
+		log.Printf("ImportService.process err: %v\n", err)
+		i.updateJob(job.ID, map[string]any{"status": entity.ImportFailed, "message": consts.InternalError})
+		return
+	}
+	styles := make(map[string]uint64, len(styleList))
+	for _, style := range styleList {
+		styles[strings.ToLower(style.Name)] = style.StyleID
+	}
+
+	imp := rowImporter{ImportService: i, task: task, files: files, styles: styles, seen: make(map[string]bool)}
+	report := make([]vo.ImportRowVO, 0, len(task.rows))
+	for _, row := range task.rows {
+		rowVO := imp.importRow(row)
+		report = append(report, rowVO)
+		if rowVO.Success {
+			job.Succeeded++
+		} else {
+			job.Failed++
+		}
+		job.Processed++
+		i.updateJob(job.ID, map[string]any{"processed": job.Processed, "succeeded": job.Succeeded, "failed": job.Failed})
+	}
+	if len(imp.songIds) > 0 {
+		i.searchService.RefreshSongs(imp.songIds...)
+		util.DeleteCacheByPattern("song:*")
+		util.DeleteCacheByPattern("album:*")
+		util.DeleteCacheByPattern("style:*")
+	}
+	if len(imp.artistIds) > 0 {
+		i.searchService.RefreshArtists(imp.artistIds...)
+		util.DeleteCacheByPattern("artist:*")
+	}
+
+	reportJson, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("ImportService.process err: %v\n", err)
+	}
+	i.updateJob(job.ID, map[string]any{"status": entity.ImportDone, "report": string(reportJson), "finish_time": time.Now()})
+	log.Printf("import %s: %d succeeded, %d failed (dry run: %v)\n", job.ID, job.Succeeded, job.Failed, job.DryRun)
+}
+
+// updateJob 更新任务状态与进度, 失败只记录日志, 不影响导入本身
+func (i ImportService) updateJob(id string, fields map[string]any) {
+	if status, ok := fields["status"]; ok && status == entity.ImportFailed {
+		fields["finish_time"] = time.Now()
+	}
+	if err := i.importJobRepo.UpdateImportJob(id, fields); err != nil {
+		log.Printf("ImportService.updateJob err: %v\n", err)
+	}
+}
+
+// rowImporter 一个任务内逐行导入的状态
+type rowImporter struct {
+	ImportService
+	task      importTask
+	files     map[string]*zip.File // 压缩包中的文件, 以清理后的路径为键
+	styles    map[string]uint64    // 小写风格名 -> 风格 id
+	seen      map[string]bool      // 已出现的 歌手名+歌曲名
+	songIds   []uint64
+	artistIds []uint64 // 新建的歌手
+}
+
+// importRow 校验一行, 全部通过且不是预演时在一个事务中写入歌手、歌曲与文件
+func (r *rowImporter) importRow(row importRow) vo.ImportRowVO {
+	rowVO := vo.ImportRowVO{Row: row.line, SongName: row.SongName, Artist: row.Artist}
+	errs := slices.Clone(row.errs)
+	fail := func(field, msg string) {
+		errs = append(errs, field+": "+msg)
+	}
+
+	// 1. 字段
+	checkText := func(field, value string, limit int, required bool) {
+		if value == "" && required {
+			fail(field, consts.NotNull)
+		} else if utf8.RuneCountInString(value) > limit {
+			fail(field, consts.WordLimitError)
+		}
+	}
+	checkText("songName", row.SongName, 200, true)
+	checkText("artist", row.Artist, 100, true)
+	checkText("album", row.Album, 200, false)
+	var collaborators []string
+	for _, name := range splitArtistNames(row.Artists) {
+		if name != row.Artist && !slices.Contains(collaborators, name) {
+			checkText("artists", name, 100, false)
+			collaborators = append(collaborators, name)
+		}
+	}
+	var releaseTime time.Time
+	if row.ReleaseTime != "" {
+		t, err := time.ParseInLocation("2006-01-02", row.ReleaseTime, time.Local)
+		if err != nil {
+			fail("releaseTime", consts.FormatError)
+		}
+		releaseTime = t
+	}
+	styleNames := util.ParseStyle(row.Style)
+	styleIds := make([]uint64, 0, len(styleNames))
+	for _, name := range styleNames {
+		if id, ok := r.styles[strings.ToLower(name)]; ok {
+			styleIds = append(styleIds, id)
+		} else {
+			fail("style", consts.Style+consts.NotExist+": "+name)
+		}
+	}
+	checkText("style", strings.Join(styleNames, ","), 100, false)
+	if row.SongName != "" && row.Artist != "" {
+		key := row.Artist + "\x00" + row.SongName
+		if r.seen[key] {
+			fail("songName", consts.DuplicateRow)
+		}
+		r.seen[key] = true
+	}
+
+	// 2. 歌手, 不存在的将新建
+	if row.Artist != "" {
+		var artist entity.Artist
+		if msg := r.findArtist(&artist, row.Artist); msg != "" {
+			fail("artist", msg)
+		} else if artist.ID == 0 {
+			rowVO.NewArtists = append(rowVO.NewArtists, row.Artist)
+		} else if row.SongName != "" {
+			exists, err := r.songRepo.ExistSongByName(artist.ID, row.SongName)
+			if err != nil {
+				fail("songName", consts.InternalError)
+			} else if exists {
+				fail("songName", consts.Song+consts.AlreadyExists)
+			}
+		}
+	}
+	for _, name := range collaborators {
+		var artist entity.Artist
+		if msg := r.findArtist(&artist, name); msg != "" {
+			fail("artists", msg)
+		} else if artist.ID == 0 {
+			rowVO.NewArtists = append(rowVO.NewArtists, name)
+		}
+	}
+
+	// 3. 媒体: 解出到临时文件并按上传策略校验
+	var audio, cover *os.File
+	defer func() {
+		for _, f := range []*os.File{audio, cover} {
+			if f != nil {
+				_ = f.Close()
+				_ = os.Remove(f.Name())
+			}
+		}
+	}()
+	var msg string
+	if row.Audio != "" {
+		if audio, msg = r.extractMedia(row.Audio, "songs"); msg != "" {
+			fail("audio", msg)
+		}
+	}
+	if row.Cover != "" {
+		if cover, msg = r.extractMedia(row.Cover, "songCovers"); msg != "" {
+			fail("cover", msg)
+		}
+	}
+	var lyric string
+	if row.Lyric != "" {
+		if lyric, msg = r.readLyric(row.Lyric); msg != "" {
+			fail("lyric", msg)
+		}
+	}
+
+	if len(errs) > 0 {
+		rowVO.Errors = errs
+		return rowVO
+	}
+	if r.task.job.DryRun {
+		rowVO.Success = true
+		return rowVO
+	}
+
+	// 4. 写入
+	song := entity.Song{
+		Name:        row.SongName,
+		Album:       row.Album,
+		DiscNumber:  max(row.DiscNumber, 1),
+		TrackNumber: row.TrackNumber,
+		Style:       strings.Join(styleNames, ","),
+		Lyric:       lyric,
+		ReleaseTime: releaseTime,
+	}
+	var newArtistIds []uint64
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		newArtistIds = newArtistIds[:0]
+		artistRepo := r.artistRepo.WithTx(uow)
+		resolve := func(name string) (uint64, error) {
+			var artist entity.Artist
+			err := artistRepo.SelectByName(&artist, name)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				artist = entity.Artist{Name: name}
+				err = artistRepo.CreateArtist(&artist)
+				newArtistIds = append(newArtistIds, artist.ID)
+			}
+			return artist.ID, err
+		}
+		primaryId, err := resolve(row.Artist)
+		if err != nil {
+			return err
+		}
+		credits := []entity.SongArtist{{ArtistID: primaryId, Role: entity.CreditRolePrimary}}
+		for _, name := range collaborators {
+			id, err := resolve(name)
+			if err != nil {
+				return err
+			}
+			credits = append(credits, entity.SongArtist{ArtistID: id, Role: entity.CreditRoleFeatured, Sort: uint(len(credits))})
+		}
+		song.ArtistID = uint(primaryId)
+		// 专辑只关联该歌手名下已有的同名专辑
+		if row.Album != "" {
+			var album entity.Album
+			if err := r.albumRepo.GetAlbumByTitle(&album, primaryId, row.Album); err == nil {
+				song.AlbumID = &album.ID
+				song.Album = album.Title
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+		if cover != nil {
+			stat, err := cover.Stat()
+			if err != nil {
+				return err
+			}
+			if song.CoverURL, err = r.storageService.UploadReader(cover, stat.Size(), "songCovers", path.Base(row.Cover)); err != nil {
+				return err
+			}
+			r.storageService.DeleteFileOnRollback(uow, song.CoverURL)
+		}
+		if audio != nil {
+			if err := r.uploadAudio(uow, &song, audio, path.Base(row.Audio)); err != nil {
+				return err
+			}
+		}
+		return r.songService.createSong(uow, &song, credits, styleIds)
+	})
+	if err != nil {
+		log.Printf("ImportService.importRow %s row %d err: %v\n", r.task.job.ID, row.line, err)
+		var rejectErr *upload.RejectError
+		if errors.As(err, &rejectErr) {
+			rowVO.Errors = []string{consts.FileUpload + consts.Failed + ": " + rejectErr.Msg}
+		} else {
+			rowVO.Errors = []string{consts.Add + consts.Failed}
+		}
+		return rowVO
+	}
+	rowVO.Success = true
+	rowVO.SongID = uint64(song.ID)
+	r.songIds = append(r.songIds, rowVO.SongID)
+	r.artistIds = append(r.artistIds, newArtistIds...)
+	return rowVO
+}
+
+// uploadAudio 上传音频并写入格式信息; 歌曲还没有封面时使用音频内嵌的封面
+func (r *rowImporter) uploadAudio(uow *db.UnitOfWork, song *entity.Song, audio *os.File, filename string) error {
+	stat, err := audio.Stat()
+	if err != nil {
+		return err
+	}
+	if song.AudioURL, err = r.storageService.UploadReader(audio, stat.Size(), "songs", filename); err != nil {
+		return err
+	}
+	r.storageService.DeleteFileOnRollback(uow, song.AudioURL)
+	meta, err := audiometa.Read(audio, stat.Size())
+	if err != nil {
+		// 无法识别的格式不影响导入
+		return nil
+	}
+	if meta.Duration > 0 {
+		song.Duration = formatDuration(meta.Duration.Milliseconds())
+	}
+	song.Codec = meta.Codec
+	song.Bitrate = meta.Bitrate
+	song.SampleRate = meta.SampleRate
+	if meta.Cover != nil && song.CoverURL == "" {
+		if song.CoverURL, err = r.storageService.UploadBytes(meta.Cover.Data, "songCovers", "cover"); err != nil {
+			log.Printf("ImportService.uploadAudio err: %v\n", err)
+			song.CoverURL = ""
+		}
+		r.storageService.DeleteFileOnRollback(uow, song.CoverURL)
+	}
+	return nil
+}
+
+// findArtist 按名称查找歌手, 不存在时 artist 保持为空, 出错时返回错误信息
+func (r *rowImporter) findArtist(artist *entity.Artist, name string) string {
+	if err := r.artistRepo.SelectByName(artist, name); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return consts.InternalError
+	}
+	return ""
+}
+
+// extractMedia 将压缩包中的文件解出到临时文件并按 folder 的上传策略校验, 返回读取位置在开头的文件或错误信息
+func (r *rowImporter) extractMedia(name, folder string) (*os.File, string) {
+	f, ok := r.files[cleanMediaPath(name)]
+	if !ok {
+		return nil, consts.MediaNotFound + ": " + name
+	}
+	policy, _ := upload.PolicyFor(folder)
+	tmp, err := os.CreateTemp(r.task.dir, "media-*")
+	if err != nil {
+		log.Printf("ImportService.extractMedia err: %v\n", err)
+		return nil, consts.InternalError
+	}
+	discard := func(msg string) (*os.File, string) {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, msg
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return discard(consts.MediaFormatError)
+	}
+	// 多读一个字节, 超出上限的文件由策略校验拒绝
+	size, err := io.Copy(tmp, io.LimitReader(rc, policy.MaxSize+1))
+	_ = rc.Close()
+	if err != nil {
+		return discard(consts.MediaFormatError)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return discard(consts.InternalError)
+	}
+	if _, err := policy.Check(tmp, size); err != nil {
+		var rejectErr *upload.RejectError
+		if errors.As(err, &rejectErr) {
+			return discard(rejectErr.Msg)
+		}
+		return discard(consts.InternalError)
+	}
+	return tmp, ""
+}
+
+// readLyric 读取并校验压缩包中的 LRC 歌词, 返回清理后的歌词或错误信息
+func (r *rowImporter) readLyric(name string) (string, string) {
+	f, ok := r.files[cleanMediaPath(name)]
+	if !ok {
+		return "", consts.MediaNotFound + ": " + name
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", consts.MediaFormatError
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, lrc.MaxSize+1))
+	if err != nil {
+		return "", consts.MediaFormatError
+	}
+	if len(data) > lrc.MaxSize {
+		return "", consts.Lyric + consts.WordLimitError
+	}
+	lyric := lrc.Clean(string(data))
+	if lyric == "" {
+		return "", consts.Lyric + consts.NotNull
+	}
+	if _, err := lrc.Parse(lyric); err != nil {
+		return "", consts.Lyric + consts.FormatError + ": " + err.Error()
+	}
+	return lyric, ""
+}
+
+// readImportManifest 按扩展名解析 .csv 或 .json 清单
+func readImportManifest(manifest *multipart.FileHeader) ([]importRow, error) {
+	src, err := manifest.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, importManifestLimit))
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(path.Ext(manifest.Filename)) {
+	case ".csv":
+		return parseCSVManifest(data)
+	case ".json":
+		return parseJSONManifest(data)
+	}
+	return nil, errors.New("仅支持 .csv 或 .json")
+}
+
+// parseCSVManifest 第一行为表头, 列名同 JSON 字段名, 列的顺序不限; 数字列格式错误记为该行的错误
+func parseCSVManifest(data []byte) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"songName", "artist"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("缺少 %s 列", name)
+		}
+	}
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line, SongImportRowDTO: dto.SongImportRowDTO{
+			SongName:    get("songName"),
+			Artist:      get("artist"),
+			Artists:     get("artists"),
+			Album:       get("album"),
+			Style:       get("style"),
+			ReleaseTime: get("releaseTime"),
+			Audio:       get("audio"),
+			Cover:       get("cover"),
+			Lyric:       get("lyric"),
+		}}
+		parseNumber := func(name string, field *uint) {
+			if value := get(name); value != "" {
+				n, err := strconv.ParseUint(value, 10, 16)
+				if err != nil {
+					row.errs = append(row.errs, name+": "+consts.FormatError)
+				}
+				*field = uint(n)
+			}
+		}
+		parseNumber("discNumber", &row.DiscNumber)
+		parseNumber("trackNumber", &row.TrackNumber)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseJSONManifest 清单为对象数组, 行号为数组下标加一
+func parseJSONManifest(data []byte) ([]importRow, error) {
+	var dtos []dto.SongImportRowDTO
+	if err := json.Unmarshal(data, &dtos); err != nil {
+		return nil, err
+	}
+	rows := make([]importRow, 0, len(dtos))
+	for i, d := range dtos {
+		for _, field := range []*string{&d.SongName, &d.Artist, &d.Artists, &d.Album, &d.Style, &d.ReleaseTime, &d.Audio, &d.Cover, &d.Lyric} {
+			*field = strings.TrimSpace(*field)
+		}
+		rows = append(rows, importRow{SongImportRowDTO: d, line: i + 1})
+	}
+	return rows, nil
+}
+
+// saveImportMedia 将上传的压缩包保存到 dst 并确认能够打开, 返回错误信息
+func saveImportMedia(media *multipart.FileHeader, dst string) string {
+	src, err := media.Open()
+	if err != nil {
+		return consts.FileUpload + consts.Failed
+	}
+	defer src.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		log.Printf("ImportService.saveImportMedia err: %v\n", err)
+		return consts.InternalError
+	}
+	_, err = io.Copy(out, src)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Printf("ImportService.saveImportMedia err: %v\n", err)
+		return consts.InternalError
+	}
+	zr, err := zip.OpenReader(dst)
+	if err != nil {
+		return consts.MediaFormatError
+	}
+	_ = zr.Close()
+	return ""
+}
+
+// splitArtistNames 合作歌手以 ; 或全角分号分隔, 歌手名中可能含有逗号与斜杠
+func splitArtistNames(names string) []string {
+	fields := strings.FieldsFunc(names, func(r rune) bool { return r == ';' || r == '；' })
+	out := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// cleanMediaPath 统一清单与压缩包中的路径写法: 斜杠方向、开头的 ./ 与 /
+func cleanMediaPath(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func toImportJobVO(job entity.ImportJob) vo.ImportJobVO {
+	return vo.ImportJobVO{
+		ID:         job.ID,
+		Status:     job.Status,
+		DryRun:     job.DryRun,
+		Manifest:   job.Manifest,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Succeeded:  job.Succeeded,
+		Failed:     job.Failed,
+		Message:    job.Message,
+		CreateTime: job.CreateTime,
+		FinishTime: job.FinishTime,
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/repo"
+)
+
+// MigrationService 按文件名顺序执行 scripts/migrations 中尚未执行的脚本, 供 cmd/vibectl 使用.
+// 已执行的脚本记录在 tb_schema_migration 中; 启用记录之前已手动执行过脚本的库, 先用 MarkApplied 补记
+type MigrationService struct {
+	migrationRepo *repo.MigrationRepo
+}
+
+func NewMigrationService(migrationRepo *repo.MigrationRepo) *MigrationService {
+	return &MigrationService{migrationRepo: migrationRepo}
+}
+
+// GetMigrations 列出目录中的脚本及执行时间
+func (m MigrationService) GetMigrations(dir string) ([]vo.MigrationVO, error) {
+	files, err := migrationFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]vo.MigrationVO, 0, len(files))
+	for _, file := range files {
+		migration := vo.MigrationVO{Version: file}
+		if t, ok := applied[file]; ok {
+			migration.AppliedAt = &t
+		}
+		data = append(data, migration)
+	}
+	return data, nil
+}
+
+// Migrate 依次执行尚未执行的脚本, 返回本次执行的脚本. 脚本中途失败时前面的语句已生效, 需手动处理后再执行
+func (m MigrationService) Migrate(dir string) ([]string, error) {
+	migrations, err := m.GetMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+	var done []string
+	for _, migration := range migrations {
+		if migration.AppliedAt != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, migration.Version))
+		if err != nil {
+			return done, err
+		}
+		for i, statement := range splitSQLStatements(string(data)) {
+			if err := m.migrationRepo.ExecStatement(statement); err != nil {
+				return done, fmt.Errorf("%s statement %d: %w", migration.Version, i+1, err)
+			}
+		}
+		if err := m.migrationRepo.AddMigration(&entity.SchemaMigration{Version: migration.Version, AppliedAt: time.Now()}); err != nil {
+			return done, err
+		}
+		done = append(done, migration.Version)
+	}
+	return done, nil
+}
+
+// MarkApplied 将 version 及之前的脚本记为已执行而不执行, 返回新记录的脚本
+func (m MigrationService) MarkApplied(dir, version string) ([]string, error) {
+	migrations, err := m.GetMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(version, ".sql") {
+		// 允许只给出编号, 如 016
+		for _, migration := range migrations {
+			if strings.HasPrefix(migration.Version, version+"_") {
+				version = migration.Version
+				break
+			}
+		}
+	}
+	found := false
+	for _, migration := range migrations {
+		if migration.Version == version {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("migration %s not found in %s", version, dir)
+	}
+	var marked []string
+	for _, migration := range migrations {
+		if migration.Version > version {
+			break
+		}
+		if migration.AppliedAt != nil {
+			continue
+		}
+		if err := m.migrationRepo.AddMigration(&entity.SchemaMigration{Version: migration.Version, AppliedAt: time.Now()}); err != nil {
+			return marked, err
+		}
+		marked = append(marked, migration.Version)
+	}
+	return marked, nil
+}
+
+func (m MigrationService) applied() (map[string]time.Time, error) {
+	if err := m.migrationRepo.EnsureMigrationTable(); err != nil {
+		return nil, err
+	}
+	var migrations []entity.SchemaMigration
+	if err := m.migrationRepo.GetMigrations(&migrations); err != nil {
+		return nil, err
+	}
+	applied := make(map[string]time.Time, len(migrations))
+	for _, migration := range migrations {
+		applied[migration.Version] = migration.AppliedAt
+	}
+	return applied, nil
+}
+
+// migrationFiles 目录中的 .sql 脚本, 按文件名排序
+func migrationFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".sql") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// splitSQLStatements 按分号拆分脚本中的语句, 忽略引号内的分号与注释
+func splitSQLStatements(script string) []string {
+	var statements []string
+	var sb strings.Builder
+	var quote byte // 当前所在引号, 0 表示不在引号内
+	flush := func() {
+		if statement := strings.TrimSpace(sb.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		sb.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		if quote != 0 {
+			sb.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(script) {
+				i++
+				sb.WriteByte(script[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			sb.WriteByte(c)
+		case c == '-' && strings.HasPrefix(script[i:], "-- "), c == '#':
+			// 行注释
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			sb.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+			sb.WriteByte(' ')
+		case c == ';':
+			flush()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
@@ -2,9 +2,11 @@ package service
 
 import (
 	"fmt"
+	"log"
 	"vibe-music-server/internal/model/dto"
 	"vibe-music-server/internal/model/entity"
 	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
 	"vibe-music-server/internal/pkg/result"
 	"vibe-music-server/internal/pkg/result/consts"
 	"vibe-music-server/internal/pkg/util"
@@ -12,18 +14,24 @@ import (
 )
 
 type PlaylistService struct {
-	playlistRepo *repo.PlaylistRepo
-	favoriteRepo *repo.FavoriteRepo
-	styleRepo    *repo.StyleRepo
-	minioService *MinioService
+	playlistRepo    *repo.PlaylistRepo
+	songArtistRepo  *repo.SongArtistRepo
+	favoriteRepo    *repo.FavoriteRepo
+	styleRepo       *repo.StyleRepo
+	storageService  *StorageService
+	searchService   *SearchService
+	deletionService *DeletionService
 }
 
-func NewPlaylistService(playlistRepo *repo.PlaylistRepo, favoriteRepo *repo.FavoriteRepo, styleRepo *repo.StyleRepo, minioService *MinioService) *PlaylistService {
+func NewPlaylistService(playlistRepo *repo.PlaylistRepo, songArtistRepo *repo.SongArtistRepo, favoriteRepo *repo.FavoriteRepo, styleRepo *repo.StyleRepo, storageService *StorageService, searchService *SearchService, deletionService *DeletionService) *PlaylistService {
 	return &PlaylistService{
-		playlistRepo: playlistRepo,
-		favoriteRepo: favoriteRepo,
-		styleRepo:    styleRepo,
-		minioService: minioService,
+		playlistRepo:    playlistRepo,
+		songArtistRepo:  songArtistRepo,
+		favoriteRepo:    favoriteRepo,
+		styleRepo:       styleRepo,
+		storageService:  storageService,
+		searchService:   searchService,
+		deletionService: deletionService,
 	}
 }
 
@@ -46,6 +54,7 @@ func (p PlaylistService) GetAllPlaylists(playlistDTO *dto.PlaylistDTO) result.Re
 	if data.Total == 0 {
 		return retErr(consts.DataNotFound)
 	}
+	p.storageService.FillSrcsets(&data)
 	util.SetCache(templateKey, data)
 	return retSuc(consts.Success, data)
 }
@@ -83,6 +92,7 @@ func (p PlaylistService) GetRecommendedPlaylists(claims *util.Claims) result.Res
 		if err := p.playlistRepo.GetRandomPlaylists(&data, 10); err != nil {
 			return retErr(consts.InternalError)
 		}
+		p.storageService.FillSrcsets(&data)
 		return retSuc(consts.Success, data)
 	}
 	userId := claims.UserId
@@ -95,6 +105,7 @@ func (p PlaylistService) GetRecommendedPlaylists(claims *util.Claims) result.Res
 		if err := p.playlistRepo.GetRandomPlaylists(&data, 10); err != nil {
 			return retErr(consts.InternalError)
 		}
+		p.storageService.FillSrcsets(&data)
 		return retSuc(consts.Success, data)
 	}
 	var favoriteStyles []string
@@ -130,6 +141,7 @@ func (p PlaylistService) GetRecommendedPlaylists(claims *util.Claims) result.Res
 			}
 		}
 	}
+	p.storageService.FillSrcsets(&data)
 	return retSuc(consts.Success, data)
 }
 
@@ -156,6 +168,9 @@ func (p PlaylistService) GetPlaylistDetail(playlistId uint64, claims *util.Claim
 	if data.PlaylistID == 0 {
 		return retErr(consts.DataNotFound)
 	}
+	if err := fillSongArtists(p.songArtistRepo, data.Songs); err != nil {
+		return retErr(consts.InternalError)
+	}
 	if claims != nil {
 		userId := claims.UserId
 		var isFavorite uint8
@@ -164,6 +179,7 @@ func (p PlaylistService) GetPlaylistDetail(playlistId uint64, claims *util.Claim
 		}
 		data.LikeStatus = isFavorite
 	}
+	p.storageService.FillSrcsets(&data)
 	util.SetCache(templateKey, data)
 	return retSuc(consts.Success, data)
 }
@@ -191,6 +207,7 @@ func (p PlaylistService) AddPlaylist(playlistAddDTO *dto.PlaylistAddDTO) result.
 	if err := p.playlistRepo.CreatePlaylist(&playlist); err != nil {
 		return retErr(consts.Add + consts.Failed)
 	}
+	p.searchService.RefreshPlaylists(uint64(playlist.ID))
 	util.DeleteCacheByPattern("playlist:*")
 	return retSuc(consts.Add + consts.Success)
 }
@@ -205,6 +222,7 @@ func (p PlaylistService) UpdatePlaylist(playlistUpdateDTO *dto.PlaylistUpdateDTO
 	if err := p.playlistRepo.UpdatePlaylist(&playlist, playlistUpdateDTO); err != nil {
 		return retErr(consts.Update + consts.Failed)
 	}
+	p.searchService.RefreshPlaylists(playlistUpdateDTO.PlaylistID)
 	util.DeleteCacheByPattern("playlist:*")
 	return retSuc(consts.Update + consts.Success)
 }
@@ -217,48 +235,33 @@ func (p PlaylistService) UpdatePlaylistCover(playlistId uint64, coverUrl string)
 		return retErr(consts.Playlist + consts.NotFound)
 	}
 	// 删除旧封面
-	if err := p.minioService.DeleteFile(playlist.CoverURL); err != nil {
+	if err := p.storageService.DeleteFile(playlist.CoverURL); err != nil {
 		return retErr(consts.Update + consts.Failed)
 	}
 	if err := p.playlistRepo.UpdatePlaylistCover(&playlist, coverUrl); err != nil {
 		return retErr(consts.Update + consts.Failed)
 	}
+	p.searchService.RefreshPlaylists(playlistId)
 	util.DeleteCacheByPattern("playlist:*")
 	return retSuc(consts.Update + consts.Success)
 }
 
 func (p PlaylistService) DeletePlaylist(playlistId uint64) result.Result[result.Nil] {
-	retErr := result.Error[result.Nil]
-	retSuc := result.Success[result.Nil]
 	var playlist entity.Playlist
 	if err := p.playlistRepo.GetPlaylistById(&playlist, playlistId); err != nil {
-		return retErr(consts.Playlist + consts.NotFound)
-	}
-	if err := p.minioService.DeleteFile(playlist.CoverURL); err != nil {
-		return retErr(consts.Delete + consts.Failed)
+		return result.Error[result.Nil](consts.Playlist + consts.NotFound)
 	}
-	if err := p.playlistRepo.DeletePlaylist(&playlist); err != nil {
-		return retErr(consts.Delete + consts.Failed)
-	}
-	util.DeleteCacheByPattern("playlist:*")
-	return retSuc(consts.Delete + consts.Success)
+	return p.DeletePlaylists([]uint64{playlistId})
 }
 
+// DeletePlaylists 将歌单移入回收站, 收录、收藏、评论与封面在回收站中清除时才随之删除
 func (p PlaylistService) DeletePlaylists(playlistIds []uint64) result.Result[result.Nil] {
-	retErr := result.Error[result.Nil]
-	retSuc := result.Success[result.Nil]
-	var coverUrls []string
-	if err := p.playlistRepo.GetPlaylistCoverUrlsByIds(&coverUrls, playlistIds); err != nil {
-		return retErr(consts.Playlist + consts.NotFound)
-	}
-	for _, coverUrl := range coverUrls {
-		if err := p.minioService.DeleteFile(coverUrl); err != nil {
-			return retErr(consts.Delete + consts.Failed)
-		}
-	}
-	if err := p.playlistRepo.DeletePlaylists(playlistIds); err != nil {
-		return retErr(consts.Delete + consts.Failed)
-	}
-	util.DeleteCacheByPattern("playlist:*")
-	return retSuc(consts.Delete + consts.Success)
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		return p.deletionService.SoftDelete(uow, "tb_playlist", playlistIds)
+	})
+	if err != nil {
+		log.Printf("PlaylistService.DeletePlaylists err: %v\n", err)
+		return result.Error[result.Nil](deleteFailedMessage(err))
+	}
+	return result.Success[result.Nil](consts.Delete + consts.Success)
 }
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+	"vibe-music-server/internal/config"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/upload"
+	"vibe-music-server/internal/repo"
+)
+
+const defaultGracePeriod = 24 * time.Hour
+
+var errReconcileRunning = errors.New("reconcile already running")
+
+// ReconcileService 对账数据库中的文件引用与存储中的对象: 找出无人引用的孤儿对象, 以及指向不存在对象的悬空引用.
+// 先上传后写库、替换时不删旧文件、批量删除中途失败等都会留下这两类数据
+type ReconcileService struct {
+	storageRefRepo    *repo.StorageRefRepo
+	storageObjectRepo *repo.StorageObjectRepo
+	storageService    *StorageService
+	running           *atomic.Bool
+}
+
+func NewReconcileService(storageRefRepo *repo.StorageRefRepo, storageObjectRepo *repo.StorageObjectRepo, storageService *StorageService) *ReconcileService {
+	return &ReconcileService{
+		storageRefRepo:    storageRefRepo,
+		storageObjectRepo: storageObjectRepo,
+		storageService:    storageService,
+		running:           &atomic.Bool{},
+	}
+}
+
+// Reconcile 执行一次对账; deleteOrphans 为 true 时删除上传超过宽限期的孤儿对象并修正偏小的引用计数, 否则只报告
+func (r ReconcileService) Reconcile(deleteOrphans bool) result.Result[vo.StorageReportVO] {
+	report, err := r.reconcile(deleteOrphans)
+	if err != nil {
+		if errors.Is(err, errReconcileRunning) {
+			return result.Error[vo.StorageReportVO](consts.ReconcileRunning)
+		}
+		log.Printf("ReconcileService.Reconcile err: %v\n", err)
+		return result.Error[vo.StorageReportVO](consts.InternalError)
+	}
+	return result.SuccessWithData(consts.Success, report)
+}
+
+// Run 按配置 storage.gc.interval 定期对账, 间隔为 0 时不执行
+func (r ReconcileService) Run() {
+	gc := config.Get().Storage.GC
+	if gc.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(gc.Interval) * time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		report, err := r.reconcile(gc.DeleteOrphans)
+		if err != nil {
+			log.Printf("ReconcileService.Run err: %v\n", err)
+			continue
+		}
+		log.Printf("storage reconcile: %d objects, %d references, %d orphans (%d bytes), %d deleted, %d dangling, %d ref counts fixed\n",
+			report.Objects, report.References, len(report.Orphans), report.OrphanBytes, report.Deleted, len(report.Dangling), report.RefCountFixed)
+		for _, ref := range report.Dangling {
+			log.Printf("storage reconcile: dangling %s.%s id=%d %s\n", ref.Table, ref.Column, ref.ID, ref.Key)
+		}
+	}
+}
+
+func (r ReconcileService) reconcile(deleteOrphans bool) (vo.StorageReportVO, error) {
+	if !r.running.CompareAndSwap(false, true) {
+		return vo.StorageReportVO{}, errReconcileRunning
+	}
+	defer r.running.Store(false)
+
+	report := vo.StorageReportVO{StartTime: time.Now(), Orphans: []vo.StorageOrphanVO{}, Dangling: []vo.StorageRefVO{}}
+	// 1. 先列对象再查引用: 两步之间新上传并写库的文件只会被漏判为孤儿, 由宽限期兜底
+	objects, err := r.storageService.ListObjects("")
+	if err != nil {
+		return report, err
+	}
+	var refs, renditionRefs []vo.StorageRefVO
+	if err = r.storageRefRepo.GetURLRefs(&refs); err != nil {
+		return report, err
+	}
+	if err = r.storageRefRepo.GetRenditionRefs(&renditionRefs); err != nil {
+		return report, err
+	}
+
+	// 2. 地址换算为对象名, 图片的缩略图随原图一起算作被引用
+	referenced := make(map[string]int, len(refs)+len(renditionRefs))
+	thumbnails := make(map[string]bool) // 被引用图片的缩略图前缀, 按存储中实际存在的对象匹配
+	n := 0
+	for _, ref := range refs {
+		key, err := r.storageService.ObjectName(ref.URL)
+		if err != nil {
+			report.External++
+			continue
+		}
+		ref.Key = key
+		refs[n] = ref
+		n++
+		referenced[key]++
+		if prefix, ok := upload.ThumbnailPrefix(key); ok {
+			thumbnails[prefix] = true
+		}
+	}
+	refs = append(refs[:n], renditionRefs...)
+	for _, ref := range renditionRefs {
+		referenced[ref.Key]++
+	}
+	report.Objects = len(objects)
+	report.References = len(refs)
+
+	// 3. 孤儿对象; 分片上传的临时对象由 UploadService 清理
+	exists := make(map[string]bool, len(objects))
+	grace := time.Duration(config.Get().Storage.GC.GracePeriod) * time.Hour
+	if grace <= 0 {
+		grace = defaultGracePeriod
+	}
+	before := report.StartTime.Add(-grace)
+	// 一条引用都没有多半是连错了数据库, 此时不删除任何对象
+	deleteOrphans = deleteOrphans && len(refs) > 0
+	for _, obj := range objects {
+		exists[obj.Key] = true
+		if referenced[obj.Key] > 0 || isReferencedThumbnail(obj.Key, thumbnails) || strings.HasPrefix(obj.Key, uploadTempPrefix) {
+			continue
+		}
+		orphan := vo.StorageOrphanVO{Key: obj.Key, Size: obj.Size, LastModified: obj.LastModified}
+		report.OrphanBytes += obj.Size
+		if deleteOrphans && obj.LastModified.Before(before) {
+			if err = r.storageService.RemoveObject(obj.Key); err == nil {
+				err = r.storageObjectRepo.DeleteStorageObject(obj.Key)
+			}
+			if err != nil {
+				log.Printf("ReconcileService.reconcile err: %v\n", err)
+			} else {
+				orphan.Deleted = true
+				report.Deleted++
+				report.DeletedBytes += obj.Size
+			}
+		}
+		report.Orphans = append(report.Orphans, orphan)
+	}
+
+	// 4. 悬空引用
+	for _, ref := range refs {
+		if !exists[ref.Key] {
+			report.Dangling = append(report.Dangling, ref)
+		}
+	}
+
+	// 5. 引用计数少于实际引用数时, 释放一次就会误删仍被引用的对象; 多出的计数会在引用全部消失后按孤儿清理
+	if deleteOrphans {
+		var storageObjects []entity.StorageObject
+		if err = r.storageObjectRepo.GetStorageObjects(&storageObjects); err != nil {
+			return report, err
+		}
+		for _, obj := range storageObjects {
+			if n := referenced[obj.ObjectKey]; n > obj.RefCount {
+				if err = r.storageObjectRepo.RaiseRefCount(obj.ObjectKey, n); err != nil {
+					log.Printf("ReconcileService.reconcile err: %v\n", err)
+					continue
+				}
+				report.RefCountFixed++
+			}
+		}
+	}
+	report.Duration = time.Since(report.StartTime).Round(time.Millisecond).String()
+	return report, nil
+}
+
+// isReferencedThumbnail 对象是被引用图片的缩略图
+func isReferencedThumbnail(key string, thumbnails map[string]bool) bool {
+	if _, _, ok := upload.ParseThumbnailKey(key); !ok {
+		return false
+	}
+	return thumbnails[path.Dir(key)+"/-"]
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+	"vibe-music-server/internal/config"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/audiometa"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/repo"
+)
+
+const anonymousRole = "anonymous"
+
+// 配置中未指定时各角色可播放的最高音质
+var defaultEntitlements = map[string]string{
+	anonymousRole:    entity.QualityStandard,
+	consts.UserRole:  entity.QualityHigh,
+	consts.AdminRole: entity.QualityLossless,
+}
+
+var losslessCodecs = map[string]bool{"flac": true, "alac": true, "wav": true}
+
+// RenditionService 管理歌曲的多音质版本, 并按请求音质与用户权益选择播放地址
+type RenditionService struct {
+	songRepo       *repo.SongRepo
+	renditionRepo  *repo.SongRenditionRepo
+	storageService *StorageService
+	statService    *StatService
+}
+
+func NewRenditionService(songRepo *repo.SongRepo, renditionRepo *repo.SongRenditionRepo, storageService *StorageService, statService *StatService) *RenditionService {
+	return &RenditionService{
+		songRepo:       songRepo,
+		renditionRepo:  renditionRepo,
+		storageService: storageService,
+		statService:    statService,
+	}
+}
+
+func (r RenditionService) GetSongRenditions(songId uint64) result.Result[[]vo.SongRenditionVO] {
+	retErr := result.Error[[]vo.SongRenditionVO]
+	var renditions []entity.SongRendition
+	if err := r.renditionRepo.GetRenditionsBySongId(&renditions, songId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	data := make([]vo.SongRenditionVO, 0, len(renditions))
+	for _, rendition := range renditions {
+		data = append(data, r.toSongRenditionVO(rendition))
+	}
+	return result.SuccessWithData(consts.Success, data)
+}
+
+// SaveSongRendition 登记已上传的音质版本, 同一音质已有版本时替换并删除旧文件
+func (r RenditionService) SaveSongRendition(songId uint64, renditionDTO *dto.SongRenditionDTO, audioUrl string, audio io.ReaderAt, size int64) result.Result[vo.SongRenditionVO] {
+	retErr := result.Error[vo.SongRenditionVO]
+	objectKey, err := r.storageService.ObjectName(audioUrl)
+	if err != nil {
+		return retErr(consts.InternalError)
+	}
+	// 未通过校验时删除刚上传的文件
+	discard := func(msg string) result.Result[vo.SongRenditionVO] {
+		if err := r.storageService.ReleaseObject(objectKey); err != nil {
+			log.Printf("RenditionService.SaveSongRendition err: %v\n", err)
+		}
+		return retErr(msg)
+	}
+	var song entity.Song
+	if err = r.songRepo.GetSongById(&song, songId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return discard(consts.DataNotFound)
+		}
+		return discard(consts.InternalError)
+	}
+
+	rendition := entity.SongRendition{
+		SongID:     songId,
+		Quality:    renditionDTO.Quality,
+		Codec:      strings.TrimPrefix(path.Ext(objectKey), "."),
+		Size:       size,
+		ObjectKey:  objectKey,
+		CreateTime: time.Now(),
+	}
+	if meta, err := audiometa.Read(audio, size); err == nil {
+		rendition.Codec = meta.Codec
+		rendition.Bitrate = meta.Bitrate
+	}
+	if rendition.Quality == entity.QualityLossless && !losslessCodecs[rendition.Codec] {
+		return discard(consts.NotLossless)
+	}
+
+	var old entity.SongRendition
+	if err = r.renditionRepo.GetRendition(&old, songId, rendition.Quality); err != nil {
+		return discard(consts.InternalError)
+	}
+	rendition.ID = old.ID
+	if err = r.renditionRepo.SaveRendition(&rendition); err != nil {
+		return discard(consts.Update + consts.Failed)
+	}
+	// 相同内容重复上传得到同一个对象, 也要释放旧记录占用的那次引用
+	if old.ID != 0 {
+		if err = r.storageService.ReleaseObject(old.ObjectKey); err != nil {
+			log.Printf("RenditionService.SaveSongRendition err: %v\n", err)
+		}
+	}
+	return result.SuccessWithData(consts.Update+consts.Success, r.toSongRenditionVO(rendition))
+}
+
+func (r RenditionService) DeleteSongRendition(renditionId uint64) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	var rendition entity.SongRendition
+	if err := r.renditionRepo.GetRenditionById(&rendition, renditionId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if rendition.ID == 0 {
+		return retErr(consts.DataNotFound)
+	}
+	if err := r.storageService.ReleaseObject(rendition.ObjectKey); err != nil {
+		return retErr(consts.Delete + consts.Failed)
+	}
+	if err := r.renditionRepo.DeleteRenditionById(renditionId); err != nil {
+		return retErr(consts.Delete + consts.Failed)
+	}
+	return result.Success[result.Nil](consts.Delete + consts.Success)
+}
+
+// GetSongStream 在不超过请求音质与用户权益的版本中取最高的一个, 返回限时有效的播放地址.
+// 原始上传的音频(tb_song.audio_url)也作为候选, 音质按其编码与码率判断
+func (r RenditionService) GetSongStream(songId uint64, streamDTO *dto.SongStreamDTO, claims *util.Claims) result.Result[vo.SongStreamVO] {
+	retErr := result.Error[vo.SongStreamVO]
+	var song entity.Song
+	if err := r.songRepo.GetSongById(&song, songId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
+	}
+	var candidates []entity.SongRendition
+	if err := r.renditionRepo.GetRenditionsBySongId(&candidates, songId); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if song.AudioURL != "" {
+		if objectKey, err := r.storageService.ObjectName(song.AudioURL); err == nil {
+			candidates = append(candidates, entity.SongRendition{
+				Quality:   originalQuality(song),
+				Codec:     song.Codec,
+				Bitrate:   song.Bitrate,
+				ObjectKey: objectKey,
+			})
+		}
+	}
+	if len(candidates) == 0 {
+		return retErr(consts.DataNotFound)
+	}
+
+	limit := entity.QualityRank(entitledQuality(claims))
+	if streamDTO.Quality != "" {
+		limit = min(limit, entity.QualityRank(streamDTO.Quality))
+	}
+	var best *entity.SongRendition
+	for i, c := range candidates {
+		rank := entity.QualityRank(c.Quality)
+		if rank <= limit && (best == nil || rank > entity.QualityRank(best.Quality)) {
+			best = &candidates[i]
+		}
+	}
+	if best == nil {
+		return retErr(consts.QualityDenied)
+	}
+
+	expiry := time.Duration(max(config.Get().Stream.URLExpiration, 1)) * time.Minute
+	streamUrl, err := r.storageService.PresignedURL(best.ObjectKey, expiry)
+	if err != nil {
+		log.Printf("RenditionService.GetSongStream err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	// 每次取得播放地址记为一次播放
+	r.statService.RecordPlay(songId, claims)
+	return result.SuccessWithData(consts.Success, vo.SongStreamVO{
+		SongID:    songId,
+		Quality:   best.Quality,
+		Codec:     best.Codec,
+		Bitrate:   best.Bitrate,
+		URL:       streamUrl,
+		ExpiresAt: time.Now().Add(expiry),
+	})
+}
+
+func (r RenditionService) toSongRenditionVO(rendition entity.SongRendition) vo.SongRenditionVO {
+	return vo.SongRenditionVO{
+		RenditionID: rendition.ID,
+		SongID:      rendition.SongID,
+		Quality:     rendition.Quality,
+		Codec:       rendition.Codec,
+		Bitrate:     rendition.Bitrate,
+		Size:        rendition.Size,
+		ObjectKey:   rendition.ObjectKey,
+		AudioURL:    r.storageService.ObjectURL(rendition.ObjectKey),
+	}
+}
+
+// entitledQuality 用户可播放的最高音质, 由角色决定
+func entitledQuality(claims *util.Claims) string {
+	role := anonymousRole
+	if claims != nil {
+		role = claims.Role
+	}
+	// viper 的 map 键统一为小写
+	if quality, ok := config.Get().Stream.Entitlements[strings.ToLower(role)]; ok && entity.QualityRank(quality) > 0 {
+		return quality
+	}
+	return defaultEntitlements[role]
+}
+
+func originalQuality(song entity.Song) string {
+	switch {
+	case losslessCodecs[song.Codec]:
+		return entity.QualityLossless
+	case song.Bitrate > 192:
+		return entity.QualityHigh
+	default:
+		return entity.QualityStandard
+	}
+}
@@ -0,0 +1,502 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/audiometa"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/lrc"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/repo"
+)
+
+// 扫描单个文件的结果
+const (
+	scanCreated   = "created"
+	scanUpdated   = "updated"
+	scanMoved     = "moved"
+	scanUnchanged = "unchanged"
+	scanSkipped   = "skipped"
+	scanFailed    = "failed"
+)
+
+var (
+	scanExtensions = map[string]bool{".mp3": true, ".flac": true, ".ogg": true, ".oga": true, ".opus": true, ".m4a": true, ".wav": true}
+	// 没有内嵌封面时依次查找同目录下的封面图
+	scanCoverNames = []string{"cover.jpg", "cover.jpeg", "cover.png", "folder.jpg", "folder.jpeg", "folder.png"}
+
+	errScanNoArtist   = errors.New("no artist tag")
+	errScanDuplicate  = errors.New("artist already has a song with this name")
+	errScanSongAbsent = errors.New("song is in the trash")
+)
+
+// ScanService 扫描本地目录中的音频文件, 按标签新建或更新歌手、专辑与歌曲, 供 cmd/scan 使用.
+// 文件按绝对路径记录在 tb_scan_file 中: 大小与修改时间不变时跳过, 否则比较内容摘要, 内容变化才重新上传并更新歌曲;
+// 新路径的内容与一个已不存在的已记录文件相同时视为移动, 只更新路径
+type ScanService struct {
+	scanFileRepo   *repo.ScanFileRepo
+	songRepo       *repo.SongRepo
+	artistRepo     *repo.ArtistRepo
+	albumRepo      *repo.AlbumRepo
+	styleRepo      *repo.StyleRepo
+	storageService *StorageService
+	songService    *SongService
+}
+
+func NewScanService(scanFileRepo *repo.ScanFileRepo, songRepo *repo.SongRepo, artistRepo *repo.ArtistRepo, albumRepo *repo.AlbumRepo, styleRepo *repo.StyleRepo, storageService *StorageService, songService *SongService) *ScanService {
+	return &ScanService{
+		scanFileRepo:   scanFileRepo,
+		songRepo:       songRepo,
+		artistRepo:     artistRepo,
+		albumRepo:      albumRepo,
+		styleRepo:      styleRepo,
+		storageService: storageService,
+		songService:    songService,
+	}
+}
+
+// scanTags 由标签与文件路径得到的歌曲信息
+type scanTags struct {
+	title       string
+	artists     []string // 第一个为主歌手
+	album       string
+	discNumber  uint
+	trackNumber uint
+	releaseTime time.Time
+	styleNames  []string
+	styleIds    []uint64
+	lyric       string
+	meta        *audiometa.Metadata
+}
+
+// scanner 一次扫描的状态
+type scanner struct {
+	ScanService
+	dryRun  bool
+	styles  map[string]uint64 // 小写风格名 -> 风格 id
+	report  vo.ScanReportVO
+	changed bool
+}
+
+// Scan 扫描 root 下的音频文件; dryRun 为 true 时只报告将要执行的操作. 逐个文件处理, 单个文件失败不影响其他文件
+func (s ScanService) Scan(root string, dryRun bool) (vo.ScanReportVO, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return vo.ScanReportVO{}, err
+	}
+	if info, err := os.Stat(root); err != nil {
+		return vo.ScanReportVO{}, err
+	} else if !info.IsDir() {
+		return vo.ScanReportVO{}, fmt.Errorf("%s is not a directory", root)
+	}
+	var styleList []vo.StyleVO
+	if err := s.styleRepo.GetStyleList(&styleList); err != nil {
+		return vo.ScanReportVO{}, err
+	}
+	sc := scanner{ScanService: s, dryRun: dryRun, styles: make(map[string]uint64, len(styleList))}
+	for _, style := range styleList {
+		sc.styles[strings.ToLower(style.Name)] = style.StyleID
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			log.Printf("scan: %s: %v\n", path, err)
+			return nil
+		}
+		// 跳过隐藏目录及 NAS 生成的缩略图目录(如 @eaDir)
+		if d.IsDir() && path != root && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "@")) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !d.Type().IsRegular() || !scanExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		sc.scanFile(path, d)
+		return nil
+	})
+	if err != nil {
+		return sc.report, err
+	}
+	if sc.changed {
+		util.DeleteCacheByPattern("song:*")
+		util.DeleteCacheByPattern("album:*")
+		util.DeleteCacheByPattern("artist:*")
+		util.DeleteCacheByPattern("style:*")
+	}
+	return sc.report, nil
+}
+
+func (sc *scanner) scanFile(path string, d fs.DirEntry) {
+	sc.report.Files++
+	status, err := sc.process(path, d)
+	switch status {
+	case scanCreated:
+		sc.report.Created++
+	case scanUpdated:
+		sc.report.Updated++
+	case scanMoved:
+		sc.report.Moved++
+	case scanUnchanged:
+		sc.report.Unchanged++
+	case scanSkipped:
+		sc.report.Skipped++
+	default:
+		sc.report.Failed++
+	}
+	if err != nil {
+		log.Printf("scan: %s %s: %v\n", status, path, err)
+	} else if status != scanUnchanged {
+		log.Printf("scan: %s %s\n", status, path)
+	}
+}
+
+func (sc *scanner) process(path string, d fs.DirEntry) (string, error) {
+	info, err := d.Info()
+	if err != nil {
+		return scanFailed, err
+	}
+
+	// 1. 大小与修改时间不变时不再读取文件
+	var record entity.ScanFile
+	err = sc.scanFileRepo.GetScanFileByPath(&record, path)
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return scanFailed, err
+	}
+	modTime := info.ModTime().UnixNano()
+	if found && record.Size == info.Size() && record.ModTime == modTime {
+		return scanUnchanged, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return scanFailed, err
+	}
+	defer f.Close()
+	sum, err := hashContent(f)
+	if err != nil {
+		return scanFailed, err
+	}
+
+	// 2. 内容不变只更新修改时间; 新路径的内容属于已不存在的文件时视为移动
+	if found && record.Sha256 == sum {
+		record.Size, record.ModTime = info.Size(), modTime
+		return scanUnchanged, sc.saveRecord(&record)
+	}
+	if !found {
+		var same []entity.ScanFile
+		if err := sc.scanFileRepo.GetScanFilesBySha256(&same, sum); err != nil {
+			return scanFailed, err
+		}
+		for _, old := range same {
+			if _, err := os.Stat(old.Path); errors.Is(err, os.ErrNotExist) {
+				old.Path, old.Size, old.ModTime = path, info.Size(), modTime
+				return scanMoved, sc.saveRecord(&old)
+			}
+		}
+		record = entity.ScanFile{Path: path}
+	}
+	record.Size, record.ModTime, record.Sha256 = info.Size(), modTime, sum
+
+	// 3. 读取标签, 新建或更新歌曲
+	tags, err := sc.readTags(f, info.Size(), path)
+	if err != nil {
+		return scanSkipped, err
+	}
+	if record.SongID != nil {
+		return sc.updateSong(&record, f, tags)
+	}
+	return sc.createSong(&record, f, tags)
+}
+
+func (sc *scanner) saveRecord(record *entity.ScanFile) error {
+	if sc.dryRun {
+		return nil
+	}
+	return sc.scanFileRepo.SaveScanFile(record)
+}
+
+// readTags 标签中没有标题时使用文件名; 风格只取已登记的, 歌词优先使用同名 .lrc 文件
+func (sc *scanner) readTags(f *os.File, size int64, path string) (scanTags, error) {
+	meta, err := audiometa.Read(f, size)
+	if err != nil {
+		// 无法识别的格式(如 WAV)没有标签, 仍可按文件名导入, 但缺少歌手时跳过
+		meta = &audiometa.Metadata{}
+	}
+	tags := scanTags{
+		title:       truncateRunes(meta.Title, 200),
+		album:       truncateRunes(meta.Album, 200),
+		discNumber:  uint(max(meta.DiscNumber, 1)),
+		trackNumber: uint(max(meta.TrackNumber, 0)),
+		meta:        meta,
+	}
+	if tags.title == "" {
+		tags.title = truncateRunes(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), 200)
+	}
+	for _, name := range splitArtistNames(meta.Artist) {
+		name = truncateRunes(name, 100)
+		if !slices.ContainsFunc(tags.artists, func(a string) bool { return strings.EqualFold(a, name) }) {
+			tags.artists = append(tags.artists, name)
+		}
+	}
+	if len(tags.artists) == 0 {
+		return tags, errScanNoArtist
+	}
+	if releaseTime, ok := parseReleaseDate(meta.Date); ok {
+		tags.releaseTime = releaseTime
+	}
+	for _, name := range util.ParseStyle(meta.Genre) {
+		if id, ok := sc.styles[strings.ToLower(name)]; ok {
+			tags.styleNames = append(tags.styleNames, name)
+			tags.styleIds = append(tags.styleIds, id)
+		}
+	}
+
+	lyric := meta.Lyrics
+	if data, err := os.ReadFile(strings.TrimSuffix(path, filepath.Ext(path)) + ".lrc"); err == nil && len(data) <= lrc.MaxSize {
+		lyric = string(data)
+	}
+	if lyric = lrc.Clean(lyric); lyric != "" {
+		if _, err := lrc.Parse(lyric); err == nil {
+			tags.lyric = lyric
+		}
+	}
+	return tags, nil
+}
+
+// createSong 新建歌曲, 不存在的歌手与主歌手名下不存在的专辑一并新建
+func (sc *scanner) createSong(record *entity.ScanFile, audio *os.File, tags scanTags) (string, error) {
+	var primary entity.Artist
+	if err := sc.artistRepo.SelectByName(&primary, tags.artists[0]); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return scanFailed, err
+	}
+	if primary.ID != 0 {
+		exists, err := sc.songRepo.ExistSongByName(primary.ID, tags.title)
+		if err != nil {
+			return scanFailed, err
+		}
+		if exists {
+			return scanSkipped, errScanDuplicate
+		}
+	}
+	if sc.dryRun {
+		return scanCreated, nil
+	}
+
+	song := entity.Song{
+		Name:        tags.title,
+		DiscNumber:  tags.discNumber,
+		TrackNumber: tags.trackNumber,
+		Style:       strings.Join(tags.styleNames, ","),
+		Lyric:       tags.lyric,
+		ReleaseTime: tags.releaseTime,
+	}
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		artistRepo := sc.artistRepo.WithTx(uow)
+		credits := make([]entity.SongArtist, 0, len(tags.artists))
+		for i, name := range tags.artists {
+			var artist entity.Artist
+			err := artistRepo.SelectByName(&artist, name)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				artist = entity.Artist{Name: name}
+				err = artistRepo.CreateArtist(&artist)
+			}
+			if err != nil {
+				return err
+			}
+			credit := entity.SongArtist{ArtistID: artist.ID, Role: entity.CreditRoleFeatured, Sort: uint(i)}
+			if i == 0 {
+				credit.Role = entity.CreditRolePrimary
+			}
+			credits = append(credits, credit)
+		}
+		song.ArtistID = uint(credits[0].ArtistID)
+		if err := sc.uploadMedia(uow, &song, audio, tags); err != nil {
+			return err
+		}
+		if err := sc.linkAlbum(uow, &song, tags); err != nil {
+			return err
+		}
+		if err := sc.songService.createSong(uow, &song, credits, tags.styleIds); err != nil {
+			return err
+		}
+		songId := uint64(song.ID)
+		record.SongID = &songId
+		record.ScanTime = time.Now()
+		return sc.scanFileRepo.WithTx(uow).SaveScanFile(record)
+	})
+	if err != nil {
+		return scanFailed, err
+	}
+	sc.changed = true
+	return scanCreated, nil
+}
+
+// updateSong 文件内容变化后替换音频并按新标签更新歌曲; 署名与风格保留管理端的编辑, 封面与歌词只在歌曲没有时补上
+func (sc *scanner) updateSong(record *entity.ScanFile, audio *os.File, tags scanTags) (string, error) {
+	var song entity.Song
+	if err := sc.songRepo.GetSongById(&song, *record.SongID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return scanSkipped, errScanSongAbsent
+		}
+		return scanFailed, err
+	}
+	if sc.dryRun {
+		return scanUpdated, nil
+	}
+
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		oldAudio := song.AudioURL
+		song.Name = tags.title
+		song.DiscNumber, song.TrackNumber = tags.discNumber, tags.trackNumber
+		if !tags.releaseTime.IsZero() {
+			song.ReleaseTime = tags.releaseTime
+		}
+		if song.Lyric == "" {
+			song.Lyric = tags.lyric
+		}
+		if err := sc.uploadMedia(uow, &song, audio, tags); err != nil {
+			return err
+		}
+		if err := sc.linkAlbum(uow, &song, tags); err != nil {
+			return err
+		}
+		fields := map[string]any{
+			"name":         song.Name,
+			"album_id":     song.AlbumID,
+			"album":        song.Album,
+			"disc_number":  song.DiscNumber,
+			"track_number": song.TrackNumber,
+			"release_time": song.ReleaseTime,
+			"lyric":        song.Lyric,
+			"duration":     song.Duration,
+			"codec":        song.Codec,
+			"bitrate":      song.Bitrate,
+			"sample_rate":  song.SampleRate,
+			"cover_url":    song.CoverURL,
+			"audio_url":    song.AudioURL,
+		}
+		if err := sc.songRepo.WithTx(uow).UpdateSongFields(uint64(song.ID), fields); err != nil {
+			return err
+		}
+		sc.storageService.DeleteFileAfterCommit(uow, oldAudio)
+		record.ScanTime = time.Now()
+		return sc.scanFileRepo.WithTx(uow).SaveScanFile(record)
+	})
+	if err != nil {
+		return scanFailed, err
+	}
+	sc.changed = true
+	return scanUpdated, nil
+}
+
+// uploadMedia 上传音频并写入格式信息; 歌曲没有封面时使用内嵌封面或同目录下的封面图
+func (sc *scanner) uploadMedia(uow *db.UnitOfWork, song *entity.Song, audio *os.File, tags scanTags) error {
+	stat, err := audio.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := audio.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if song.AudioURL, err = sc.storageService.UploadReader(audio, stat.Size(), "songs", filepath.Base(audio.Name())); err != nil {
+		return err
+	}
+	sc.storageService.DeleteFileOnRollback(uow, song.AudioURL)
+	meta := tags.meta
+	if meta.Duration > 0 {
+		song.Duration = formatDuration(meta.Duration.Milliseconds())
+	}
+	song.Codec = meta.Codec
+	song.Bitrate = meta.Bitrate
+	song.SampleRate = meta.SampleRate
+
+	if song.CoverURL != "" {
+		return nil
+	}
+	if meta.Cover != nil {
+		song.CoverURL, err = sc.storageService.UploadBytes(meta.Cover.Data, "songCovers", "cover")
+	} else if cover := findScanCover(filepath.Dir(audio.Name())); cover != "" {
+		song.CoverURL, err = sc.uploadFile(cover, "songCovers")
+	}
+	if err != nil {
+		// 封面不合上传策略时不影响导入
+		log.Printf("scan: cover of %s: %v\n", audio.Name(), err)
+		song.CoverURL = ""
+	}
+	sc.storageService.DeleteFileOnRollback(uow, song.CoverURL)
+	return nil
+}
+
+// linkAlbum 关联主歌手名下的同名专辑, 不存在时新建; 新专辑使用歌曲的封面与发行日期
+func (sc *scanner) linkAlbum(uow *db.UnitOfWork, song *entity.Song, tags scanTags) error {
+	if tags.album == "" {
+		song.AlbumID, song.Album = nil, ""
+		return nil
+	}
+	albumRepo := sc.albumRepo.WithTx(uow)
+	var album entity.Album
+	err := albumRepo.GetAlbumByTitle(&album, uint64(song.ArtistID), tags.album)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		album = entity.Album{ArtistID: uint64(song.ArtistID), Title: tags.album, ReleaseDate: tags.releaseTime, Type: entity.AlbumTypeLP}
+		if song.CoverURL != "" {
+			// 专辑单独占用一次封面引用, 相同内容不会重复存储
+			obj, size, err := sc.storageService.OpenFile(song.CoverURL)
+			if err != nil {
+				return err
+			}
+			album.CoverURL, err = sc.storageService.UploadReader(obj, size, "albumCovers", "cover")
+			obj.Close()
+			if err != nil {
+				return err
+			}
+			sc.storageService.DeleteFileOnRollback(uow, album.CoverURL)
+		}
+		err = albumRepo.CreateAlbum(&album)
+	}
+	if err != nil {
+		return err
+	}
+	song.AlbumID = &album.ID
+	song.Album = album.Title
+	return nil
+}
+
+func (sc *scanner) uploadFile(file, folder string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	return sc.storageService.UploadReader(f, stat.Size(), folder, filepath.Base(file))
+}
+
+// findScanCover 目录下的封面图, 文件名不区分大小写, 没有时返回空
+func findScanCover(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, name := range scanCoverNames {
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && strings.EqualFold(entry.Name(), name) {
+				return filepath.Join(dir, entry.Name())
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"log"
+	"math"
+	"time"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/search"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/repo"
+)
+
+const (
+	defaultSearchPageSize = 10
+	groupSearchPageSize   = 5 // 不指定类型时每组返回的条数
+	defaultSuggestLimit   = 8
+	defaultTrendingLimit  = 10
+	defaultTrendingDays   = 7
+	searchQueryRetention  = 30 // 搜索词计数保留天数
+	queryLogFlushInterval = time.Minute
+)
+
+type SearchService struct {
+	searchRepo     *repo.SearchRepo
+	storageService *StorageService
+	index          *search.Index
+	suggester      *search.Suggester
+	queryLog       *search.QueryLog
+}
+
+func NewSearchService(searchRepo *repo.SearchRepo, storageService *StorageService) *SearchService {
+	return &SearchService{
+		searchRepo:     searchRepo,
+		storageService: storageService,
+		index:          search.NewIndex(),
+		suggester:      search.NewSuggester(),
+		queryLog:       search.NewQueryLog(),
+	}
+}
+
+// Rebuild 从数据库全量重建索引
+func (s SearchService) Rebuild() error {
+	var docs []search.Doc
+	var rows []vo.SearchDocVO
+	if err := s.searchRepo.GetArtistDocs(&rows, nil); err != nil {
+		return err
+	}
+	docs = appendDocs(docs, search.TypeArtist, rows)
+	rows = nil
+	if err := s.searchRepo.GetSongDocs(&rows, nil); err != nil {
+		return err
+	}
+	docs = appendDocs(docs, search.TypeSong, rows)
+	rows = nil
+	if err := s.searchRepo.GetAlbumDocs(&rows, nil); err != nil {
+		return err
+	}
+	docs = appendDocs(docs, search.TypeAlbum, rows)
+	rows = nil
+	if err := s.searchRepo.GetPlaylistDocs(&rows, nil); err != nil {
+		return err
+	}
+	docs = appendDocs(docs, search.TypePlaylist, rows)
+	s.index.Replace(docs)
+	s.suggester.Replace(toSuggestions(docs))
+	return nil
+}
+
+// Run 启动时构建索引, 之后按固定间隔全量重建, 兜底未经服务层的数据变更; 同时定期将搜索词计数落库
+func (s SearchService) Run(interval time.Duration) {
+	if err := s.Rebuild(); err != nil {
+		log.Printf("SearchService.Rebuild err: %v\n", err)
+	}
+	rebuild := time.NewTicker(interval)
+	defer rebuild.Stop()
+	flush := time.NewTicker(queryLogFlushInterval)
+	defer flush.Stop()
+	for {
+		select {
+		case <-rebuild.C:
+			if err := s.Rebuild(); err != nil {
+				log.Printf("SearchService.Rebuild err: %v\n", err)
+			}
+			if err := s.searchRepo.DeleteSearchQueriesBefore(today().AddDate(0, 0, -searchQueryRetention)); err != nil {
+				log.Printf("SearchService.DeleteSearchQueriesBefore err: %v\n", err)
+			}
+		case <-flush.C:
+			s.flushQueryLog()
+		}
+	}
+}
+
+func (s SearchService) flushQueryLog() {
+	counts := s.queryLog.Drain()
+	if len(counts) == 0 {
+		return
+	}
+	if err := s.searchRepo.IncrSearchQueries(counts, today()); err != nil {
+		log.Printf("SearchService.flushQueryLog err: %v\n", err)
+	}
+}
+
+func (s SearchService) Search(searchDTO *dto.SearchDTO) result.Result[vo.SearchResultVO] {
+	retSuc := result.SuccessWithData[vo.SearchResultVO]
+	var types []search.DocType
+	pageSize := searchDTO.PageSize
+	if searchDTO.Type != "" {
+		types = []search.DocType{search.DocType(searchDTO.Type)}
+		if pageSize == 0 {
+			pageSize = defaultSearchPageSize
+		}
+	} else if pageSize == 0 {
+		pageSize = groupSearchPageSize
+	}
+	pageNum := max(searchDTO.PageNum, 1)
+	res := s.index.Search(searchDTO.Q, types, (pageNum-1)*pageSize, pageSize)
+	// 只统计有结果的首页查询, 翻页不重复计数
+	if res.Top != nil && pageNum == 1 {
+		s.queryLog.Add(searchDTO.Q)
+	}
+
+	var data vo.SearchResultVO
+	if res.Top != nil {
+		top := toHitVO(*res.Top)
+		data.Top = &top
+	}
+	for t, page := range res.Groups {
+		group := &vo.SearchGroupVO{Total: int64(page.Total), Items: make([]vo.SearchHitVO, 0, len(page.Hits))}
+		for _, hit := range page.Hits {
+			group.Items = append(group.Items, toHitVO(hit))
+		}
+		switch t {
+		case search.TypeSong:
+			data.Songs = group
+		case search.TypeArtist:
+			data.Artists = group
+		case search.TypeAlbum:
+			data.Albums = group
+		case search.TypePlaylist:
+			data.Playlists = group
+		}
+	}
+	s.storageService.FillSrcsets(&data)
+	return retSuc(consts.Success, data)
+}
+
+func (s SearchService) Suggest(suggestDTO *dto.SearchSuggestDTO) result.Result[[]vo.SearchSuggestVO] {
+	retSuc := result.SuccessWithData[[]vo.SearchSuggestVO]
+	limit := suggestDTO.Limit
+	if limit == 0 {
+		limit = defaultSuggestLimit
+	}
+	suggestions := s.suggester.Suggest(suggestDTO.Q, limit)
+	data := make([]vo.SearchSuggestVO, 0, len(suggestions))
+	for _, sg := range suggestions {
+		data = append(data, vo.SearchSuggestVO{
+			Type:     string(sg.Type),
+			ID:       sg.ID,
+			Text:     sg.Text,
+			Subtitle: sg.Subtitle,
+		})
+	}
+	return retSuc(consts.Success, data)
+}
+
+func (s SearchService) GetTrending(trendingDTO *dto.SearchTrendingDTO) result.Result[[]vo.TrendingQueryVO] {
+	retErr := result.Error[[]vo.TrendingQueryVO]
+	retSuc := result.SuccessWithData[[]vo.TrendingQueryVO]
+	days := trendingDTO.Days
+	if days == 0 {
+		days = defaultTrendingDays
+	}
+	limit := trendingDTO.Limit
+	if limit == 0 {
+		limit = defaultTrendingLimit
+	}
+	data := []vo.TrendingQueryVO{}
+	templateKey := util.GenKeyByPattern("search:trending", days, limit)
+	if util.GetCache(templateKey, &data) {
+		return retSuc(consts.Success, data)
+	}
+	if err := s.searchRepo.GetTrendingQueries(&data, today().AddDate(0, 0, 1-days), limit); err != nil {
+		log.Printf("SearchService.GetTrending err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	util.SetCache(templateKey, data)
+	return retSuc(consts.Success, data)
+}
+
+// 以下方法供其他服务在数据变更后同步索引, 失败只记录日志, 下次重建时修正
+
+func (s SearchService) RefreshSongs(ids ...uint64) {
+	var rows []vo.SearchDocVO
+	if err := s.searchRepo.GetSongDocs(&rows, ids); err != nil {
+		log.Printf("SearchService.RefreshSongs err: %v\n", err)
+		return
+	}
+	s.index.Upsert(appendDocs(nil, search.TypeSong, rows)...)
+}
+
+func (s SearchService) RemoveSongs(ids ...uint64) {
+	s.index.Remove(search.TypeSong, ids...)
+}
+
+// RefreshArtists 歌手名变更会影响其歌曲与专辑的副标题, 一并刷新
+func (s SearchService) RefreshArtists(ids ...uint64) {
+	var artists, songs, albums []vo.SearchDocVO
+	if err := s.searchRepo.GetArtistDocs(&artists, ids); err != nil {
+		log.Printf("SearchService.RefreshArtists err: %v\n", err)
+		return
+	}
+	if err := s.searchRepo.GetSongDocsByArtists(&songs, ids); err != nil {
+		log.Printf("SearchService.RefreshArtists err: %v\n", err)
+		return
+	}
+	if err := s.searchRepo.GetAlbumDocsByArtists(&albums, ids); err != nil {
+		log.Printf("SearchService.RefreshArtists err: %v\n", err)
+		return
+	}
+	docs := appendDocs(nil, search.TypeArtist, artists)
+	docs = appendDocs(docs, search.TypeSong, songs)
+	docs = appendDocs(docs, search.TypeAlbum, albums)
+	s.index.Upsert(docs...)
+}
+
+// RemoveArtists 歌手删除时其专辑随之级联删除, 名下有歌曲的歌手不允许删除
+func (s SearchService) RemoveArtists(ids ...uint64) {
+	s.index.RemoveByArtist(ids...)
+}
+
+func (s SearchService) RefreshAlbums(ids ...uint64) {
+	var rows []vo.SearchDocVO
+	if err := s.searchRepo.GetAlbumDocs(&rows, ids); err != nil {
+		log.Printf("SearchService.RefreshAlbums err: %v\n", err)
+		return
+	}
+	s.index.Upsert(appendDocs(nil, search.TypeAlbum, rows)...)
+}
+
+func (s SearchService) RemoveAlbums(ids ...uint64) {
+	s.index.Remove(search.TypeAlbum, ids...)
+}
+
+func (s SearchService) RefreshPlaylists(ids ...uint64) {
+	var rows []vo.SearchDocVO
+	if err := s.searchRepo.GetPlaylistDocs(&rows, ids); err != nil {
+		log.Printf("SearchService.RefreshPlaylists err: %v\n", err)
+		return
+	}
+	s.index.Upsert(appendDocs(nil, search.TypePlaylist, rows)...)
+}
+
+func (s SearchService) RemovePlaylists(ids ...uint64) {
+	s.index.Remove(search.TypePlaylist, ids...)
+}
+
+func appendDocs(docs []search.Doc, t search.DocType, rows []vo.SearchDocVO) []search.Doc {
+	for _, row := range rows {
+		doc := search.Doc{
+			Type:     t,
+			ID:       row.ID,
+			Title:    row.Title,
+			Subtitle: row.Subtitle,
+			CoverURL: row.CoverURL,
+			// 收藏数取对数, 避免热度压过文本相关度
+			Boost: min(math.Log1p(float64(row.Popularity)), 5),
+		}
+		if row.ArtistID != 0 {
+			doc.ArtistIDs = []uint64{row.ArtistID}
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// 联想覆盖歌手、歌曲和歌单, 同等热度下歌手优先
+func toSuggestions(docs []search.Doc) []search.Suggestion {
+	typeWeight := map[search.DocType]float64{
+		search.TypeArtist:   1,
+		search.TypeSong:     0.5,
+		search.TypePlaylist: 0,
+	}
+	var out []search.Suggestion
+	for _, doc := range docs {
+		w, ok := typeWeight[doc.Type]
+		if !ok {
+			continue
+		}
+		out = append(out, search.Suggestion{
+			Type:     doc.Type,
+			ID:       doc.ID,
+			Text:     doc.Title,
+			Subtitle: doc.Subtitle,
+			Weight:   doc.Boost + w,
+		})
+	}
+	return out
+}
+
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func toHitVO(hit search.Hit) vo.SearchHitVO {
+	return vo.SearchHitVO{
+		Type:     string(hit.Type),
+		ID:       hit.ID,
+		Title:    hit.Title,
+		Subtitle: hit.Subtitle,
+		CoverURL: hit.CoverURL,
+		Score:    math.Round(hit.Score*100) / 100,
+	}
+}
@@ -1,10 +1,21 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 	"vibe-music-server/internal/model/dto"
 	"vibe-music-server/internal/model/entity"
 	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/audiometa"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/lrc"
 	"vibe-music-server/internal/pkg/result"
 	"vibe-music-server/internal/pkg/result/consts"
 	"vibe-music-server/internal/pkg/util"
@@ -12,20 +23,32 @@ import (
 )
 
 type SongService struct {
-	songRepo     *repo.SongRepo
-	favoriteRepo *repo.FavoriteRepo
-	styleRepo    *repo.StyleRepo
-	genreRepo    *repo.GenreRepo
-	minioService *MinioService
+	songRepo        *repo.SongRepo
+	albumRepo       *repo.AlbumRepo
+	songArtistRepo  *repo.SongArtistRepo
+	favoriteRepo    *repo.FavoriteRepo
+	styleRepo       *repo.StyleRepo
+	genreRepo       *repo.GenreRepo
+	audioMetaRepo   *repo.SongAudioMetaRepo
+	renditionRepo   *repo.SongRenditionRepo
+	storageService  *StorageService
+	searchService   *SearchService
+	deletionService *DeletionService
 }
 
-func NewSongService(songRepo *repo.SongRepo, favoriteRepo *repo.FavoriteRepo, styleRepo *repo.StyleRepo, genreRepo *repo.GenreRepo, minioService *MinioService) *SongService {
+func NewSongService(songRepo *repo.SongRepo, albumRepo *repo.AlbumRepo, songArtistRepo *repo.SongArtistRepo, favoriteRepo *repo.FavoriteRepo, styleRepo *repo.StyleRepo, genreRepo *repo.GenreRepo, audioMetaRepo *repo.SongAudioMetaRepo, renditionRepo *repo.SongRenditionRepo, storageService *StorageService, searchService *SearchService, deletionService *DeletionService) *SongService {
 	return &SongService{
-		songRepo:     songRepo,
-		favoriteRepo: favoriteRepo,
-		styleRepo:    styleRepo,
-		genreRepo:    genreRepo,
-		minioService: minioService,
+		songRepo:        songRepo,
+		albumRepo:       albumRepo,
+		songArtistRepo:  songArtistRepo,
+		favoriteRepo:    favoriteRepo,
+		styleRepo:       styleRepo,
+		genreRepo:       genreRepo,
+		audioMetaRepo:   audioMetaRepo,
+		renditionRepo:   renditionRepo,
+		storageService:  storageService,
+		searchService:   searchService,
+		deletionService: deletionService,
 	}
 }
 
@@ -37,14 +60,18 @@ func (s SongService) GetAllSongs(songDTO *dto.SongDTO, claims *util.Claims) resu
 	pageSize := songDTO.PageSize
 	startIndex := (pageNum - 1) * pageSize
 	var data result.PageResult[vo.SongVO]
-	templateKey := fmt.Sprintf("song:getAllSongs:%v-%v-%v-%v-%v", startIndex, pageSize, *songDTO.SongName, *songDTO.ArtistName, *songDTO.Album)
+	templateKey := util.GenKeyByPattern("song:getAllSongs", startIndex, pageSize, songDTO.SongName, songDTO.ArtistName, songDTO.Album, songDTO.Style)
 	if !util.GetCache(templateKey, &data) {
-		if err := s.songRepo.GetAllSongs(&data, startIndex, pageSize, songDTO.SongName, songDTO.ArtistName, songDTO.Album); err != nil {
+		if err := s.songRepo.GetAllSongs(&data, startIndex, pageSize, songDTO.SongName, songDTO.ArtistName, songDTO.Album, songDTO.Style); err != nil {
 			return retErr(consts.InternalError)
 		}
 		if data.Total == 0 {
 			return retErr(consts.DataNotFound)
 		}
+		if err := fillSongArtists(s.songArtistRepo, data.Items); err != nil {
+			return retErr(consts.InternalError)
+		}
+		s.storageService.FillSrcsets(&data)
 		util.SetCache(templateKey, data)
 	}
 	if claims == nil {
@@ -83,6 +110,7 @@ func (s SongService) GetAllSongsByArtist(songAndArtistDTO *dto.SongAndArtistDTO)
 		if data.Total == 0 {
 			return retErr(consts.DataNotFound)
 		}
+		s.storageService.FillSrcsets(&data)
 		util.SetCache(templateKey, data)
 	}
 	return retSuc(consts.Success, data)
@@ -101,7 +129,11 @@ func (s SongService) GetRecommendedSongs(claims *util.Claims) result.Result[[]vo
 		if len(data) == 0 {
 			return retErr(consts.DataNotFound)
 		}
+		if err := fillSongArtists(s.songArtistRepo, data); err != nil {
+			return retErr(consts.InternalError)
+		}
 		// 默认 LikeStatus 均为 0
+		s.storageService.FillSrcsets(&data)
 		return retSuc(consts.Success, data)
 	}
 	if claims.Role == consts.User {
@@ -119,24 +151,22 @@ func (s SongService) GetRecommendedSongs(claims *util.Claims) result.Result[[]vo
 			if len(data) == 0 {
 				return retErr(consts.DataNotFound)
 			}
+			if err := fillSongArtists(s.songArtistRepo, data); err != nil {
+				return retErr(consts.InternalError)
+			}
 			// 默认 LikeStatus 均为 0
+			s.storageService.FillSrcsets(&data)
 			return retSuc(consts.Success, data)
 		}
 		// 根据用户喜欢的风格，推荐歌曲
-		var favoriteStyles []string
-		if err := s.songRepo.GetStylesByIds(&favoriteStyles, favoriteSongIds); err != nil {
-			return retErr(consts.InternalError)
-		}
 		var favoriteStyleIds []uint64
-		if err := s.styleRepo.GetStyleIdsByNames(&favoriteStyleIds, favoriteStyles); err != nil {
+		if err := s.genreRepo.GetStyleIdsBySongIds(&favoriteStyleIds, favoriteSongIds); err != nil {
 			return retErr(consts.InternalError)
 		}
-		var styleFrequency = make(map[uint64]int)
-		for _, styleId := range favoriteStyleIds {
-			styleFrequency[styleId]++
-		}
-		if err := s.songRepo.GetRecommendedSongsByStyles(&data, favoriteStyles, favoriteSongIds, 10); err != nil {
-			return retErr(consts.InternalError)
+		if len(favoriteStyleIds) > 0 {
+			if err := s.songRepo.GetRecommendedSongsByStyleIds(&data, favoriteStyleIds, favoriteSongIds, 10); err != nil {
+				return retErr(consts.InternalError)
+			}
 		}
 		for len(data) < 10 {
 			var haveIds = make([]uint64, 0, len(data))
@@ -157,6 +187,10 @@ func (s SongService) GetRecommendedSongs(claims *util.Claims) result.Result[[]vo
 			}
 		}
 	}
+	if err := fillSongArtists(s.songArtistRepo, data); err != nil {
+		return retErr(consts.InternalError)
+	}
+	s.storageService.FillSrcsets(&data)
 	return retSuc(consts.Success, data)
 }
 
@@ -173,6 +207,10 @@ func (s SongService) GetSongDetail(songId uint64, claims *util.Claims) result.Re
 		if data.SongID == 0 {
 			return retErr(consts.DataNotFound)
 		}
+		if err := s.songArtistRepo.GetCreditsBySongIds(&data.Artists, []uint64{songId}); err != nil {
+			return retErr(consts.InternalError)
+		}
+		s.storageService.FillSrcsets(&data)
 		util.SetCache(templateKey, data)
 	}
 	if claims == nil {
@@ -202,168 +240,637 @@ func (s SongService) GetAllSongsCount(style *string) result.Result[int64] {
 func (s SongService) AddSong(songAddDTO *dto.SongAddDTO) result.Result[result.Nil] {
 	retErr := result.Error[result.Nil]
 	retSuc := result.Success[result.Nil]
+	credits, msg := s.buildCredits(songAddDTO.ArtistID, songAddDTO.Artists)
+	if msg != "" {
+		return retErr(msg)
+	}
+	styleIds, msg := s.resolveStyles(songAddDTO.Style)
+	if msg != "" {
+		return retErr(msg)
+	}
+	primaryId := credits[0].ArtistID
 	song := entity.Song{
 		Name:        songAddDTO.SongName,
-		ArtistID:    uint(songAddDTO.ArtistID),
+		ArtistID:    uint(primaryId),
 		Album:       songAddDTO.Album,
-		Style:       songAddDTO.Style,
+		DiscNumber:  max(songAddDTO.DiscNumber, 1),
+		TrackNumber: songAddDTO.TrackNumber,
+		Style:       strings.Join(util.ParseStyle(songAddDTO.Style), ","),
 		ReleaseTime: songAddDTO.ReleaseTime,
 	}
-	if err := s.songRepo.CreateSong(&song); err != nil {
-		return retErr(consts.Add + consts.Failed)
-	}
-	// 解析style
-	styles := util.ParseStyle(songAddDTO.Style)
-	var styleIds []uint64
-	if err := s.styleRepo.GetStyleIdsByNames(&styleIds, styles); err != nil {
-		return retErr(consts.InternalError)
-	}
-	for _, styleId := range styleIds {
-		genre := entity.Genre{
-			SongID:  uint64(song.ID),
-			StyleID: styleId,
+	if songAddDTO.AlbumID != nil {
+		title, msg := s.checkAlbum(*songAddDTO.AlbumID, primaryId)
+		if msg != "" {
+			return retErr(msg)
 		}
-		if err := s.genreRepo.CreateGenre(&genre); err != nil {
-			return retErr(consts.Add + consts.Failed)
+		song.AlbumID = songAddDTO.AlbumID
+		song.Album = title
+	}
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		if err := s.createSong(uow, &song, credits, styleIds); err != nil {
+			return err
 		}
+		uow.AfterCommit(func() { s.afterSongsChanged(uint64(song.ID)) })
+		return nil
+	})
+	if err != nil {
+		log.Printf("SongService.AddSong err: %v\n", err)
+		return retErr(consts.Add + consts.Failed)
 	}
-	util.DeleteCacheByPattern("song:*")
 	return retSuc(consts.Add + consts.Success)
 }
 
+// createSong 在工作单元中写入歌曲、署名与风格关联; 刷新索引与缓存由调用方负责
+func (s SongService) createSong(uow *db.UnitOfWork, song *entity.Song, credits []entity.SongArtist, styleIds []uint64) error {
+	if err := s.songRepo.WithTx(uow).CreateSong(song); err != nil {
+		return err
+	}
+	songId := uint64(song.ID)
+	for i := range credits {
+		credits[i].SongID = songId
+	}
+	if err := s.songArtistRepo.WithTx(uow).ReplaceCredits(songId, credits); err != nil {
+		return err
+	}
+	// 写入风格关联并同步冗余风格名
+	return s.genreRepo.WithTx(uow).ReplaceSongGenres(songId, styleIds)
+}
+
 func (s SongService) UpdateSong(songUpdateDTO *dto.SongUpdateDTO) result.Result[result.Nil] {
 	retErr := result.Error[result.Nil]
 	retSuc := result.Success[result.Nil]
 	var song entity.Song
 	if err := s.songRepo.GetSongById(&song, songUpdateDTO.SongID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		log.Printf("SongService.UpdateSong err: %v\n", err)
 		return retErr(consts.InternalError)
 	}
-	if song.ID == 0 {
-		return retErr(consts.DataNotFound)
+	credits, msg := s.buildCredits(songUpdateDTO.ArtistID, songUpdateDTO.Artists)
+	if msg != "" {
+		return retErr(msg)
+	}
+	styleIds, msg := s.resolveStyles(songUpdateDTO.Style)
+	if msg != "" {
+		return retErr(msg)
+	}
+	primaryId := credits[0].ArtistID
+	album := songUpdateDTO.Album
+	if songUpdateDTO.AlbumID != nil {
+		title, msg := s.checkAlbum(*songUpdateDTO.AlbumID, primaryId)
+		if msg != "" {
+			return retErr(msg)
+		}
+		album = title
 	}
 	song = entity.Song{
 		ID:          uint(songUpdateDTO.SongID),
 		Name:        songUpdateDTO.SongName,
-		ArtistID:    uint(songUpdateDTO.ArtistID),
-		Album:       songUpdateDTO.Album,
-		Style:       songUpdateDTO.Style,
+		ArtistID:    uint(primaryId),
 		ReleaseTime: songUpdateDTO.ReleaseTime,
 	}
-	if err := s.songRepo.UpdateSong(&song); err != nil {
+	for i := range credits {
+		credits[i].SongID = songUpdateDTO.SongID
+	}
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		songRepo := s.songRepo.WithTx(uow)
+		if err := songRepo.UpdateSong(&song); err != nil {
+			return err
+		}
+		if err := s.songArtistRepo.WithTx(uow).ReplaceCredits(songUpdateDTO.SongID, credits); err != nil {
+			return err
+		}
+		// 专辑字段允许置空, 单独更新
+		if err := songRepo.UpdateSongAlbum(songUpdateDTO.SongID, songUpdateDTO.AlbumID, album,
+			max(songUpdateDTO.DiscNumber, 1), songUpdateDTO.TrackNumber); err != nil {
+			return err
+		}
+		// 覆盖风格关联并同步冗余风格名
+		if err := s.genreRepo.WithTx(uow).ReplaceSongGenres(songUpdateDTO.SongID, styleIds); err != nil {
+			return err
+		}
+		uow.AfterCommit(func() { s.afterSongsChanged(songUpdateDTO.SongID) })
+		return nil
+	})
+	if err != nil {
+		log.Printf("SongService.UpdateSong err: %v\n", err)
 		return retErr(consts.Update + consts.Failed)
 	}
-	// 删除原有的风格关联
-	if err := s.genreRepo.DeleteGenresBySongId(songUpdateDTO.SongID); err != nil {
-		return retErr(consts.InternalError)
+	return retSuc(consts.Update + consts.Success)
+}
+
+// checkAlbum 校验专辑存在且属于该歌手, 返回专辑标题或错误信息
+func (s SongService) checkAlbum(albumId, artistId uint64) (string, string) {
+	var album entity.Album
+	if err := s.albumRepo.GetAlbumById(&album, albumId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", consts.Album + consts.NotExist
+		}
+		return "", consts.InternalError
+	}
+	if album.ArtistID != artistId {
+		return "", consts.InvalidParams
+	}
+	return album.Title, ""
+}
+
+// resolveStyles 解析风格串为风格 id, 存在未登记的风格时返回错误信息
+func (s SongService) resolveStyles(style string) ([]uint64, string) {
+	styles := util.ParseStyle(style)
+	if len(styles) == 0 {
+		return nil, ""
 	}
-	// 解析style
-	styles := util.ParseStyle(songUpdateDTO.Style)
 	var styleIds []uint64
 	if err := s.styleRepo.GetStyleIdsByNames(&styleIds, styles); err != nil {
-		return retErr(consts.InternalError)
+		return nil, consts.InternalError
+	}
+	if len(styleIds) != len(styles) {
+		return nil, consts.Style + consts.NotExist
+	}
+	return styleIds, ""
+}
+
+// buildCredits 整理署名列表: 去重, 保证主歌手以主要身份排在首位, 返回列表或错误信息
+func (s SongService) buildCredits(artistId uint64, creditDTOs []dto.SongCreditDTO) ([]entity.SongArtist, string) {
+	if artistId == 0 {
+		for _, c := range creditDTOs {
+			if entity.CreditRole(c.Role) == entity.CreditRolePrimary {
+				artistId = c.ArtistID
+				break
+			}
+		}
+	}
+	if artistId == 0 {
+		return nil, consts.InvalidParams
 	}
-	for _, styleId := range styleIds {
-		genre := entity.Genre{
-			SongID:  songUpdateDTO.SongID,
-			StyleID: styleId,
+	credits := []entity.SongArtist{{ArtistID: artistId, Role: entity.CreditRolePrimary}}
+	artistIds := []uint64{artistId}
+	seen := map[entity.SongArtist]bool{credits[0]: true}
+	for _, c := range creditDTOs {
+		credit := entity.SongArtist{ArtistID: c.ArtistID, Role: entity.CreditRole(c.Role)}
+		if seen[credit] {
+			continue
 		}
-		if err := s.genreRepo.CreateGenre(&genre); err != nil {
-			return retErr(consts.Add + consts.Failed)
+		seen[credit] = true
+		credit.Sort = uint(len(credits))
+		credits = append(credits, credit)
+		if !slices.Contains(artistIds, c.ArtistID) {
+			artistIds = append(artistIds, c.ArtistID)
 		}
 	}
-	util.DeleteCacheByPattern("song:*")
-	return retSuc(consts.Update + consts.Success)
+	var count int64
+	if err := s.songArtistRepo.CountExistArtists(&count, artistIds); err != nil {
+		return nil, consts.InternalError
+	}
+	if count != int64(len(artistIds)) {
+		return nil, consts.Artist + consts.NotExist
+	}
+	return credits, ""
 }
 
-func (s SongService) UpdateSongCover(songId uint64, coverUrl string) result.Result[result.Nil] {
-	var song entity.Song
-	if err := s.songRepo.GetSongById(&song, songId); err != nil {
-		return result.Error[result.Nil](consts.InternalError)
+// fillSongArtists 批量填充歌曲的署名歌手
+func fillSongArtists(songArtistRepo *repo.SongArtistRepo, songs []vo.SongVO) error {
+	if len(songs) == 0 {
+		return nil
+	}
+	songIds := make([]uint64, 0, len(songs))
+	for _, song := range songs {
+		songIds = append(songIds, song.SongID)
+	}
+	var credits []vo.SongArtistVO
+	if err := songArtistRepo.GetCreditsBySongIds(&credits, songIds); err != nil {
+		return err
 	}
-	if song.ID == 0 {
-		return result.Error[result.Nil](consts.DataNotFound)
+	creditMap := make(map[uint64][]vo.SongArtistVO, len(songs))
+	for _, credit := range credits {
+		creditMap[credit.SongID] = append(creditMap[credit.SongID], credit)
 	}
-	song.CoverURL = coverUrl
-	if err := s.songRepo.UpdateSong(&song); err != nil {
+	for i := range songs {
+		songs[i].Artists = creditMap[songs[i].SongID]
+	}
+	return nil
+}
+
+func (s SongService) UpdateSongCover(songId uint64, coverUrl string) result.Result[result.Nil] {
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		// 歌曲不存在或写库失败时释放刚上传的封面
+		s.storageService.DeleteFileOnRollback(uow, coverUrl)
+		songRepo := s.songRepo.WithTx(uow)
+		var song entity.Song
+		if err := songRepo.GetSongById(&song, songId); err != nil {
+			return err
+		}
+		oldCover := song.CoverURL
+		song.CoverURL = coverUrl
+		if err := songRepo.UpdateSong(&song); err != nil {
+			return err
+		}
+		// 新旧相同时新上传也占用了一次引用, 同样要释放
+		s.storageService.DeleteFileAfterCommit(uow, oldCover)
+		uow.AfterCommit(func() {
+			s.searchService.RefreshSongs(songId)
+			util.DeleteCacheByPattern("song:*")
+		})
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return result.Error[result.Nil](consts.DataNotFound)
+		}
+		log.Printf("SongService.UpdateSongCover err: %v\n", err)
 		return result.Error[result.Nil](consts.Update + consts.Failed)
 	}
-	util.DeleteCacheByPattern("song:*")
 	return result.Success[result.Nil](consts.Update + consts.Success)
 }
 
-func (s SongService) UpdateSongAudio(songId uint64, audioUrl string) result.Result[result.Nil] {
+// GetSongLyrics 返回解析后的逐行歌词, 翻译按时间戳并入对应行
+func (s SongService) GetSongLyrics(songId uint64) result.Result[vo.LyricVO] {
+	retErr := result.Error[vo.LyricVO]
+	retSuc := result.SuccessWithData[vo.LyricVO]
+	var data vo.LyricVO
+	templateKey := fmt.Sprintf("song:getSongLyrics:%v", songId)
+	if util.GetCache(templateKey, &data) {
+		return retSuc(consts.Success, data)
+	}
 	var song entity.Song
-	if err := s.songRepo.GetSongById(&song, songId); err != nil {
-		return result.Error[result.Nil](consts.InternalError)
+	if err := s.songRepo.GetSongLyric(&song, songId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
 	}
-	if song.ID == 0 {
-		return result.Error[result.Nil](consts.DataNotFound)
+	if song.Lyric == "" {
+		return retErr(consts.Lyric + consts.NotExist)
 	}
-	song.AudioURL = audioUrl
-	if err := s.songRepo.UpdateSong(&song); err != nil {
-		return result.Error[result.Nil](consts.Update + consts.Failed)
+	data.SongID = songId
+	lyrics, err := lrc.Parse(song.Lyric)
+	if err != nil {
+		// 早期录入的纯文本歌词
+		data.Lines = toLyricLineVOs(lrc.PlainLines(song.Lyric))
+		util.SetCache(templateKey, data)
+		return retSuc(consts.Success, data)
 	}
-	util.DeleteCacheByPattern("song:*")
-	return result.Success[result.Nil](consts.Update + consts.Success)
+	if song.Translation != "" {
+		if tr, err := lrc.Parse(song.Translation); err == nil {
+			lyrics.MergeTranslation(tr)
+		}
+	}
+	data.Synced = true
+	data.Title = lyrics.Title
+	data.Artist = lyrics.Artist
+	data.Album = lyrics.Album
+	data.By = lyrics.By
+	data.Lines = toLyricLineVOs(lyrics.Lines)
+	util.SetCache(templateKey, data)
+	return retSuc(consts.Success, data)
 }
 
-func (s SongService) DeleteSong(songId uint64) result.Result[result.Nil] {
+// UpdateSongLyric 校验并保存 LRC 歌词, 格式错误时返回出错行号
+func (s SongService) UpdateSongLyric(songLyricDTO *dto.SongLyricDTO) result.Result[result.Nil] {
 	retErr := result.Error[result.Nil]
 	retSuc := result.Success[result.Nil]
+	lyric := lrc.Clean(songLyricDTO.Lyric)
+	translation := lrc.Clean(songLyricDTO.Translation)
+	if lyric == "" && translation != "" {
+		return retErr(consts.Lyric + consts.NotNull)
+	}
+	if lyric != "" {
+		if _, err := lrc.Parse(lyric); err != nil {
+			return retErr(consts.Lyric + consts.FormatError + ": " + err.Error())
+		}
+	}
+	if translation != "" {
+		if _, err := lrc.Parse(translation); err != nil {
+			return retErr(consts.Translation + consts.FormatError + ": " + err.Error())
+		}
+	}
 	var song entity.Song
-	if err := s.songRepo.GetSongById(&song, songId); err != nil {
+	if err := s.songRepo.GetSongById(&song, songLyricDTO.SongID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
 		return retErr(consts.InternalError)
 	}
-	if song.ID == 0 {
-		return retErr(consts.DataNotFound)
+	if err := s.songRepo.UpdateSongLyric(songLyricDTO.SongID, lyric, translation); err != nil {
+		return retErr(consts.Update + consts.Failed)
 	}
-	// 删除歌曲文件和封面
-	if err := s.minioService.DeleteFile(song.CoverURL); err != nil {
-		return retErr(consts.Delete + consts.Failed)
+	util.DeleteCacheByPattern("song:*")
+	return retSuc(consts.Update + consts.Success)
+}
+
+func toLyricLineVOs(lines []lrc.Line) []vo.LyricLineVO {
+	out := make([]vo.LyricLineVO, 0, len(lines))
+	for _, line := range lines {
+		lineVO := vo.LyricLineVO{
+			Time:        line.Time,
+			Duration:    line.Duration,
+			Text:        line.Text,
+			Translation: line.Translation,
+		}
+		for _, w := range line.Words {
+			lineVO.Words = append(lineVO.Words, vo.LyricWordVO{Time: w.Time, Duration: w.Duration, Text: w.Text})
+		}
+		out = append(out, lineVO)
 	}
-	if err := s.minioService.DeleteFile(song.AudioURL); err != nil {
-		return retErr(consts.Delete + consts.Failed)
+	return out
+}
+
+// UpdateSongAudio 替换音频并提取元数据, 提取结果待管理员确认后再写入歌曲
+func (s SongService) UpdateSongAudio(songId uint64, audioUrl string, audio io.ReaderAt, size int64) result.Result[vo.SongAudioMetaVO] {
+	retErr := result.Error[vo.SongAudioMetaVO]
+	var song entity.Song
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		s.storageService.DeleteFileOnRollback(uow, audioUrl)
+		songRepo := s.songRepo.WithTx(uow)
+		if err := songRepo.GetSongById(&song, songId); err != nil {
+			return err
+		}
+		oldAudio := song.AudioURL
+		song.AudioURL = audioUrl
+		if err := songRepo.UpdateSong(&song); err != nil {
+			return err
+		}
+		s.storageService.DeleteFileAfterCommit(uow, oldAudio)
+		uow.AfterCommit(func() { util.DeleteCacheByPattern("song:*") })
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		log.Printf("SongService.UpdateSongAudio err: %v\n", err)
+		return retErr(consts.Update + consts.Failed)
 	}
-	// 删除歌曲信息
-	if err := s.songRepo.DeleteSongById(songId); err != nil {
-		return retErr(consts.Delete + consts.Failed)
+
+	meta, err := audiometa.Read(audio, size)
+	if err != nil {
+		// 无法识别的格式不影响音频替换
+		log.Printf("SongService.UpdateSongAudio err: %v\n", err)
+		return result.Success[vo.SongAudioMetaVO](consts.Update + consts.Success)
+	}
+	pending := entity.SongAudioMeta{
+		SongID:      songId,
+		AudioURL:    audioUrl,
+		Codec:       meta.Codec,
+		DurationMs:  meta.Duration.Milliseconds(),
+		Bitrate:     meta.Bitrate,
+		SampleRate:  meta.SampleRate,
+		Channels:    meta.Channels,
+		Title:       truncateRunes(meta.Title, 200),
+		Artist:      truncateRunes(meta.Artist, 200),
+		Album:       truncateRunes(meta.Album, 200),
+		ReleaseDate: truncateRunes(meta.Date, 20),
+		Genre:       truncateRunes(meta.Genre, 100),
+		TrackNumber: uint(max(meta.TrackNumber, 0)),
+		DiscNumber:  uint(max(meta.DiscNumber, 0)),
+		Lyric:       lrc.Clean(meta.Lyrics),
+		CreateTime:  time.Now(),
+	}
+	if len(pending.Lyric) > lrc.MaxSize {
+		pending.Lyric = ""
+	}
+	if meta.Cover != nil {
+		if coverUrl, err := s.storageService.UploadBytes(meta.Cover.Data, "songCovers", "cover"); err == nil {
+			pending.CoverURL = coverUrl
+		} else {
+			log.Printf("SongService.UpdateSongAudio err: %v\n", err)
+		}
 	}
-	// 删除风格关联
-	if err := s.genreRepo.DeleteGenresBySongId(songId); err != nil {
+	s.discardPendingCover(songId)
+	if err := s.audioMetaRepo.SaveAudioMeta(&pending); err != nil {
 		return retErr(consts.InternalError)
 	}
-	util.DeleteCacheByPattern("song:*")
-	return retSuc(consts.Delete + consts.Success)
+	return result.SuccessWithData(consts.Update+consts.Success, toSongAudioMetaVO(pending, song))
 }
 
-func (s SongService) DeleteSongs(songIds []uint64) result.Result[result.Nil] {
-	retErr := result.Error[result.Nil]
-	retSuc := result.Success[result.Nil]
-	var covers []string
-	var audios []string
-	if err := s.songRepo.GetCoversByIds(&covers, songIds); err != nil {
+func (s SongService) GetSongAudioMeta(songId uint64) result.Result[vo.SongAudioMetaVO] {
+	retErr := result.Error[vo.SongAudioMetaVO]
+	retSuc := result.SuccessWithData[vo.SongAudioMetaVO]
+	var pending entity.SongAudioMeta
+	if err := s.audioMetaRepo.GetAudioMeta(&pending, songId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
 		return retErr(consts.InternalError)
 	}
-	if err := s.songRepo.GetAudiosByIds(&audios, songIds); err != nil {
+	var song entity.Song
+	if err := s.songRepo.GetSongById(&song, songId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		log.Printf("SongService.GetSongAudioMeta err: %v\n", err)
 		return retErr(consts.InternalError)
 	}
-	for _, cover := range covers {
-		if err := s.minioService.DeleteFile(cover); err != nil {
-			return retErr(consts.Delete + consts.Failed)
+	return retSuc(consts.Success, toSongAudioMetaVO(pending, song))
+}
+
+// errAudioMetaAlbumAbsent 提取到的专辑名在该歌手名下不存在
+var errAudioMetaAlbumAbsent = errors.New("album of extracted metadata not found")
+
+// ApplySongAudioMeta 将审核通过的字段写入歌曲, 未提取到值的字段跳过
+func (s SongService) ApplySongAudioMeta(applyDTO *dto.SongAudioMetaApplyDTO) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	apply := func(field string) bool {
+		return len(applyDTO.Fields) == 0 || slices.Contains(applyDTO.Fields, field)
+	}
+	var pending entity.SongAudioMeta
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		songRepo := s.songRepo.WithTx(uow)
+		audioMetaRepo := s.audioMetaRepo.WithTx(uow)
+		if err := audioMetaRepo.GetAudioMeta(&pending, applyDTO.SongID); err != nil {
+			return err
+		}
+		var song entity.Song
+		if err := songRepo.GetSongById(&song, applyDTO.SongID); err != nil {
+			return err
+		}
+		updates := make(map[string]any)
+		if apply("duration") && pending.DurationMs > 0 {
+			updates["duration"] = formatDuration(pending.DurationMs)
 		}
+		if apply("format") && pending.Codec != "" {
+			updates["codec"] = pending.Codec
+			updates["bitrate"] = pending.Bitrate
+			updates["sample_rate"] = pending.SampleRate
+		}
+		if apply("title") && pending.Title != "" {
+			updates["name"] = pending.Title
+		}
+		if apply("releaseTime") {
+			if releaseTime, ok := parseReleaseDate(pending.ReleaseDate); ok {
+				updates["release_time"] = releaseTime
+			}
+		}
+		if apply("track") {
+			if pending.TrackNumber > 0 {
+				updates["track_number"] = pending.TrackNumber
+			}
+			if pending.DiscNumber > 0 {
+				updates["disc_number"] = pending.DiscNumber
+			}
+		}
+		if apply("lyric") && pending.Lyric != "" {
+			updates["lyric"] = pending.Lyric
+		}
+		coverApplied := apply("cover") && pending.CoverURL != ""
+		if coverApplied {
+			updates["cover_url"] = pending.CoverURL
+		}
+		// 专辑只关联该歌手名下已有的同名专辑
+		if apply("album") && pending.Album != "" {
+			var album entity.Album
+			if err := s.albumRepo.WithTx(uow).GetAlbumByTitle(&album, uint64(song.ArtistID), pending.Album); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errAudioMetaAlbumAbsent
+				}
+				return err
+			}
+			updates["album_id"] = album.ID
+			updates["album"] = album.Title
+		}
+		if len(updates) > 0 {
+			if err := songRepo.UpdateSongFields(applyDTO.SongID, updates); err != nil {
+				return err
+			}
+		}
+		if err := audioMetaRepo.DeleteAudioMeta(applyDTO.SongID); err != nil {
+			return err
+		}
+		if coverApplied {
+			// 提取的封面取代了原封面, 提交后释放原封面的引用
+			s.storageService.DeleteFileAfterCommit(uow, song.CoverURL)
+		} else {
+			// 未被采用的提取封面
+			s.storageService.DeleteFileAfterCommit(uow, pending.CoverURL)
+		}
+		uow.AfterCommit(func() {
+			s.searchService.RefreshSongs(applyDTO.SongID)
+			util.DeleteCacheByPattern("song:*")
+			util.DeleteCacheByPattern("album:*")
+		})
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		if errors.Is(err, errAudioMetaAlbumAbsent) {
+			return retErr(consts.Album + consts.NotExist + ": " + pending.Album)
+		}
+		log.Printf("SongService.ApplySongAudioMeta err: %v\n", err)
+		return retErr(consts.Update + consts.Failed)
+	}
+	return retSuc(consts.Update + consts.Success)
+}
+
+func (s SongService) DiscardSongAudioMeta(songId uint64) result.Result[result.Nil] {
+	s.discardPendingCover(songId)
+	if err := s.audioMetaRepo.DeleteAudioMeta(songId); err != nil {
+		return result.Error[result.Nil](consts.Delete + consts.Failed)
+	}
+	return result.Success[result.Nil](consts.Delete + consts.Success)
+}
+
+// discardPendingCover 删除未被采用的提取封面
+func (s SongService) discardPendingCover(songId uint64) {
+	var pending entity.SongAudioMeta
+	if err := s.audioMetaRepo.GetAudioMeta(&pending, songId); err != nil || pending.CoverURL == "" {
+		return
+	}
+	if err := s.storageService.DeleteFile(pending.CoverURL); err != nil {
+		log.Printf("SongService.discardPendingCover err: %v\n", err)
 	}
-	for _, audio := range audios {
-		if err := s.minioService.DeleteFile(audio); err != nil {
-			return retErr(consts.Delete + consts.Failed)
+}
+
+func toSongAudioMetaVO(pending entity.SongAudioMeta, song entity.Song) vo.SongAudioMetaVO {
+	return vo.SongAudioMetaVO{
+		SongID:      pending.SongID,
+		AudioURL:    pending.AudioURL,
+		Codec:       pending.Codec,
+		Duration:    formatDuration(pending.DurationMs),
+		Bitrate:     pending.Bitrate,
+		SampleRate:  pending.SampleRate,
+		Channels:    pending.Channels,
+		Title:       pending.Title,
+		Artist:      pending.Artist,
+		Album:       pending.Album,
+		ReleaseDate: pending.ReleaseDate,
+		Genre:       pending.Genre,
+		TrackNumber: pending.TrackNumber,
+		DiscNumber:  pending.DiscNumber,
+		Lyric:       pending.Lyric,
+		CoverURL:    pending.CoverURL,
+		CreateTime:  pending.CreateTime,
+		Current: vo.SongAudioCurrentVO{
+			SongName:    song.Name,
+			Album:       song.Album,
+			Duration:    song.Duration,
+			Codec:       song.Codec,
+			Bitrate:     song.Bitrate,
+			SampleRate:  song.SampleRate,
+			ReleaseTime: song.ReleaseTime,
+			TrackNumber: song.TrackNumber,
+			DiscNumber:  song.DiscNumber,
+			HasLyric:    song.Lyric != "",
+			CoverURL:    song.CoverURL,
+		},
+	}
+}
+
+// formatDuration 歌曲时长按秒保存, 保留两位小数
+func formatDuration(ms int64) string {
+	return fmt.Sprintf("%.2f", float64(ms)/1000)
+}
+
+// parseReleaseDate 兼容标签中的 yyyy、yyyy-mm、yyyy-mm-dd 及带时间的写法
+func parseReleaseDate(date string) (time.Time, bool) {
+	date = strings.TrimSpace(date)
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+		if len(date) >= len(layout) {
+			if t, err := time.Parse(layout, date[:len(layout)]); err == nil {
+				return t, true
+			}
 		}
 	}
-	if err := s.songRepo.DeleteSongByIds(songIds); err != nil {
-		return retErr(consts.Delete + consts.Failed)
+	return time.Time{}, false
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
 	}
-	// 删除风格关联
-	if err := s.genreRepo.DeleteGenresBySongIds(songIds); err != nil {
-		return retErr(consts.InternalError)
+	return string([]rune(s)[:n])
+}
+
+func (s SongService) DeleteSong(songId uint64) result.Result[result.Nil] {
+	var song entity.Song
+	if err := s.songRepo.GetSongById(&song, songId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return result.Error[result.Nil](consts.DataNotFound)
+		}
+		log.Printf("SongService.DeleteSong err: %v\n", err)
+		return result.Error[result.Nil](consts.InternalError)
 	}
+	return s.DeleteSongs([]uint64{songId})
+}
+
+// DeleteSongs 将歌曲移入回收站, 收藏、歌单收录、评论与文件在回收站中清除时才随之删除
+func (s SongService) DeleteSongs(songIds []uint64) result.Result[result.Nil] {
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		return s.deletionService.SoftDelete(uow, "tb_song", songIds)
+	})
+	if err != nil {
+		log.Printf("SongService.DeleteSongs err: %v\n", err)
+		return result.Error[result.Nil](deleteFailedMessage(err))
+	}
+	return result.Success[result.Nil](consts.Delete + consts.Success)
+}
+
+// afterSongsChanged 歌曲写入提交后刷新搜索索引并清除缓存
+func (s SongService) afterSongsChanged(songIds ...uint64) {
+	s.searchService.RefreshSongs(songIds...)
 	util.DeleteCacheByPattern("song:*")
-	return retSuc(consts.Delete + consts.Success)
+	util.DeleteCacheByPattern("album:*")
 }
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/upload"
+)
+
+// FillSrcsets 为 data(VO 或其指针、切片、分页结果)中所有 vo.ImageSrcset 填充缩略图地址.
+// 缩略图以上传时记录在 tb_storage_object 中的宽度为准; 没有记录的图片(按内容寻址之前上传)不填充
+func (s StorageService) FillSrcsets(data any) {
+	var targets []vo.ImageSrcset
+	collectSrcsets(reflect.ValueOf(data), &targets)
+	if len(targets) == 0 {
+		return
+	}
+	keys := make(map[string]string, len(targets)) // 图片地址 -> 对象名
+	var objectKeys []string
+	for _, target := range targets {
+		imageURL, _ := target.SrcsetImage()
+		if _, ok := keys[imageURL]; ok || imageURL == "" {
+			continue
+		}
+		key, err := s.ObjectName(imageURL)
+		if err != nil {
+			continue
+		}
+		keys[imageURL] = key
+		objectKeys = append(objectKeys, key)
+	}
+	if len(objectKeys) == 0 {
+		return
+	}
+	var objs []entity.StorageObject
+	if err := s.storageObjectRepo.GetStorageObjectsByKeys(&objs, objectKeys); err != nil {
+		log.Printf("StorageService.FillSrcsets err: %v\n", err)
+		return
+	}
+	srcsets := make(map[string]map[string]string, len(objs))
+	for _, obj := range objs {
+		if srcset := s.srcset(obj); len(srcset) > 0 {
+			srcsets[obj.ObjectKey] = srcset
+		}
+	}
+	for _, target := range targets {
+		imageURL, srcset := target.SrcsetImage()
+		if key, ok := keys[imageURL]; ok && srcsets[key] != nil {
+			*srcset = srcsets[key]
+		}
+	}
+}
+
+// srcset 对象各宽度缩略图的访问地址, 键为宽度
+func (s StorageService) srcset(obj entity.StorageObject) map[string]string {
+	if obj.Renditions == nil {
+		renditions, ext, err := s.recordRenditions(obj.ObjectKey)
+		if err != nil {
+			log.Printf("StorageService.srcset err: %v\n", err)
+			return nil
+		}
+		obj.Renditions, obj.RenditionExt = &renditions, ext
+	}
+	if *obj.Renditions == "" {
+		return nil
+	}
+	widths := strings.Split(*obj.Renditions, ",")
+	srcset := make(map[string]string, len(widths))
+	for _, w := range widths {
+		width, err := strconv.Atoi(w)
+		if err != nil {
+			continue
+		}
+		srcset[w] = s.ObjectURL(upload.ThumbnailKey(obj.ObjectKey, width, obj.RenditionExt))
+	}
+	return srcset
+}
+
+// recordRenditions 为增加记录之前上传的对象补记存储中实际存在的缩略图
+func (s StorageService) recordRenditions(objectKey string) (string, string, error) {
+	var widths []int
+	ext := ""
+	if prefix, ok := upload.ThumbnailPrefix(objectKey); ok {
+		thumbnails, err := s.ListObjects(prefix)
+		if err != nil {
+			return "", "", err
+		}
+		for _, thumbnail := range thumbnails {
+			if width, e, ok := upload.ParseThumbnailKey(thumbnail.Key); ok {
+				widths = append(widths, width)
+				ext = e
+			}
+		}
+	}
+	sort.Ints(widths)
+	list := make([]string, 0, len(widths))
+	for _, w := range widths {
+		list = append(list, strconv.Itoa(w))
+	}
+	renditions := strings.Join(list, ",")
+	return renditions, ext, s.storageObjectRepo.UpdateRenditions(objectKey, renditions, ext)
+}
+
+// collectSrcsets 递归查找可寻址的 vo.ImageSrcset
+func collectSrcsets(v reflect.Value, targets *[]vo.ImageSrcset) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collectSrcsets(v.Elem(), targets)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectSrcsets(v.Index(i), targets)
+		}
+	case reflect.Struct:
+		if v.CanAddr() {
+			if target, ok := v.Addr().Interface().(vo.ImageSrcset); ok {
+				*targets = append(*targets, target)
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectSrcsets(v.Field(i), targets)
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"database/sql"
+	"log"
+	"time"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/repo"
+)
+
+const (
+	playQueueSize       = 4096
+	playBatchSize       = 200
+	dashboardDays       = 30      // 未指定日期范围时统计的天数
+	dashboardMaxDays    = 3 * 366 // 日期范围的上限
+	dashboardTopStyles  = 10
+	dashboardDateLayout = "2006-01-02"
+)
+
+// StatService 记录播放, 维护按天预聚合的统计, 并提供管理端仪表盘
+type StatService struct {
+	statRepo *repo.StatRepo
+	plays    chan entity.PlayLog
+}
+
+func NewStatService(statRepo *repo.StatRepo) *StatService {
+	return &StatService{
+		statRepo: statRepo,
+		plays:    make(chan entity.PlayLog, playQueueSize),
+	}
+}
+
+// RecordPlay 记录一次播放, 由 Run 批量写入; 队列已满时丢弃, 只影响统计.
+// 公开接口不返回音频地址, 播放只能经 RenditionService.GetSongStream 取得地址, 因此在那里记录
+func (s StatService) RecordPlay(songId uint64, claims *util.Claims) {
+	playLog := entity.PlayLog{SongID: songId, CreateTime: time.Now()}
+	if claims != nil && claims.Role == consts.UserRole {
+		userId := claims.UserId
+		playLog.UserID = &userId
+	}
+	select {
+	case s.plays <- playLog:
+	default:
+		log.Printf("StatService.RecordPlay: queue full, play of song %d dropped\n", songId)
+	}
+}
+
+// Run 批量写入播放记录, 并每隔 interval 重算最近的按天统计; 启动时先补齐缺失的天
+func (s StatService) Run(interval time.Duration) {
+	s.refresh()
+	flushTicker := time.NewTicker(time.Second)
+	defer flushTicker.Stop()
+	refreshTicker := time.NewTicker(interval)
+	defer refreshTicker.Stop()
+	batch := make([]entity.PlayLog, 0, playBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.statRepo.AddPlayLogs(batch); err != nil {
+			log.Printf("StatService.Run err: %v\n", err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case playLog := <-s.plays:
+			batch = append(batch, playLog)
+			if len(batch) >= playBatchSize {
+				flush()
+			}
+		case <-flushTicker.C:
+			flush()
+		case <-refreshTicker.C:
+			flush()
+			s.refresh()
+		}
+	}
+}
+
+// refresh 从已统计的最后一天(至少昨天, 午夜前的播放可能稍后才写入)重算到今天; 尚无统计时从最早的数据开始
+func (s StatService) refresh() {
+	today := truncateDay(time.Now())
+	start := today.AddDate(0, 0, -1)
+	var latest sql.NullTime
+	if err := s.statRepo.GetLatestStatDate(&latest); err != nil {
+		log.Printf("StatService.refresh err: %v\n", err)
+		return
+	}
+	if latest.Valid {
+		start = minTime(start, truncateDay(latest.Time))
+	} else {
+		var first sql.NullTime
+		if err := s.statRepo.GetFirstActivityTime(&first); err != nil {
+			log.Printf("StatService.refresh err: %v\n", err)
+			return
+		}
+		if first.Valid {
+			start = minTime(start, truncateDay(first.Time))
+		}
+	}
+	if err := s.Rebuild(start, today); err != nil {
+		log.Printf("StatService.refresh err: %v\n", err)
+	}
+}
+
+// Rebuild 由源表重算 [start, end] 内各天的统计
+func (s StatService) Rebuild(start, end time.Time) error {
+	return db.Transaction(func(uow *db.UnitOfWork) error {
+		return s.statRepo.WithTx(uow).RebuildDailyStats(truncateDay(start), truncateDay(end).AddDate(0, 0, 1))
+	})
+}
+
+// GetFirstActivityDay 最早有数据的一天, 尚无数据时为今天
+func (s StatService) GetFirstActivityDay() (time.Time, error) {
+	var first sql.NullTime
+	if err := s.statRepo.GetFirstActivityTime(&first); err != nil {
+		return time.Time{}, err
+	}
+	if !first.Valid {
+		return truncateDay(time.Now()), nil
+	}
+	return truncateDay(first.Time), nil
+}
+
+func (s StatService) GetDashboard(dashboardDTO *dto.DashboardDTO) result.Result[vo.DashboardVO] {
+	retErr := result.Error[vo.DashboardVO]
+	end := truncateDay(time.Now())
+	if !dashboardDTO.EndDate.IsZero() {
+		end = truncateDay(dashboardDTO.EndDate)
+	}
+	start := end.AddDate(0, 0, 1-dashboardDays)
+	if !dashboardDTO.StartDate.IsZero() {
+		start = truncateDay(dashboardDTO.StartDate)
+	}
+	if start.After(end) || end.Sub(start) > dashboardMaxDays*24*time.Hour {
+		return retErr(consts.InvalidParams)
+	}
+	granularity := dashboardDTO.Granularity
+	if granularity == "" {
+		granularity = "day"
+	}
+
+	data := vo.DashboardVO{
+		StartDate:   start.Format(dashboardDateLayout),
+		EndDate:     end.Format(dashboardDateLayout),
+		Granularity: granularity,
+		TopStyles:   []vo.DashboardStyleVO{},
+		Storage:     []vo.DashboardStorageVO{},
+	}
+	if err := s.statRepo.GetTotals(&data.Totals); err != nil {
+		log.Printf("StatService.GetDashboard err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	series := []struct {
+		metric string
+		points *[]vo.DashboardPointVO
+	}{
+		{entity.MetricNewUsers, &data.NewUsers},
+		{entity.MetricPlays, &data.Plays},
+		{entity.MetricFavorites, &data.Favorites},
+	}
+	for _, ser := range series {
+		var points []repo.StatPoint
+		if err := s.statRepo.GetStatSeries(&points, ser.metric, granularity, start, end); err != nil {
+			log.Printf("StatService.GetDashboard err: %v\n", err)
+			return retErr(consts.InternalError)
+		}
+		*ser.points = fillStatSeries(points, granularity, start, end)
+	}
+	if err := s.statRepo.GetTopStyles(&data.TopStyles, start, end, dashboardTopStyles); err != nil {
+		log.Printf("StatService.GetDashboard err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	if err := s.statRepo.GetStorageUsage(&data.Storage); err != nil {
+		log.Printf("StatService.GetDashboard err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	return result.SuccessWithData(consts.Success, data)
+}
+
+// fillStatSeries 按粒度列出 [start, end] 内的每个周期, 没有数据的周期值为 0
+func fillStatSeries(points []repo.StatPoint, granularity string, start, end time.Time) []vo.DashboardPointVO {
+	values := make(map[string]int64, len(points))
+	for _, p := range points {
+		values[p.Period.Format(dashboardDateLayout)] = p.Value
+	}
+	var data []vo.DashboardPointVO
+	for period := periodStart(start, granularity); !period.After(end); period = nextPeriod(period, granularity) {
+		key := period.Format(dashboardDateLayout)
+		data = append(data, vo.DashboardPointVO{Period: key, Value: values[key]})
+	}
+	return data
+}
+
+// periodStart 日期所属周期的第一天, 与 repo 中的 statPeriods 一致
+func periodStart(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case "week":
+		return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return t
+}
+
+func nextPeriod(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,475 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/storage"
+	"vibe-music-server/internal/pkg/upload"
+	"vibe-music-server/internal/repo"
+)
+
+// StorageService 文件上传、删除等对象存储操作, 具体存储由配置 storage.driver 决定.
+// 上传的文件按内容寻址: 同一上传策略下相同内容只存一份, 由 tb_storage_object 记录引用计数
+type StorageService struct {
+	store             storage.Storage
+	storageObjectRepo *repo.StorageObjectRepo
+	ctx               context.Context
+}
+
+func NewStorageService(store storage.Storage, storageObjectRepo *repo.StorageObjectRepo) *StorageService {
+	return &StorageService{
+		store:             store,
+		storageObjectRepo: storageObjectRepo,
+		ctx:               context.Background(),
+	}
+}
+
+// UploadFile 按目录对应的上传策略校验后上传, 不符合策略时返回 *upload.RejectError.
+// 每次成功上传都占用一次引用, 不再使用时需通过 DeleteFile 释放
+func (s StorageService) UploadFile(file *multipart.FileHeader, folder string) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("open multipart: %w", err)
+	}
+	defer src.Close()
+	return s.put(src, file.Size, folder, file.Filename)
+}
+
+// UploadBytes 上传内存中的数据, 如从音频中提取的封面
+func (s StorageService) UploadBytes(data []byte, folder, filename string) (string, error) {
+	return s.put(bytes.NewReader(data), int64(len(data)), folder, filename)
+}
+
+// UploadReader 上传可随机读取的数据, 如从压缩包中解出的临时文件
+func (s StorageService) UploadReader(src io.ReadSeeker, size int64, folder, filename string) (string, error) {
+	return s.put(src, size, folder, filename)
+}
+
+func (s StorageService) put(src io.ReadSeeker, size int64, folder, filename string) (string, error) {
+	// 1. 校验大小、真实类型与图片尺寸
+	policy, ok := upload.PolicyFor(folder)
+	if !ok {
+		return "", &upload.RejectError{Msg: consts.UnknownFolder}
+	}
+	f, err := policy.Check(src, size)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. 已有相同内容时直接引用
+	sum, err := hashContent(src)
+	if err != nil {
+		return "", err
+	}
+	objectName, err := s.acquire(policy.Name, sum)
+	if err != nil {
+		return "", err
+	}
+	if objectName != "" {
+		return s.ObjectURL(objectName), nil
+	}
+
+	// 3. 上传到 策略名/SHA-256/清理后的文件名, 超时随文件大小增长; Content-Type 以嗅探结果为准
+	dir := path.Join(policy.Name, sum)
+	var objects []upload.Object
+	if f.Width > 0 && len(policy.Renditions) > 0 {
+		objects, err = s.putImage(src, dir, filename, policy.Renditions)
+	} else {
+		objects, err = s.putFile(src, size, path.Join(dir, upload.SanitizeFilename(filename, f.Ext)), f.ContentType)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// 4. 登记并返回拼接好的访问 URL
+	objectName, err = s.register(entity.StorageObject{
+		ObjectKey: objects[0].Key,
+		Folder:    policy.Name,
+		Sha256:    sum,
+		Size:      size,
+	}, objects)
+	if err != nil {
+		return "", err
+	}
+	return s.ObjectURL(objectName), nil
+}
+
+func (s StorageService) putFile(src io.Reader, size int64, objectName, contentType string) ([]upload.Object, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, upload.Timeout(size))
+	defer cancel()
+	if err := s.store.Put(ctx, objectName, src, size, contentType); err != nil {
+		return nil, fmt.Errorf("put object: %w", err)
+	}
+	return []upload.Object{{Key: objectName, ContentType: contentType}}, nil
+}
+
+// putImage 上传重新编码后的原尺寸图及各宽度缩略图, 第一个为原尺寸图; 任一失败则清理已上传的对象
+func (s StorageService) putImage(src io.Reader, dir, filename string, widths []int) ([]upload.Object, error) {
+	objects, err := upload.ProcessImage(src, dir, filename, widths)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, obj := range objects {
+		total += int64(len(obj.Data))
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, upload.Timeout(total))
+	defer cancel()
+	for i, obj := range objects {
+		err = s.store.Put(ctx, obj.Key, bytes.NewReader(obj.Data), int64(len(obj.Data)), obj.ContentType)
+		if err != nil {
+			for _, uploaded := range objects[:i] {
+				_ = s.store.Remove(s.ctx, uploaded.Key)
+			}
+			return nil, fmt.Errorf("put object: %w", err)
+		}
+	}
+	return objects, nil
+}
+
+// acquire 按内容查找已存储的对象并占用一次引用, 没有时返回空对象名
+func (s StorageService) acquire(folder, sum string) (string, error) {
+	var obj entity.StorageObject
+	if err := s.storageObjectRepo.AcquireStorageObject(&obj, folder, sum); err != nil {
+		return "", fmt.Errorf("acquire object: %w", err)
+	}
+	if obj.ObjectKey == "" {
+		return "", nil
+	}
+	// 记录还在而对象已不在(删除对象后记录未能删除)时, 丢弃记录重新上传
+	if !s.exists(obj.ObjectKey) {
+		if err := s.storageObjectRepo.DeleteStorageObject(obj.ObjectKey); err != nil {
+			return "", fmt.Errorf("delete stale object record: %w", err)
+		}
+		return "", nil
+	}
+	return obj.ObjectKey, nil
+}
+
+// register 登记新上传的对象, 引用计数为 1. 并发上传相同内容时以先登记的为准,
+// 删除自己上传的、与其不同名的对象后改为引用它
+func (s StorageService) register(obj entity.StorageObject, objects []upload.Object) (string, error) {
+	renditions, ext := renditionsOf(objects)
+	obj.Renditions = &renditions
+	obj.RenditionExt = ext
+	obj.RefCount = 1
+	obj.CreateTime = time.Now()
+	obj.UpdateTime = obj.CreateTime
+	added, err := s.storageObjectRepo.AddStorageObject(&obj)
+	if err == nil && added {
+		return obj.ObjectKey, nil
+	}
+	objectName := ""
+	if err == nil {
+		objectName, err = s.acquire(obj.Folder, obj.Sha256)
+	}
+	keep := make(map[string]bool)
+	keys, keysErr := s.objectKeys(objectName)
+	if keysErr != nil {
+		// 无法确认已有对象的缩略图时只删除自己上传的原图, 同名缩略图保留
+		keys = append(keys, objectName)
+		for _, o := range objects[1:] {
+			keys = append(keys, o.Key)
+		}
+	}
+	for _, key := range keys {
+		keep[key] = true
+	}
+	for _, o := range objects {
+		if !keep[o.Key] {
+			_ = s.store.Remove(s.ctx, o.Key)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("register object: %w", err)
+	}
+	if objectName == "" {
+		return "", errors.New("register object: conflicting record disappeared")
+	}
+	return objectName, nil
+}
+
+func (s StorageService) exists(objectName string) bool {
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+	obj, _, err := s.store.Get(ctx, objectName)
+	if err != nil {
+		// 无法确认时按存在处理, 宁可保留记录
+		return !errors.Is(err, storage.ErrNotFound)
+	}
+	obj.Close()
+	return true
+}
+
+// objectKeys 对象连同其缩略图的对象名; 缩略图按存储中实际存在的对象列出, 与当前配置的宽度无关
+func (s StorageService) objectKeys(objectName string) ([]string, error) {
+	if objectName == "" {
+		return nil, nil
+	}
+	keys := []string{objectName}
+	prefix, ok := upload.ThumbnailPrefix(objectName)
+	if !ok {
+		return keys, nil
+	}
+	thumbnails, err := s.ListObjects(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list thumbnails: %w", err)
+	}
+	for _, thumbnail := range thumbnails {
+		keys = append(keys, thumbnail.Key)
+	}
+	return keys, nil
+}
+
+// renditionsOf 上传的对象中缩略图的宽度(逗号分隔)与扩展名
+func renditionsOf(objects []upload.Object) (string, string) {
+	var widths []string
+	ext := ""
+	for _, o := range objects {
+		if o.Width > 0 {
+			widths = append(widths, strconv.Itoa(o.Width))
+			ext = path.Ext(o.Key)
+		}
+	}
+	return strings.Join(widths, ","), ext
+}
+
+// hashContent 计算内容的 SHA-256 后回到开头
+func hashContent(src io.ReadSeeker) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, src); err != nil {
+		return "", fmt.Errorf("hash content: %w", err)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("hash content: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ObjectURL 对象的公开访问 URL
+func (s StorageService) ObjectURL(objectName string) string {
+	return s.store.URL(objectName)
+}
+
+// NewMultipartUpload 发起分片上传, 返回存储的 uploadId
+func (s StorageService) NewMultipartUpload(objectName string) (string, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+	return s.store.NewMultipartUpload(ctx, objectName)
+}
+
+// PutPart 上传一个分片, 同时让存储按 sha256Hex 校验内容
+func (s StorageService) PutPart(objectName, uploadId string, partNumber int, data []byte, sha256Hex string) (storage.Part, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, upload.Timeout(int64(len(data))))
+	defer cancel()
+	return s.store.PutPart(ctx, objectName, uploadId, partNumber, bytes.NewReader(data), int64(len(data)), sha256Hex)
+}
+
+// ListParts 列出已上传的分片
+func (s StorageService) ListParts(objectName, uploadId string) ([]storage.Part, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+	return s.store.ListParts(ctx, objectName, uploadId)
+}
+
+func (s StorageService) CompleteMultipartUpload(objectName, uploadId string, parts []storage.Part) error {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+	defer cancel()
+	return s.store.CompleteMultipartUpload(ctx, objectName, uploadId, parts)
+}
+
+func (s StorageService) AbortMultipartUpload(objectName, uploadId string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+	return s.store.AbortMultipartUpload(ctx, objectName, uploadId)
+}
+
+// ListIncompleteUploads 列出 prefix 下未完成的分片上传
+func (s StorageService) ListIncompleteUploads(prefix string) ([]storage.MultipartUpload, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+	defer cancel()
+	return s.store.ListMultipartUploads(ctx, prefix)
+}
+
+// ListObjects 列出 prefix 下的对象
+func (s StorageService) ListObjects(prefix string) ([]storage.ObjectInfo, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+	defer cancel()
+	return s.store.List(ctx, prefix)
+}
+
+// GetObject 打开对象用于读取, 调用方负责关闭
+func (s StorageService) GetObject(objectName string) (storage.Object, storage.ObjectInfo, error) {
+	return s.store.Get(s.ctx, objectName)
+}
+
+// PutObject 按给定对象名直接写入, 不校验上传策略也不记录引用, 用于从备份恢复
+func (s StorageService) PutObject(objectName string, r io.Reader, size int64, contentType string) error {
+	return s.store.Put(s.ctx, objectName, r, size, contentType)
+}
+
+func (s StorageService) RemoveObject(objectName string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+	return s.store.Remove(ctx, objectName)
+}
+
+// PromoteObject 按 folder 的上传策略校验已上传完的临时对象, 通过后转为按内容寻址的正式对象并删除临时对象, 返回访问 URL
+func (s StorageService) PromoteObject(tempObject, folder, filename string) (string, error) {
+	policy, ok := upload.PolicyFor(folder)
+	if !ok {
+		return "", &upload.RejectError{Msg: consts.UnknownFolder}
+	}
+	obj, info, err := s.store.Get(s.ctx, tempObject)
+	if err != nil {
+		return "", fmt.Errorf("get object: %w", err)
+	}
+	defer obj.Close()
+	f, err := policy.Check(obj, info.Size)
+	if err != nil {
+		return "", err
+	}
+	sum, err := hashContent(obj)
+	if err != nil {
+		return "", err
+	}
+	objectName, err := s.acquire(policy.Name, sum)
+	if err != nil {
+		return "", err
+	}
+
+	if objectName == "" {
+		objectName = path.Join(policy.Name, sum, upload.SanitizeFilename(filename, f.Ext))
+		ctx, cancel := context.WithTimeout(s.ctx, upload.Timeout(info.Size))
+		defer cancel()
+		if err = s.store.Copy(ctx, tempObject, objectName, f.ContentType); err != nil {
+			return "", fmt.Errorf("copy object: %w", err)
+		}
+		objectName, err = s.register(entity.StorageObject{
+			ObjectKey: objectName,
+			Folder:    policy.Name,
+			Sha256:    sum,
+			Size:      info.Size,
+		}, []upload.Object{{Key: objectName}})
+		if err != nil {
+			return "", err
+		}
+	}
+	if err = s.RemoveObject(tempObject); err != nil {
+		log.Printf("StorageService.PromoteObject err: %v\n", err)
+	}
+	return s.ObjectURL(objectName), nil
+}
+
+// OpenFile 打开已上传的文件用于随机读取, 调用方负责关闭
+func (s StorageService) OpenFile(fileURL string) (storage.Object, int64, error) {
+	objectName, err := s.ObjectName(fileURL)
+	if err != nil {
+		return nil, 0, err
+	}
+	obj, info, err := s.store.Get(s.ctx, objectName)
+	if err != nil {
+		return nil, 0, err
+	}
+	return obj, info.Size, nil
+}
+
+// PresignedURL 生成带签名、限时有效的下载地址
+func (s StorageService) PresignedURL(objectName string, expiry time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+	defer cancel()
+	return s.store.PresignedURL(ctx, objectName, expiry)
+}
+
+// DeleteFile 释放 UploadFile 得到的文件的一次引用, 没有其他引用时才删除
+func (s StorageService) DeleteFile(fileURL string) error {
+	objectName, err := s.ObjectName(fileURL)
+	if err != nil {
+		return err
+	}
+	return s.ReleaseObject(objectName)
+}
+
+// ReleaseObject 释放对象的一次引用, 引用计数归零时删除对象及其缩略图.
+// 按内容寻址之前上传的对象没有记录, 可能被多行共用(如迁移时专辑沿用歌曲封面、从备份恢复), 数据库中已无引用时才删除;
+// 调用方须先去掉自己的引用, 否则对象保留, 由存储对账清理
+func (s StorageService) ReleaseObject(objectName string) error {
+	remove := func() error {
+		keys, err := s.objectKeys(objectName)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, 5*time.Second)
+		defer cancel()
+		for _, key := range keys {
+			if err := s.store.Remove(ctx, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	found, err := s.storageObjectRepo.ReleaseStorageObject(objectName, remove)
+	if err != nil || found {
+		return err
+	}
+	var refs int64
+	if err := s.storageObjectRepo.CountLiveRefs(&refs, objectName); err != nil {
+		return err
+	}
+	if refs > 0 {
+		return nil
+	}
+	return remove()
+}
+
+// DeleteFileAfterCommit 在工作单元提交后释放文件, 事务回滚时文件仍被引用而不释放; 释放失败只留下孤儿对象, 由存储对账清理
+func (s StorageService) DeleteFileAfterCommit(uow *db.UnitOfWork, fileURL string) {
+	if fileURL == "" {
+		return
+	}
+	uow.AfterCommit(func() {
+		if err := s.DeleteFile(fileURL); err != nil {
+			log.Printf("StorageService.DeleteFileAfterCommit err: %v\n", err)
+		}
+	})
+}
+
+// ReleaseObjectAfterCommit 同 DeleteFileAfterCommit, 参数为对象名
+func (s StorageService) ReleaseObjectAfterCommit(uow *db.UnitOfWork, objectName string) {
+	uow.AfterCommit(func() {
+		if err := s.ReleaseObject(objectName); err != nil {
+			log.Printf("StorageService.ReleaseObjectAfterCommit err: %v\n", err)
+		}
+	})
+}
+
+// DeleteFileOnRollback 事务回滚时释放为本次操作新上传的文件, 避免数据库未写入而文件残留
+func (s StorageService) DeleteFileOnRollback(uow *db.UnitOfWork, fileURL string) {
+	if fileURL == "" {
+		return
+	}
+	uow.OnRollback(func() {
+		if err := s.DeleteFile(fileURL); err != nil {
+			log.Printf("StorageService.DeleteFileOnRollback err: %v\n", err)
+		}
+	})
+}
+
+// ObjectName 从访问 URL 中解析出对象名
+func (s StorageService) ObjectName(fileURL string) (string, error) {
+	return s.store.Key(fileURL)
+}
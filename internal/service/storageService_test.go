@@ -0,0 +1,239 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/storage"
+	"vibe-music-server/internal/repo"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 测试库只建与存储对象相关的表与列
+var testSchema = []string{
+	"CREATE TABLE tb_storage_object (object_key TEXT PRIMARY KEY, folder TEXT NOT NULL, sha256 TEXT NOT NULL, size INTEGER NOT NULL," +
+		" renditions TEXT NULL, rendition_ext TEXT NOT NULL DEFAULT '', ref_count INTEGER NOT NULL, create_time DATETIME NOT NULL, update_time DATETIME NOT NULL)",
+	"CREATE UNIQUE INDEX uk_storage_object_sha256 ON tb_storage_object (folder, sha256)",
+	"CREATE TABLE tb_song (id INTEGER PRIMARY KEY, cover_url TEXT, audio_url TEXT)",
+	"CREATE TABLE tb_artist (id INTEGER PRIMARY KEY, avatar TEXT)",
+	"CREATE TABLE tb_album (id INTEGER PRIMARY KEY, cover_url TEXT)",
+	"CREATE TABLE tb_playlist (id INTEGER PRIMARY KEY, cover_url TEXT)",
+	"CREATE TABLE tb_user (id INTEGER PRIMARY KEY, user_avatar TEXT)",
+	"CREATE TABLE tb_banner (id INTEGER PRIMARY KEY, banner_url TEXT)",
+	"CREATE TABLE tb_song_audio_meta (song_id INTEGER PRIMARY KEY, cover_url TEXT)",
+	"CREATE TABLE tb_song_rendition (id INTEGER PRIMARY KEY, object_key TEXT)",
+}
+
+// newTestStorageService 内存存储加上每个测试独立的 SQLite 内存库
+func newTestStorageService(t *testing.T) (*StorageService, *storage.Memory) {
+	t.Helper()
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	conn, err := gorm.Open(sqlite.Open("file:"+name+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 共享缓存的内存库在最后一个连接关闭时销毁, 单连接也避免了 SQLite 的写锁冲突
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	for _, statement := range testSchema {
+		if err := conn.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Use(conn)
+	store := storage.NewMemory()
+	return NewStorageService(store, repo.NewStorageObjectRepo()), store
+}
+
+func testImage(t *testing.T, seed uint8) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 320, 240))
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 320; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: seed, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func storageObject(t *testing.T, objectKey string) (entity.StorageObject, bool) {
+	t.Helper()
+	var objs []entity.StorageObject
+	if err := db.Get().Where("object_key = ?", objectKey).Find(&objs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) == 0 {
+		return entity.StorageObject{}, false
+	}
+	return objs[0], true
+}
+
+func objectCount(t *testing.T, s *StorageService, prefix string) int {
+	t.Helper()
+	objects, err := s.ListObjects(prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(objects)
+}
+
+func TestStorageServiceDedup(t *testing.T) {
+	s, _ := newTestStorageService(t)
+	images := map[uint8][]byte{1: testImage(t, 1), 2: testImage(t, 2)}
+	uploads := []struct {
+		name     string
+		folder   string
+		filename string
+		seed     uint8
+		// 与第几次上传得到同一对象, -1 表示新对象
+		sameAs int
+	}{
+		{"first upload", "albumCovers", "album.png", 1, -1},
+		// songCovers 与 albumCovers 同属 covers 策略, 相同内容只存一份
+		{"same content in the same policy", "songCovers", "other name.png", 1, 0},
+		{"same content in another policy", "artists", "album.png", 1, -1},
+		{"other content", "albumCovers", "album.png", 2, -1},
+	}
+	urls := make([]string, len(uploads))
+	for i, u := range uploads {
+		fileURL, err := s.UploadBytes(images[u.seed], u.folder, u.filename)
+		if err != nil {
+			t.Fatalf("%s: upload: %v", u.name, err)
+		}
+		urls[i] = fileURL
+		if u.sameAs >= 0 {
+			if fileURL != urls[u.sameAs] {
+				t.Errorf("%s: url = %s, want %s", u.name, fileURL, urls[u.sameAs])
+			}
+		} else if slices.Contains(urls[:i], fileURL) {
+			t.Errorf("%s: shares an object with an earlier upload: %s", u.name, fileURL)
+		}
+	}
+	albumCover, songCover := urls[0], urls[1]
+
+	key, err := s.ObjectName(albumCover)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, ok := storageObject(t, key)
+	if !ok || obj.RefCount != 2 || obj.Folder != "covers" {
+		t.Fatalf("record = %+v, %v", obj, ok)
+	}
+	if obj.Renditions == nil || *obj.Renditions != "64,200,600,1200" {
+		t.Errorf("renditions = %v", obj.Renditions)
+	}
+	prefix := key[:strings.LastIndexByte(key, '/')+1]
+	// 原图加四个缩略图
+	if n := objectCount(t, s, prefix); n != 5 {
+		t.Errorf("objects = %d, want 5", n)
+	}
+
+	// 引用计数归零前对象保留, 归零后连同缩略图删除
+	if err := s.DeleteFile(albumCover); err != nil {
+		t.Fatal(err)
+	}
+	if obj, _ := storageObject(t, key); obj.RefCount != 1 {
+		t.Errorf("ref count after one release = %d, want 1", obj.RefCount)
+	}
+	if n := objectCount(t, s, prefix); n != 5 {
+		t.Errorf("objects after one release = %d, want 5", n)
+	}
+	if err := s.DeleteFile(songCover); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := storageObject(t, key); ok {
+		t.Error("record kept after last release")
+	}
+	if n := objectCount(t, s, prefix); n != 0 {
+		t.Errorf("objects after last release = %d, want 0", n)
+	}
+	if n := objectCount(t, s, "artists/"); n != 5 {
+		t.Errorf("artist objects = %d, want 5", n)
+	}
+
+	// 删除后再次上传重新存储
+	again, err := s.UploadBytes(images[1], "albumCovers", "album.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj, ok := storageObject(t, key); again != albumCover || !ok || obj.RefCount != 1 {
+		t.Errorf("re-upload = %s, record %+v", again, obj)
+	}
+}
+
+func TestStorageServiceStaleRecord(t *testing.T) {
+	s, store := newTestStorageService(t)
+	img := testImage(t, 3)
+	fileURL, err := s.UploadBytes(img, "users", "me.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := s.ObjectName(fileURL)
+	// 对象已被删除而记录还在时, 下次上传丢弃记录重新存储
+	if err := store.Remove(t.Context(), key); err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.UploadBytes(img, "users", "me.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != fileURL || !s.exists(key) {
+		t.Errorf("object not restored: %s", again)
+	}
+	if obj, _ := storageObject(t, key); obj.RefCount != 1 {
+		t.Errorf("ref count = %d, want 1", obj.RefCount)
+	}
+}
+
+func TestReleaseUnrecordedObject(t *testing.T) {
+	s, _ := newTestStorageService(t)
+	// 按内容寻址之前上传的对象: uuid 目录, 没有记录
+	dir := "covers/0b6a1b8e-2f6c-4f5e-9f3e-3c1f4b1a2d7e/"
+	for _, name := range []string{"cover.jpg", "-64.jpg", "-200.jpg"} {
+		if err := s.PutObject(dir+name, bytes.NewReader([]byte(name)), int64(len(name)), "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := dir + "cover.jpg"
+	// 两首歌共用同一封面, 释放其中一首的引用时对象保留
+	for id := 1; id <= 2; id++ {
+		if err := db.Get().Exec("INSERT INTO tb_song (id, cover_url) VALUES (?, ?)", id, s.ObjectURL(key)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Get().Exec("DELETE FROM tb_song WHERE id = 1").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReleaseObject(key); err != nil {
+		t.Fatal(err)
+	}
+	if n := objectCount(t, s, dir); n != 3 {
+		t.Fatalf("objects = %d, want 3 while still referenced", n)
+	}
+	if err := db.Get().Exec("DELETE FROM tb_song WHERE id = 2").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReleaseObject(key); err != nil {
+		t.Fatal(err)
+	}
+	if n := objectCount(t, s, dir); n != 0 {
+		t.Errorf("objects = %d, want 0 after last reference", n)
+	}
+}
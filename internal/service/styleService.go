@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/util"
+	"vibe-music-server/internal/repo"
+)
+
+type StyleService struct {
+	styleRepo *repo.StyleRepo
+}
+
+func NewStyleService(styleRepo *repo.StyleRepo) *StyleService {
+	return &StyleService{
+		styleRepo: styleRepo,
+	}
+}
+
+func (s StyleService) GetStyleList() result.Result[[]vo.StyleVO] {
+	retErr := result.Error[[]vo.StyleVO]
+	retSuc := result.SuccessWithData[[]vo.StyleVO]
+	var data []vo.StyleVO
+	templateKey := "style:getStyleList"
+	if util.GetCache(templateKey, &data) {
+		return retSuc(consts.Success, data)
+	}
+	if err := s.styleRepo.GetStyleList(&data); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if data == nil {
+		data = []vo.StyleVO{}
+	}
+	util.SetCache(templateKey, data)
+	return retSuc(consts.Success, data)
+}
+
+func (s StyleService) AddStyle(styleAddDTO *dto.StyleAddDTO) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	name := strings.TrimSpace(styleAddDTO.Name)
+	if name == "" || strings.ContainsAny(name, ",，、") {
+		return retErr(consts.Style + consts.FormatError)
+	}
+	if s.styleRepo.ExistStyleByName(name, 0) {
+		return retErr(consts.Style + consts.AlreadyExists)
+	}
+	if err := s.styleRepo.CreateStyle(&entity.Style{Name: name}); err != nil {
+		return retErr(consts.Add + consts.Failed)
+	}
+	util.DeleteCacheByPattern("style:*")
+	return retSuc(consts.Add + consts.Success)
+}
+
+func (s StyleService) UpdateStyle(styleUpdateDTO *dto.StyleUpdateDTO) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	name := strings.TrimSpace(styleUpdateDTO.Name)
+	if name == "" || strings.ContainsAny(name, ",，、") {
+		return retErr(consts.Style + consts.FormatError)
+	}
+	var style entity.Style
+	if err := s.styleRepo.GetStyleById(&style, styleUpdateDTO.StyleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
+	}
+	if s.styleRepo.ExistStyleByName(name, uint64(style.ID)) {
+		return retErr(consts.Style + consts.AlreadyExists)
+	}
+	if err := s.styleRepo.RenameStyle(&style, name); err != nil {
+		return retErr(consts.Update + consts.Failed)
+	}
+	s.clearCache()
+	return retSuc(consts.Update + consts.Success)
+}
+
+func (s StyleService) MergeStyles(styleMergeDTO *dto.StyleMergeDTO) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	if slices.Contains(styleMergeDTO.SourceIDs, styleMergeDTO.TargetID) {
+		return retErr(consts.InvalidParams)
+	}
+	var target entity.Style
+	if err := s.styleRepo.GetStyleById(&target, styleMergeDTO.TargetID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.Style + consts.NotExist)
+		}
+		return retErr(consts.InternalError)
+	}
+	var sources []entity.Style
+	if err := s.styleRepo.GetStylesByIds(&sources, styleMergeDTO.SourceIDs); err != nil {
+		return retErr(consts.InternalError)
+	}
+	if len(sources) == 0 {
+		return retErr(consts.DataNotFound)
+	}
+	if err := s.styleRepo.MergeStyles(sources, &target); err != nil {
+		return retErr(consts.Update + consts.Failed)
+	}
+	s.clearCache()
+	return retSuc(consts.Update + consts.Success)
+}
+
+// DeleteStyle reassignTo 可为nil, 为nil时仅解除歌曲关联
+func (s StyleService) DeleteStyle(styleId uint64, reassignTo *uint64) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	retSuc := result.Success[result.Nil]
+	var style entity.Style
+	if err := s.styleRepo.GetStyleById(&style, styleId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
+	}
+	var target *entity.Style
+	if reassignTo != nil {
+		if *reassignTo == styleId {
+			return retErr(consts.InvalidParams)
+		}
+		target = &entity.Style{}
+		if err := s.styleRepo.GetStyleById(target, *reassignTo); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return retErr(consts.Style + consts.NotExist)
+			}
+			return retErr(consts.InternalError)
+		}
+	}
+	if err := s.styleRepo.DeleteStyle(&style, target); err != nil {
+		return retErr(consts.Delete + consts.Failed)
+	}
+	s.clearCache()
+	return retSuc(consts.Delete + consts.Success)
+}
+
+// clearCache 风格变更会影响歌曲与歌单中的冗余风格名
+func (s StyleService) clearCache() {
+	util.DeleteCacheByPattern("style:*")
+	util.DeleteCacheByPattern("song:*")
+	util.DeleteCacheByPattern("playlist:*")
+}
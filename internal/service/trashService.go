@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+	"vibe-music-server/internal/config"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/db"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/repo"
+)
+
+// trashSources 回收站中的各类型; 自动清除按此顺序进行, 歌曲先于歌手清除, 否则歌手会被其歌曲阻止删除
+var trashSources = []repo.TrashSource{
+	{Type: "song", Table: "tb_song", Title: "name", Cover: "cover_url"},
+	{Type: "playlist", Table: "tb_playlist", Title: "title", Cover: "cover_url"},
+	{Type: "user", Table: "tb_user", Title: "username", Cover: "user_avatar"},
+	{Type: "banner", Table: "tb_banner", Cover: "banner_url"},
+	{Type: "artist", Table: "tb_artist", Title: "name", Cover: "avatar"},
+}
+
+func trashSource(t string) (repo.TrashSource, bool) {
+	for _, src := range trashSources {
+		if src.Type == t {
+			return src, true
+		}
+	}
+	return repo.TrashSource{}, false
+}
+
+// TrashService 管理端回收站: 列出、恢复与清除软删除的行, 并按保留期定期清除
+type TrashService struct {
+	trashRepo       *repo.TrashRepo
+	deletionService *DeletionService
+}
+
+func NewTrashService(trashRepo *repo.TrashRepo, deletionService *DeletionService) *TrashService {
+	return &TrashService{
+		trashRepo:       trashRepo,
+		deletionService: deletionService,
+	}
+}
+
+func (t TrashService) GetTrash(trashDTO *dto.TrashDTO) result.Result[result.PageResult[vo.TrashItemVO]] {
+	sources := trashSources
+	if trashDTO.Type != "" {
+		src, _ := trashSource(trashDTO.Type)
+		sources = []repo.TrashSource{src}
+	}
+	data := result.PageResult[vo.TrashItemVO]{Items: []vo.TrashItemVO{}}
+	startIndex := (trashDTO.PageNum - 1) * trashDTO.PageSize
+	if err := t.trashRepo.GetTrashItems(&data, sources, startIndex, trashDTO.PageSize); err != nil {
+		log.Printf("TrashService.GetTrash err: %v\n", err)
+		return result.Error[result.PageResult[vo.TrashItemVO]](consts.InternalError)
+	}
+	if days := config.Get().Trash.RetentionDays; days > 0 {
+		for i := range data.Items {
+			purgeAt := data.Items[i].DeletedAt.AddDate(0, 0, days)
+			data.Items[i].PurgeAt = &purgeAt
+		}
+	}
+	return result.SuccessWithData(consts.Success, data)
+}
+
+// Restore 恢复回收站中的行; 歌曲所属歌手仍在回收站中时需先恢复歌手
+func (t TrashService) Restore(itemsDTO *dto.TrashItemsDTO) result.Result[result.Nil] {
+	src, _ := trashSource(itemsDTO.Type)
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		return t.deletionService.Restore(uow, src.Table, itemsDTO.IDs)
+	})
+	if err != nil {
+		log.Printf("TrashService.Restore err: %v\n", err)
+		if errors.Is(err, errRestoreBlocked) {
+			return result.Error[result.Nil](consts.RestoreBlocked)
+		}
+		return result.Error[result.Nil](consts.Restore + consts.Failed)
+	}
+	return result.Success[result.Nil](consts.Restore + consts.Success)
+}
+
+// Purge 按删除策略彻底删除回收站中的行, 文件在提交后释放; 不在回收站中的 ids 被忽略
+func (t TrashService) Purge(itemsDTO *dto.TrashItemsDTO) result.Result[result.Nil] {
+	src, _ := trashSource(itemsDTO.Type)
+	var ids []uint64
+	if err := t.trashRepo.GetTrashedIds(&ids, src.Table, itemsDTO.IDs); err != nil {
+		log.Printf("TrashService.Purge err: %v\n", err)
+		return result.Error[result.Nil](consts.InternalError)
+	}
+	if len(ids) == 0 {
+		return result.Error[result.Nil](consts.DataNotFound)
+	}
+	if err := t.purge(src.Table, ids); err != nil {
+		log.Printf("TrashService.Purge err: %v\n", err)
+		return result.Error[result.Nil](deleteFailedMessage(err))
+	}
+	return result.Success[result.Nil](consts.Delete + consts.Success)
+}
+
+// Run 每隔 interval 清除移入回收站超过 trash.retention-days 天的行, 保留天数为 0 时不执行
+func (t TrashService) Run(interval time.Duration) {
+	days := config.Get().Trash.RetentionDays
+	if days <= 0 {
+		return
+	}
+	t.purgeExpired(time.Now().AddDate(0, 0, -days))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		t.purgeExpired(time.Now().AddDate(0, 0, -days))
+	}
+}
+
+func (t TrashService) purgeExpired(before time.Time) {
+	for _, src := range trashSources {
+		var ids []uint64
+		if err := t.trashRepo.GetExpiredIds(&ids, src.Table, before); err != nil {
+			log.Printf("TrashService.purgeExpired err: %v\n", err)
+			continue
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if err := t.purge(src.Table, ids); err != nil {
+			log.Printf("TrashService.purgeExpired %s err: %v\n", src.Type, err)
+			continue
+		}
+		log.Printf("trash: purged %d %s\n", len(ids), src.Type)
+	}
+}
+
+func (t TrashService) purge(table string, ids []uint64) error {
+	return db.Transaction(func(uow *db.UnitOfWork) error {
+		return t.deletionService.Delete(uow, table, ids)
+	})
+}
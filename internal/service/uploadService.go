@@ -0,0 +1,301 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"path"
+	"strings"
+	"time"
+	"vibe-music-server/internal/model/dto"
+	"vibe-music-server/internal/model/entity"
+	"vibe-music-server/internal/model/vo"
+	"vibe-music-server/internal/pkg/result"
+	"vibe-music-server/internal/pkg/result/consts"
+	"vibe-music-server/internal/pkg/storage"
+	"vibe-music-server/internal/pkg/upload"
+	"vibe-music-server/internal/repo"
+)
+
+const (
+	uploadFolder     = "songs"
+	uploadTempPrefix = "uploads/" // 分片上传的临时对象目录
+	defaultChunkSize = 8 << 20
+	MaxChunkSize     = 64 << 20
+	maxUploadParts   = 10000 // S3 分片数上限
+	uploadSessionTTL = 24 * time.Hour
+)
+
+// UploadService 大文件分片上传: 发起 -> 逐片上传(可断点续传) -> 完成后进入歌曲音频更新流程
+type UploadService struct {
+	uploadSessionRepo *repo.UploadSessionRepo
+	songRepo          *repo.SongRepo
+	storageService    *StorageService
+	songService       *SongService
+}
+
+func NewUploadService(uploadSessionRepo *repo.UploadSessionRepo, songRepo *repo.SongRepo, storageService *StorageService, songService *SongService) *UploadService {
+	return &UploadService{
+		uploadSessionRepo: uploadSessionRepo,
+		songRepo:          songRepo,
+		storageService:    storageService,
+		songService:       songService,
+	}
+}
+
+func (u UploadService) InitSongAudioUpload(uploadDTO *dto.SongAudioUploadDTO) result.Result[vo.UploadSessionVO] {
+	retErr := result.Error[vo.UploadSessionVO]
+	var song entity.Song
+	if err := u.songRepo.GetSongById(&song, uploadDTO.SongID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.DataNotFound)
+		}
+		return retErr(consts.InternalError)
+	}
+	policy, _ := upload.PolicyFor(uploadFolder)
+	if uploadDTO.Size > policy.MaxSize {
+		return retErr(consts.FileTooLarge)
+	}
+	chunkSize := uploadDTO.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultChunkSize
+	}
+	session := entity.UploadSession{
+		ID:         uuid.NewString(),
+		SongID:     uploadDTO.SongID,
+		Filename:   uploadDTO.Filename,
+		Size:       uploadDTO.Size,
+		ChunkSize:  chunkSize,
+		CreateTime: time.Now(),
+		UpdateTime: time.Now(),
+	}
+	if session.TotalParts() > maxUploadParts {
+		return retErr(consts.ChunkSizeError)
+	}
+	session.ObjectKey = uploadTempPrefix + session.ID
+	uploadId, err := u.storageService.NewMultipartUpload(session.ObjectKey)
+	if err != nil {
+		log.Printf("UploadService.InitSongAudioUpload err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	session.UploadID = uploadId
+	if err = u.uploadSessionRepo.AddUploadSession(&session); err != nil {
+		_ = u.storageService.AbortMultipartUpload(session.ObjectKey, uploadId)
+		return retErr(consts.InternalError)
+	}
+	return result.SuccessWithData(consts.Success, toUploadSessionVO(session, nil))
+}
+
+// UploadPart 上传第 partNumber 片(从 1 开始), sha256Hex 为该片内容的 SHA-256; 重复上传同一片会覆盖
+func (u UploadService) UploadPart(uploadId string, partNumber int, data []byte, sha256Hex string) result.Result[vo.UploadPartVO] {
+	retErr := result.Error[vo.UploadPartVO]
+	session, msg := u.getSession(uploadId)
+	if msg != "" {
+		return retErr(msg)
+	}
+	if partNumber < 1 || partNumber > session.TotalParts() || int64(len(data)) != session.PartSize(partNumber) {
+		return retErr(consts.ChunkSizeError)
+	}
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), sha256Hex) {
+		return retErr(consts.ChecksumMismatch)
+	}
+	// 第一片包含文件头, 提前按上传策略识别类型, 避免传完才发现格式不对
+	if partNumber == 1 {
+		policy, _ := upload.PolicyFor(uploadFolder)
+		if _, err := policy.Check(bytes.NewReader(data), int64(len(data))); err != nil {
+			var rejectErr *upload.RejectError
+			if errors.As(err, &rejectErr) {
+				return retErr(rejectErr.Msg)
+			}
+			return retErr(consts.InternalError)
+		}
+	}
+	part, err := u.storageService.PutPart(session.ObjectKey, session.UploadID, partNumber, data, hex.EncodeToString(sum[:]))
+	if err != nil {
+		log.Printf("UploadService.UploadPart err: %v\n", err)
+		return retErr(consts.FileUpload + consts.Failed)
+	}
+	if err = u.uploadSessionRepo.TouchUploadSession(session.ID); err != nil {
+		log.Printf("UploadService.UploadPart err: %v\n", err)
+	}
+	return result.SuccessWithData(consts.Success, vo.UploadPartVO{
+		PartNumber: partNumber,
+		Size:       int64(len(data)),
+		ETag:       part.ETag,
+	})
+}
+
+// GetUpload 查询上传进度, 断开后据此补传缺失的分片
+func (u UploadService) GetUpload(uploadId string) result.Result[vo.UploadSessionVO] {
+	retErr := result.Error[vo.UploadSessionVO]
+	session, msg := u.getSession(uploadId)
+	if msg != "" {
+		return retErr(msg)
+	}
+	parts, err := u.storageService.ListParts(session.ObjectKey, session.UploadID)
+	if err != nil {
+		log.Printf("UploadService.GetUpload err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	return result.SuccessWithData(consts.Success, toUploadSessionVO(session, parts))
+}
+
+// CompleteSongAudioUpload 合并分片, 校验后替换歌曲音频并提取元数据, 与表单上传走同一流程
+func (u UploadService) CompleteSongAudioUpload(uploadId string) result.Result[vo.SongAudioMetaVO] {
+	retErr := result.Error[vo.SongAudioMetaVO]
+	session, msg := u.getSession(uploadId)
+	if msg != "" {
+		return retErr(msg)
+	}
+	parts, err := u.storageService.ListParts(session.ObjectKey, session.UploadID)
+	if err != nil {
+		log.Printf("UploadService.CompleteSongAudioUpload err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	if len(parts) != session.TotalParts() {
+		return retErr(consts.UploadIncomplete)
+	}
+	for _, p := range parts {
+		if p.Size != session.PartSize(p.PartNumber) {
+			return retErr(consts.UploadIncomplete)
+		}
+	}
+	if err = u.storageService.CompleteMultipartUpload(session.ObjectKey, session.UploadID, parts); err != nil {
+		log.Printf("UploadService.CompleteSongAudioUpload err: %v\n", err)
+		return retErr(consts.FileUpload + consts.Failed)
+	}
+	// 分片已合并, 之后无论成败会话都不能再续传
+	if err = u.uploadSessionRepo.DeleteUploadSession(session.ID); err != nil {
+		log.Printf("UploadService.CompleteSongAudioUpload err: %v\n", err)
+	}
+	audioUrl, err := u.storageService.PromoteObject(session.ObjectKey, uploadFolder, session.Filename)
+	if err != nil {
+		_ = u.storageService.RemoveObject(session.ObjectKey)
+		var rejectErr *upload.RejectError
+		if errors.As(err, &rejectErr) {
+			return retErr(rejectErr.Msg)
+		}
+		log.Printf("UploadService.CompleteSongAudioUpload err: %v\n", err)
+		return retErr(consts.FileUpload + consts.Failed)
+	}
+	audio, size, err := u.storageService.OpenFile(audioUrl)
+	if err != nil {
+		log.Printf("UploadService.CompleteSongAudioUpload err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	defer audio.Close()
+	return u.songService.UpdateSongAudio(session.SongID, audioUrl, audio, size)
+}
+
+func (u UploadService) AbortUpload(uploadId string) result.Result[result.Nil] {
+	retErr := result.Error[result.Nil]
+	session, msg := u.getSession(uploadId)
+	if msg != "" {
+		return retErr(msg)
+	}
+	if err := u.storageService.AbortMultipartUpload(session.ObjectKey, session.UploadID); err != nil && !isNoSuchUpload(err) {
+		log.Printf("UploadService.AbortUpload err: %v\n", err)
+		return retErr(consts.InternalError)
+	}
+	if err := u.uploadSessionRepo.DeleteUploadSession(session.ID); err != nil {
+		return retErr(consts.Delete + consts.Failed)
+	}
+	return result.Success[result.Nil](consts.Delete + consts.Success)
+}
+
+// Run 定期清理超过 uploadSessionTTL 未再上传分片的会话, 以及存储中无会话对应的临时分片和对象
+func (u UploadService) Run(interval time.Duration) {
+	u.cleanup()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		u.cleanup()
+	}
+}
+
+func (u UploadService) cleanup() {
+	before := time.Now().Add(-uploadSessionTTL)
+	var sessions []entity.UploadSession
+	if err := u.uploadSessionRepo.GetStaleUploadSessions(&sessions, before); err != nil {
+		log.Printf("UploadService.cleanup err: %v\n", err)
+		return
+	}
+	for _, session := range sessions {
+		if err := u.storageService.AbortMultipartUpload(session.ObjectKey, session.UploadID); err != nil && !isNoSuchUpload(err) {
+			log.Printf("UploadService.cleanup err: %v\n", err)
+			continue
+		}
+		if err := u.uploadSessionRepo.DeleteUploadSession(session.ID); err != nil {
+			log.Printf("UploadService.cleanup err: %v\n", err)
+		}
+	}
+
+	// 会话记录丢失或合并后未能转存时留下的分片与临时对象
+	uploads, err := u.storageService.ListIncompleteUploads(uploadTempPrefix)
+	if err != nil {
+		log.Printf("UploadService.cleanup err: %v\n", err)
+		return
+	}
+	for _, info := range uploads {
+		if info.Initiated.After(before) || u.sessionExists(info.Key) {
+			continue
+		}
+		if err = u.storageService.AbortMultipartUpload(info.Key, info.UploadID); err != nil {
+			log.Printf("UploadService.cleanup err: %v\n", err)
+		}
+	}
+	objects, err := u.storageService.ListObjects(uploadTempPrefix)
+	if err != nil {
+		log.Printf("UploadService.cleanup err: %v\n", err)
+		return
+	}
+	for _, obj := range objects {
+		if obj.LastModified.Before(before) {
+			if err = u.storageService.RemoveObject(obj.Key); err != nil {
+				log.Printf("UploadService.cleanup err: %v\n", err)
+			}
+		}
+	}
+}
+
+func (u UploadService) sessionExists(objectKey string) bool {
+	exists, err := u.uploadSessionRepo.UploadSessionExists(path.Base(objectKey))
+	// 查询失败时按存在处理, 宁可下次再清理
+	return err != nil || exists
+}
+
+func (u UploadService) getSession(uploadId string) (entity.UploadSession, string) {
+	var session entity.UploadSession
+	if err := u.uploadSessionRepo.GetUploadSession(&session, uploadId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return session, consts.DataNotFound
+		}
+		return session, consts.InternalError
+	}
+	return session, ""
+}
+
+func toUploadSessionVO(session entity.UploadSession, parts []storage.Part) vo.UploadSessionVO {
+	uploaded := make([]vo.UploadPartVO, 0, len(parts))
+	for _, p := range parts {
+		uploaded = append(uploaded, vo.UploadPartVO{PartNumber: p.PartNumber, Size: p.Size, ETag: p.ETag})
+	}
+	return vo.UploadSessionVO{
+		UploadID:      session.ID,
+		SongID:        session.SongID,
+		Filename:      session.Filename,
+		Size:          session.Size,
+		ChunkSize:     session.ChunkSize,
+		TotalParts:    session.TotalParts(),
+		UploadedParts: uploaded,
+	}
+}
+
+func isNoSuchUpload(err error) bool {
+	return errors.Is(err, storage.ErrNoSuchUpload)
+}
@@ -1,12 +1,16 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"gorm.io/gorm"
+	"log"
 	"time"
 	"vibe-music-server/internal/model/dto"
 	"vibe-music-server/internal/model/entity"
 	"vibe-music-server/internal/model/vo"
 	"vibe-music-server/internal/pkg/cache"
+	"vibe-music-server/internal/pkg/db"
 	"vibe-music-server/internal/pkg/result"
 	"vibe-music-server/internal/pkg/result/consts"
 	"vibe-music-server/internal/pkg/util"
@@ -14,16 +18,18 @@ import (
 )
 
 type UserService struct {
-	userRepo     *repo.UserRepo
-	emailService *EmailService
-	minioService *MinioService
+	userRepo        *repo.UserRepo
+	emailService    *EmailService
+	storageService  *StorageService
+	deletionService *DeletionService
 }
 
-func NewUserService(userRepo *repo.UserRepo, emailService *EmailService, minioService *MinioService) *UserService {
+func NewUserService(userRepo *repo.UserRepo, emailService *EmailService, storageService *StorageService, deletionService *DeletionService) *UserService {
 	return &UserService{
-		userRepo:     userRepo,
-		emailService: emailService,
-		minioService: minioService,
+		userRepo:        userRepo,
+		emailService:    emailService,
+		storageService:  storageService,
+		deletionService: deletionService,
 	}
 }
 
@@ -66,7 +72,7 @@ func (u UserService) Register(userRegisterDTO *dto.UserRegisterDTO) result.Resul
 		return retErr(consts.User + consts.AlreadyExists)
 	}
 	// 判断邮箱是否存在
-	if err := u.userRepo.GetUserByEmail(&user, userRegisterDTO.Email); err == nil {
+	if err := u.userRepo.GetUserByEmailWithTrashed(&user, userRegisterDTO.Email); err == nil {
 		return retErr(consts.Email + consts.AlreadyExists)
 	}
 	// 创建用户
@@ -141,6 +147,7 @@ func (u UserService) GetUserInfo(claims *util.Claims) result.Result[vo.UserVO] {
 		UserAvatar:   user.UserAvatar,
 		Introduction: user.Introduction,
 	}
+	u.storageService.FillSrcsets(&userVO)
 	return retSuc(consts.Success, userVO)
 }
 
@@ -156,7 +163,7 @@ func (u UserService) UpdateUserInfo(userDTO *dto.UserDTO) result.Result[result.N
 		return retErr(consts.Username + consts.AlreadyExists)
 	}
 	var userByEmail entity.User
-	if err := u.userRepo.GetUserByEmail(&userByEmail, userDTO.Email); err == nil && userByEmail.UserId != user.UserId {
+	if err := u.userRepo.GetUserByEmailWithTrashed(&userByEmail, userDTO.Email); err == nil && userByEmail.UserId != user.UserId {
 		return retErr(consts.Email + consts.AlreadyExists)
 	}
 	user.Username = userDTO.Username
@@ -184,7 +191,7 @@ func (u UserService) UpdateUserAvatar(avatarUrl string, claims *util.Claims) res
 	}
 	// 删除旧头像
 	if user.UserAvatar != "" {
-		err := u.minioService.DeleteFile(user.UserAvatar)
+		err := u.storageService.DeleteFile(user.UserAvatar)
 		if err != nil {
 			return retErr(consts.Delete + consts.Failed)
 		}
@@ -283,24 +290,23 @@ func (u UserService) DeleteAccount(claims *util.Claims, token string) result.Res
 	retSuc := result.Success[result.Nil]
 	var user entity.User
 	if err := u.userRepo.GetUserById(&user, claims.UserId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return retErr(consts.User + consts.NotExist)
+		}
 		return retErr(consts.InternalError)
 	}
-	if user.UserId == 0 {
-		return retErr(consts.User + consts.NotExist)
-	}
-	// 删除用户头像
-	if user.UserAvatar != "" {
-		err := u.minioService.DeleteFile(user.UserAvatar)
-		if err != nil {
-			return retErr(consts.Delete + consts.Failed)
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		if err := u.deletionService.Delete(uow, "tb_user", []uint64{claims.UserId}); err != nil {
+			return err
 		}
+		// 删除缓存中的 token
+		uow.AfterCommit(func() { _ = cache.Del(token) })
+		return nil
+	})
+	if err != nil {
+		log.Printf("UserService.DeleteAccount err: %v\n", err)
+		return retErr(deleteFailedMessage(err))
 	}
-	if err := u.userRepo.DeleteUser(claims.UserId); err != nil {
-		return retErr(consts.Delete + consts.Failed)
-	}
-	// 删除缓存中的 token
-	_ = cache.Del(token)
-	util.DeleteCacheByPattern("user:*")
 	return retSuc(consts.Delete + consts.Success)
 }
 
@@ -322,6 +328,7 @@ func (u UserService) GetAllUsers(userSearchDTO *dto.UserSearchDTO) result.Result
 		if err := u.userRepo.GetAllUsers(&data, userSearchDTO.Username, userSearchDTO.Phone, userSearchDTO.Status, startIndex, pageSize); err != nil {
 			return retErr(consts.InternalError)
 		}
+		u.storageService.FillSrcsets(&data)
 		util.SetCache(templateKey, data)
 	}
 	if len(data.Items) == 0 {
@@ -339,7 +346,7 @@ func (u UserService) AddUser(userAddDTO *dto.UserAddDTO) result.Result[result.Ni
 		return retErr(consts.Username + consts.AlreadyExists)
 	}
 	var userByEmail entity.User
-	if err := u.userRepo.GetUserByEmail(&userByEmail, userAddDTO.Email); err == nil {
+	if err := u.userRepo.GetUserByEmailWithTrashed(&userByEmail, userAddDTO.Email); err == nil {
 		return retErr(consts.Email + consts.AlreadyExists)
 	}
 	var userByPhone entity.User
@@ -382,7 +389,7 @@ func (u UserService) UpdateUser(userDTO *dto.UserDTO) result.Result[result.Nil]
 		return retErr(consts.Username + consts.AlreadyExists)
 	}
 	var userByEmail entity.User
-	if err := u.userRepo.GetUserByEmail(&userByEmail, userDTO.Email); err == nil && userByEmail.UserId != user.UserId {
+	if err := u.userRepo.GetUserByEmailWithTrashed(&userByEmail, userDTO.Email); err == nil && userByEmail.UserId != user.UserId {
 		return retErr(consts.Email + consts.AlreadyExists)
 	}
 	var userByPhone entity.User
@@ -418,48 +425,24 @@ func (u UserService) UpdateUserStatus(userId uint64, userStatus entity.UserStatu
 }
 
 func (u UserService) DeleteUser(userId uint64) result.Result[result.Nil] {
-	retErr := result.Error[result.Nil]
-	retSuc := result.Success[result.Nil]
 	var user entity.User
 	if err := u.userRepo.GetUserById(&user, userId); err != nil {
-		return retErr(consts.InternalError)
-	}
-	if user.UserId == 0 {
-		return retErr(consts.User + consts.NotExist)
-	}
-	// 删除用户头像
-	if user.UserAvatar != "" {
-		err := u.minioService.DeleteFile(user.UserAvatar)
-		if err != nil {
-			return retErr(consts.Delete + consts.Failed)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return result.Error[result.Nil](consts.User + consts.NotExist)
 		}
+		return result.Error[result.Nil](consts.InternalError)
 	}
-	if err := u.userRepo.DeleteUser(userId); err != nil {
-		return retErr(consts.Delete + consts.Failed)
-	}
-	util.DeleteCacheByPattern("user:*")
-	return retSuc(consts.Delete + consts.Success)
+	return u.DeleteUsers([]uint64{userId})
 }
 
+// DeleteUsers 将用户移入回收站, 用户无法再登录; 评论、收藏与头像在回收站中清除时才随之删除
 func (u UserService) DeleteUsers(userIds []uint64) result.Result[result.Nil] {
-	retErr := result.Error[result.Nil]
-	retSuc := result.Success[result.Nil]
-	var avatarUrls []string
-	if err := u.userRepo.GetAvatarByIds(&avatarUrls, userIds); err != nil {
-		return retErr(consts.InternalError)
-	}
-	// 删除用户头像
-	for _, avatarUrl := range avatarUrls {
-		if avatarUrl != "" {
-			err := u.minioService.DeleteFile(avatarUrl)
-			if err != nil {
-				return retErr(consts.Delete + consts.Failed)
-			}
-		}
-	}
-	if err := u.userRepo.DeleteUsers(userIds); err != nil {
-		return retErr(consts.Delete + consts.Failed)
+	err := db.Transaction(func(uow *db.UnitOfWork) error {
+		return u.deletionService.SoftDelete(uow, "tb_user", userIds)
+	})
+	if err != nil {
+		log.Printf("UserService.DeleteUsers err: %v\n", err)
+		return result.Error[result.Nil](deleteFailedMessage(err))
 	}
-	util.DeleteCacheByPattern("user:*")
-	return retSuc(consts.Delete + consts.Success)
+	return result.Success[result.Nil](consts.Delete + consts.Success)
 }
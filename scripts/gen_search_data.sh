@@ -0,0 +1,28 @@
+#!/usr/bin/env bash
+# 生成搜索用的拼音表与繁简对照表, 依赖 ICU 的 uconv
+# 用法: scripts/gen_search_data.sh
+set -euo pipefail
+
+out="$(cd "$(dirname "$0")/.." && pwd)/internal/pkg/search/data"
+tmp="$(mktemp -d)"
+trap 'rm -rf "$tmp"' EXIT
+
+# CJK 统一表意文字基本区 U+4E00..U+9FA5, 每行一个字
+for ((c = 0x4E00; c <= 0x9FA5; c++)); do
+  printf '\\u%04X\n' "$c"
+done | uconv -x 'Hex-Any' > "$tmp/han.txt"
+
+# 拼音: 每行 "音节 汉字..." ; 多音字只取 ICU 的默认读音
+uconv -x 'Han-Latin; Latin-ASCII; Lower' "$tmp/han.txt" > "$tmp/latin.txt"
+paste -d ' ' "$tmp/latin.txt" "$tmp/han.txt" |
+  grep -E '^[a-z]+ ' |
+  sort -s -k1,1 |
+  awk '$1 != prev { if (NR > 1) print line; line = $1 " "; prev = $1 } { line = line $2 } END { print line }' \
+  > "$out/pinyin.txt"
+
+# 繁简: 每行 "繁 简", 仅保留单字到单字且不同的映射
+uconv -x 'Traditional-Simplified' "$tmp/han.txt" > "$tmp/simplified.txt"
+paste -d ' ' "$tmp/han.txt" "$tmp/simplified.txt" |
+  awk '$1 != $2' |
+  LC_ALL=C.UTF-8 grep -E '^. .$' \
+  > "$out/t2s.txt"
@@ -0,0 +1,11 @@
+-- ----------------------------
+-- 收藏支持歌手、专辑
+-- ----------------------------
+ALTER TABLE `tb_user_favorite`
+  MODIFY COLUMN `type` tinyint NOT NULL COMMENT '收藏类型：0-歌曲，1-歌单，2-歌手，3-专辑',
+  ADD COLUMN `artist_id` bigint NULL DEFAULT NULL COMMENT '收藏歌手 id' AFTER `playlist_id`,
+  ADD COLUMN `album_id` bigint NULL DEFAULT NULL COMMENT '收藏专辑 id' AFTER `artist_id`,
+  ADD INDEX `fk_user_favorite_artist_id`(`artist_id` ASC) USING BTREE,
+  ADD INDEX `fk_user_favorite_album_id`(`album_id` ASC) USING BTREE,
+  ADD INDEX `idx_user_favorite_user_type`(`user_id` ASC, `type` ASC, `create_time` DESC) USING BTREE,
+  ADD CONSTRAINT `fk_user_favorite_artist_id` FOREIGN KEY (`artist_id`) REFERENCES `tb_artist` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
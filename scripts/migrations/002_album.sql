@@ -0,0 +1,53 @@
+-- ----------------------------
+-- 专辑
+-- ----------------------------
+CREATE TABLE `tb_album`  (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '专辑 id',
+  `artist_id` bigint NOT NULL COMMENT '歌手 id',
+  `title` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '专辑名',
+  `cover_url` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '专辑封面 url',
+  `release_date` date NULL DEFAULT NULL COMMENT '发行日期',
+  `type` tinyint NOT NULL DEFAULT 0 COMMENT '专辑类型：0-LP，1-EP，2-单曲',
+  `description` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL COMMENT '专辑简介',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `uk_album_artist_title`(`artist_id` ASC, `title` ASC) USING BTREE,
+  INDEX `idx_album_title`(`title` ASC) USING BTREE,
+  CONSTRAINT `fk_album_artist_id` FOREIGN KEY (`artist_id`) REFERENCES `tb_artist` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
+
+ALTER TABLE `tb_song`
+  ADD COLUMN `album_id` bigint NULL DEFAULT NULL COMMENT '专辑 id' AFTER `artist_id`,
+  ADD COLUMN `disc_number` int NOT NULL DEFAULT 1 COMMENT '碟号' AFTER `album`,
+  ADD COLUMN `track_number` int NOT NULL DEFAULT 0 COMMENT '曲目号，0 表示未编号' AFTER `disc_number`,
+  ADD INDEX `idx_song_album_track`(`album_id` ASC, `disc_number` ASC, `track_number` ASC) USING BTREE,
+  ADD CONSTRAINT `fk_song_album_id` FOREIGN KEY (`album_id`) REFERENCES `tb_album` (`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE `tb_user_favorite`
+  ADD CONSTRAINT `fk_user_favorite_album_id` FOREIGN KEY (`album_id`) REFERENCES `tb_album` (`id`) ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- ----------------------------
+-- 按歌手归并已有的专辑名
+-- 发行日期取最早的歌曲，封面取任一歌曲封面（与歌曲共用同一对象，仍被引用时不会删除），类型按曲目数粗略推断
+-- ----------------------------
+INSERT INTO `tb_album` (`artist_id`, `title`, `cover_url`, `release_date`, `type`)
+SELECT `artist_id`,
+       TRIM(`album`),
+       MAX(`cover_url`),
+       MIN(`release_time`),
+       CASE WHEN COUNT(*) = 1 THEN 2 WHEN COUNT(*) <= 6 THEN 1 ELSE 0 END
+FROM `tb_song`
+WHERE TRIM(`album`) <> ''
+GROUP BY `artist_id`, TRIM(`album`);
+
+UPDATE `tb_song` s
+  JOIN `tb_album` al ON al.`artist_id` = s.`artist_id` AND al.`title` = TRIM(s.`album`)
+SET s.`album_id` = al.`id`,
+    s.`album`    = al.`title`;
+
+-- 按发行时间、id 生成曲目号
+UPDATE `tb_song` s
+  JOIN (SELECT `id`,
+               ROW_NUMBER() OVER (PARTITION BY `album_id` ORDER BY `release_time`, `id`) AS `track_number`
+        FROM `tb_song`
+        WHERE `album_id` IS NOT NULL) t ON t.`id` = s.`id`
+SET s.`track_number` = t.`track_number`;
@@ -0,0 +1,19 @@
+-- ----------------------------
+-- 歌曲署名：一首歌可关联多位歌手及其身份
+-- tb_song.artist_id 保留为主歌手
+-- ----------------------------
+CREATE TABLE `tb_song_artist`  (
+  `song_id` bigint NOT NULL COMMENT '歌曲 id',
+  `artist_id` bigint NOT NULL COMMENT '歌手 id',
+  `role` tinyint NOT NULL DEFAULT 0 COMMENT '署名身份：0-主要，1-合作，2-作曲，3-作词，4-制作',
+  `sort` int NOT NULL DEFAULT 0 COMMENT '署名顺序',
+  PRIMARY KEY (`song_id`, `artist_id`, `role`) USING BTREE,
+  INDEX `fk_song_artist_artist_id`(`artist_id` ASC) USING BTREE,
+  CONSTRAINT `fk_song_artist_song_id` FOREIGN KEY (`song_id`) REFERENCES `tb_song` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `fk_song_artist_artist_id` FOREIGN KEY (`artist_id`) REFERENCES `tb_artist` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
+
+-- 已有歌曲的主歌手
+INSERT INTO `tb_song_artist` (`song_id`, `artist_id`, `role`, `sort`)
+SELECT `id`, `artist_id`, 0, 0
+FROM `tb_song`;
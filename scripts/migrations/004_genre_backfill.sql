@@ -0,0 +1,20 @@
+-- ----------------------------
+-- 风格关联补全
+-- 旧版本按未去空白的风格名匹配，部分歌曲缺少 tb_genre 记录；
+-- 按 tb_song.style 重新拆分补齐，再以关联表为准回写 tb_song.style
+-- ----------------------------
+INSERT IGNORE INTO `tb_genre` (`song_id`, `style_id`)
+SELECT s.`id`, st.`id`
+FROM `tb_song` s
+  JOIN JSON_TABLE(
+         CONCAT('["', REPLACE(REPLACE(REPLACE(s.`style`, '"', ''), '，', ','), ',', '","'), '"]'),
+         '$[*]' COLUMNS (`name` varchar(50) PATH '$')
+       ) jt
+  JOIN `tb_style` st ON st.`name` = TRIM(jt.`name`)
+WHERE s.`style` IS NOT NULL AND s.`style` <> '';
+
+UPDATE `tb_song` s
+SET s.`style` = COALESCE((
+  SELECT GROUP_CONCAT(st.`name` ORDER BY st.`id` SEPARATOR ',')
+  FROM `tb_genre` g JOIN `tb_style` st ON st.`id` = g.`style_id`
+  WHERE g.`song_id` = s.`id`), '');
@@ -0,0 +1,9 @@
+-- ----------------------------
+-- 搜索词按天计数，用于热门搜索；不记录用户与 IP
+-- ----------------------------
+CREATE TABLE `tb_search_query`  (
+  `day` date NOT NULL COMMENT '日期',
+  `query` varchar(100) NOT NULL COMMENT '规范化后的搜索词',
+  `count` bigint NOT NULL DEFAULT 0 COMMENT '当天搜索次数',
+  PRIMARY KEY (`day`, `query`) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
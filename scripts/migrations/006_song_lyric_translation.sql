@@ -0,0 +1,5 @@
+-- ----------------------------
+-- 歌曲翻译歌词（LRC 格式，按时间戳与原歌词对齐）
+-- ----------------------------
+ALTER TABLE `tb_song`
+  ADD COLUMN `lyric_translation` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL COMMENT '翻译歌词' AFTER `lyric`;
@@ -0,0 +1,29 @@
+-- ----------------------------
+-- 歌曲音频参数，以及上传时提取、待审核的元数据
+-- ----------------------------
+ALTER TABLE `tb_song`
+  ADD COLUMN `codec` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '音频编码' AFTER `duration`,
+  ADD COLUMN `bitrate` int NOT NULL DEFAULT 0 COMMENT '码率（kbps）' AFTER `codec`,
+  ADD COLUMN `sample_rate` int NOT NULL DEFAULT 0 COMMENT '采样率（Hz）' AFTER `bitrate`;
+
+CREATE TABLE `tb_song_audio_meta`  (
+  `song_id` bigint NOT NULL COMMENT '歌曲 id',
+  `audio_url` varchar(500) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '提取来源音频',
+  `codec` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '音频编码',
+  `duration_ms` bigint NOT NULL DEFAULT 0 COMMENT '时长（毫秒）',
+  `bitrate` int NOT NULL DEFAULT 0 COMMENT '码率（kbps）',
+  `sample_rate` int NOT NULL DEFAULT 0 COMMENT '采样率（Hz）',
+  `channels` int NOT NULL DEFAULT 0 COMMENT '声道数',
+  `title` varchar(200) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '标签：标题',
+  `artist` varchar(200) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '标签：歌手',
+  `album` varchar(200) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '标签：专辑',
+  `release_date` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '标签：发行日期原值',
+  `genre` varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '标签：流派',
+  `track_number` int NOT NULL DEFAULT 0 COMMENT '标签：曲目号',
+  `disc_number` int NOT NULL DEFAULT 0 COMMENT '标签：碟号',
+  `lyric` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL COMMENT '标签：歌词',
+  `cover_url` varchar(500) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '内嵌封面（已上传，未应用）',
+  `create_time` datetime NOT NULL COMMENT '提取时间',
+  PRIMARY KEY (`song_id`) USING BTREE,
+  CONSTRAINT `fk_song_audio_meta_song_id` FOREIGN KEY (`song_id`) REFERENCES `tb_song` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
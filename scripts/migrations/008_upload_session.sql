@@ -0,0 +1,17 @@
+-- ----------------------------
+-- 大文件分片上传会话
+-- ----------------------------
+CREATE TABLE `tb_upload_session`  (
+  `id` varchar(36) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '会话 id',
+  `song_id` bigint NOT NULL COMMENT '歌曲 id',
+  `filename` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '原文件名',
+  `size` bigint NOT NULL COMMENT '文件大小（字节）',
+  `chunk_size` bigint NOT NULL COMMENT '分片大小（字节）',
+  `object_key` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '临时对象名',
+  `upload_id` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT 'MinIO 分片上传 id',
+  `create_time` datetime NOT NULL COMMENT '创建时间',
+  `update_time` datetime NOT NULL COMMENT '最近上传分片时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_upload_session_update_time`(`update_time` ASC) USING BTREE,
+  CONSTRAINT `fk_upload_session_song_id` FOREIGN KEY (`song_id`) REFERENCES `tb_song` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
@@ -0,0 +1,16 @@
+-- ----------------------------
+-- 歌曲的多音质版本
+-- ----------------------------
+CREATE TABLE `tb_song_rendition`  (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '版本 id',
+  `song_id` bigint NOT NULL COMMENT '歌曲 id',
+  `quality` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '音质：standard/high/lossless',
+  `codec` varchar(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '音频编码',
+  `bitrate` int NOT NULL DEFAULT 0 COMMENT '码率（kbps）',
+  `size` bigint NOT NULL DEFAULT 0 COMMENT '文件大小（字节）',
+  `object_key` varchar(500) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '对象名',
+  `create_time` datetime NOT NULL COMMENT '上传时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `uk_song_rendition_quality`(`song_id` ASC, `quality` ASC) USING BTREE,
+  CONSTRAINT `fk_song_rendition_song_id` FOREIGN KEY (`song_id`) REFERENCES `tb_song` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
@@ -0,0 +1,15 @@
+-- ----------------------------
+-- 按内容寻址的存储对象及其引用计数
+-- 此前上传的对象没有记录, 可能被多行共用, 释放时数据库中已无引用才删除
+-- ----------------------------
+CREATE TABLE `tb_storage_object`  (
+  `object_key` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '对象名',
+  `folder` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '上传策略名，去重范围',
+  `sha256` char(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '原始内容的 SHA-256',
+  `size` bigint NOT NULL DEFAULT 0 COMMENT '原始内容大小（字节）',
+  `ref_count` int NOT NULL DEFAULT 0 COMMENT '引用计数',
+  `create_time` datetime NOT NULL COMMENT '创建时间',
+  `update_time` datetime NOT NULL COMMENT '最近一次引用计数变化的时间',
+  PRIMARY KEY (`object_key`) USING BTREE,
+  UNIQUE INDEX `uk_storage_object_sha256`(`folder` ASC, `sha256` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
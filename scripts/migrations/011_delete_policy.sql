@@ -0,0 +1,30 @@
+-- ----------------------------
+-- 删除策略: 与 internal/service/deletionService.go 中的 deletePolicies 保持一致
+-- 歌手名下有歌曲时拒绝删除；用户删除后其反馈保留为匿名反馈
+-- ----------------------------
+ALTER TABLE `tb_feedback`
+  DROP FOREIGN KEY `fk_feedback_user_id`;
+ALTER TABLE `tb_feedback`
+  MODIFY COLUMN `user_id` bigint NULL DEFAULT NULL COMMENT '用户 id，用户删除后为空';
+
+-- ----------------------------
+-- 清理外键检查关闭期间留下的悬空引用
+-- ----------------------------
+DELETE FROM `tb_user_favorite` WHERE `song_id` IS NOT NULL AND `song_id` NOT IN (SELECT `id` FROM `tb_song`);
+DELETE FROM `tb_user_favorite` WHERE `playlist_id` IS NOT NULL AND `playlist_id` NOT IN (SELECT `id` FROM `tb_playlist`);
+DELETE FROM `tb_user_favorite` WHERE `user_id` NOT IN (SELECT `id` FROM `tb_user`);
+DELETE FROM `tb_playlist_binding` WHERE `song_id` NOT IN (SELECT `id` FROM `tb_song`);
+DELETE FROM `tb_playlist_binding` WHERE `playlist_id` NOT IN (SELECT `id` FROM `tb_playlist`);
+DELETE FROM `tb_comment` WHERE `song_id` IS NOT NULL AND `song_id` NOT IN (SELECT `id` FROM `tb_song`);
+DELETE FROM `tb_comment` WHERE `playlist_id` IS NOT NULL AND `playlist_id` NOT IN (SELECT `id` FROM `tb_playlist`);
+DELETE FROM `tb_comment` WHERE `user_id` NOT IN (SELECT `id` FROM `tb_user`);
+DELETE FROM `tb_genre` WHERE `song_id` NOT IN (SELECT `id` FROM `tb_song`);
+UPDATE `tb_feedback` SET `user_id` = NULL WHERE `user_id` NOT IN (SELECT `id` FROM `tb_user`);
+
+ALTER TABLE `tb_feedback`
+  ADD CONSTRAINT `fk_feedback_user_id` FOREIGN KEY (`user_id`) REFERENCES `tb_user` (`id`) ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE `tb_song`
+  DROP FOREIGN KEY `fk_song_artist_id`;
+ALTER TABLE `tb_song`
+  ADD CONSTRAINT `fk_song_artist_id` FOREIGN KEY (`artist_id`) REFERENCES `tb_artist` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE;
@@ -0,0 +1,23 @@
+-- ----------------------------
+-- 软删除: 管理端删除的歌曲、歌手、歌单、用户与轮播图进入回收站
+-- 回收站中的行不出现在任何查询中, 超过保留期后由清理任务连同文件一并删除
+-- ----------------------------
+ALTER TABLE `tb_song`
+  ADD COLUMN `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间，为空表示未删除',
+  ADD INDEX `idx_tb_song_deleted_at`(`deleted_at` ASC) USING BTREE;
+
+ALTER TABLE `tb_artist`
+  ADD COLUMN `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间，为空表示未删除',
+  ADD INDEX `idx_tb_artist_deleted_at`(`deleted_at` ASC) USING BTREE;
+
+ALTER TABLE `tb_playlist`
+  ADD COLUMN `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间，为空表示未删除',
+  ADD INDEX `idx_tb_playlist_deleted_at`(`deleted_at` ASC) USING BTREE;
+
+ALTER TABLE `tb_user`
+  ADD COLUMN `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间，为空表示未删除',
+  ADD INDEX `idx_tb_user_deleted_at`(`deleted_at` ASC) USING BTREE;
+
+ALTER TABLE `tb_banner`
+  ADD COLUMN `deleted_at` datetime(3) NULL DEFAULT NULL COMMENT '删除时间，为空表示未删除',
+  ADD INDEX `idx_tb_banner_deleted_at`(`deleted_at` ASC) USING BTREE;
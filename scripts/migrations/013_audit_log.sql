@@ -0,0 +1,25 @@
+-- ----------------------------
+-- 管理端审计日志: 每个写请求一条, 由应用异步写入
+-- ----------------------------
+CREATE TABLE `tb_audit_log`  (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '审计记录 id',
+  `admin_id` bigint NOT NULL DEFAULT 0 COMMENT '管理员 id，未登录为 0',
+  `admin_name` varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '管理员用户名',
+  `action` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '操作，处理请求的方法名',
+  `method` varchar(10) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT 'HTTP 方法',
+  `path` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '请求路径',
+  `entity_type` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '实体类型',
+  `entity_ids` varchar(1000) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '实体 id，逗号分隔',
+  `diff` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL COMMENT '修改前后变化的字段（JSON）',
+  `status` int NOT NULL DEFAULT 0 COMMENT 'HTTP 状态码',
+  `success` tinyint(1) NOT NULL DEFAULT 0 COMMENT '业务结果是否成功',
+  `message` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '业务结果消息',
+  `ip` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '客户端 IP',
+  `user_agent` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT 'User-Agent',
+  `create_time` datetime NOT NULL COMMENT '请求时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_audit_log_admin_id`(`admin_id` ASC) USING BTREE,
+  INDEX `idx_audit_log_action`(`action` ASC) USING BTREE,
+  INDEX `idx_audit_log_entity_type`(`entity_type` ASC) USING BTREE,
+  INDEX `idx_audit_log_create_time`(`create_time` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
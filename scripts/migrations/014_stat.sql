@@ -0,0 +1,22 @@
+-- ----------------------------
+-- 播放记录与按天预聚合的统计, 供管理端仪表盘使用
+-- 播放记录由应用在返回播放地址时异步写入, 统计由定时任务从源表重算
+-- ----------------------------
+CREATE TABLE `tb_play_log`  (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '播放记录 id',
+  `song_id` bigint NOT NULL COMMENT '歌曲 id',
+  `user_id` bigint NULL DEFAULT NULL COMMENT '用户 id，未登录为空',
+  `create_time` datetime NOT NULL COMMENT '播放时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_play_log_song_id`(`song_id` ASC) USING BTREE,
+  INDEX `idx_play_log_create_time`(`create_time` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
+
+CREATE TABLE `tb_daily_stat`  (
+  `stat_date` date NOT NULL COMMENT '统计日期',
+  `metric` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '指标：new_users, plays, favorites, style_plays',
+  `dim_id` bigint NOT NULL DEFAULT 0 COMMENT '维度 id，style_plays 为风格 id，其余为 0',
+  `value` bigint NOT NULL DEFAULT 0 COMMENT '当天的值',
+  PRIMARY KEY (`stat_date`, `metric`, `dim_id`) USING BTREE,
+  INDEX `idx_daily_stat_metric`(`metric` ASC, `stat_date` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
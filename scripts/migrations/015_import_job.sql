@@ -0,0 +1,19 @@
+-- ----------------------------
+-- 批量导入任务: 清单与媒体压缩包上传后在后台逐行导入, 完成后保存逐行结果
+-- ----------------------------
+CREATE TABLE `tb_import_job`  (
+  `id` varchar(36) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '任务 id',
+  `status` varchar(16) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '状态：pending, running, done, failed',
+  `dry_run` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否只校验不写入',
+  `manifest` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '清单文件名',
+  `total` int NOT NULL DEFAULT 0 COMMENT '清单行数',
+  `processed` int NOT NULL DEFAULT 0 COMMENT '已处理行数',
+  `succeeded` int NOT NULL DEFAULT 0 COMMENT '成功行数',
+  `failed` int NOT NULL DEFAULT 0 COMMENT '失败行数',
+  `message` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '任务失败的原因',
+  `report` mediumtext CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL COMMENT '逐行结果（JSON），完成后写入',
+  `create_time` datetime NOT NULL COMMENT '创建时间',
+  `finish_time` datetime NULL DEFAULT NULL COMMENT '完成时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  INDEX `idx_import_job_create_time`(`create_time` ASC) USING BTREE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
@@ -0,0 +1,18 @@
+-- ----------------------------
+-- 本地曲库扫描记录: cmd/scan 按路径与修改时间、内容摘要判断文件是否变化
+-- 歌曲彻底删除后 song_id 置空, 文件内容不变时不再重新导入
+-- ----------------------------
+CREATE TABLE `tb_scan_file`  (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '记录 id',
+  `path` varchar(700) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL COMMENT '文件的绝对路径，区分大小写',
+  `size` bigint NOT NULL DEFAULT 0 COMMENT '文件大小（字节）',
+  `mod_time` bigint NOT NULL DEFAULT 0 COMMENT '修改时间（Unix 纳秒）',
+  `sha256` char(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '文件内容的 SHA-256',
+  `song_id` bigint NULL DEFAULT NULL COMMENT '对应的歌曲 id',
+  `scan_time` datetime NOT NULL COMMENT '最近一次导入或更新的时间',
+  PRIMARY KEY (`id`) USING BTREE,
+  UNIQUE INDEX `uk_scan_file_path`(`path` ASC) USING BTREE,
+  INDEX `idx_scan_file_sha256`(`sha256` ASC) USING BTREE,
+  INDEX `fk_scan_file_song_id`(`song_id` ASC) USING BTREE,
+  CONSTRAINT `fk_scan_file_song_id` FOREIGN KEY (`song_id`) REFERENCES `tb_song` (`id`) ON DELETE SET NULL ON UPDATE CASCADE
+) ENGINE = InnoDB CHARACTER SET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci ROW_FORMAT = DYNAMIC;
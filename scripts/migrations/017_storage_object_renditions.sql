@@ -0,0 +1,7 @@
+-- ----------------------------
+-- 记录图片实际生成的缩略图, 不再按当前配置的宽度推测
+-- 已有记录为 NULL, 首次返回其缩略图地址时按存储中实际存在的缩略图补记
+-- ----------------------------
+ALTER TABLE `tb_storage_object`
+  ADD COLUMN `renditions` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NULL DEFAULT NULL COMMENT '已生成的缩略图宽度，以逗号分隔，空串表示没有缩略图，NULL 表示未记录' AFTER `size`,
+  ADD COLUMN `rendition_ext` varchar(16) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '缩略图扩展名，如 .webp' AFTER `renditions`;